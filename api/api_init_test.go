@@ -122,10 +122,9 @@ func init() {
 		spClient.RetryPolicies = map[int]int{
 			404: 1,
 		}
+		spClient.Timeout = 30 * time.Second
 	}
 
-	spClient.Timeout = 30 * time.Second
-
 	setHeadersPresets()
 }
 
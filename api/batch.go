@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// BatchRequest OData $batch request part, Method is POST when empty
+type BatchRequest struct {
+	Method  string
+	URL     string // absolute API URL
+	Body    []byte
+	Headers map[string]string // part headers, Accept and Content-Type default to the config's headers
+}
+
+// BatchResponse OData $batch response part
+type BatchResponse struct {
+	StatusCode int
+	Body       []byte
+	Err        error // the part's HTTP error
+}
+
+// Batch sends requests in a single OData $batch request, writes are sent in their own changesets
+// so a failed request doesn't affect the others. Responses are returned in requests order,
+// the error is returned when the batch itself fails.
+func (client *HTTPClient) Batch(endpoint string, requests []*BatchRequest, conf *RequestConfig) ([]*BatchResponse, error) {
+	if len(requests) == 0 {
+		return []*BatchResponse{}, nil
+	}
+	endpoint = fmt.Sprintf("%s/_api/$batch", getPriorEndpoint(endpoint, "/_api"))
+
+	headers := map[string]string{
+		"Accept":       "application/json;odata=verbose",
+		"Content-Type": "application/json;odata=verbose;charset=utf-8",
+	}
+	if conf != nil {
+		for key, value := range conf.Headers {
+			headers[key] = value
+		}
+	}
+
+	batchID := "batch_" + uuid.New().String()
+	body := &bytes.Buffer{}
+	for _, r := range requests {
+		method := r.Method
+		if method == "" {
+			method = "POST"
+		}
+		partHeaders := map[string]string{}
+		for _, key := range []string{"Accept", "Content-Type", "Accept-Language"} {
+			if value, ok := headers[key]; ok {
+				partHeaders[key] = value
+			}
+		}
+		for key, value := range r.Headers {
+			partHeaders[key] = value
+		}
+		partURL, err := url.Parse(r.URL)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse batch request URL: %w", err)
+		}
+		req := &bytes.Buffer{}
+		req.WriteString(method + " " + partURL.String() + " HTTP/1.1\r\n")
+		for key, value := range partHeaders {
+			req.WriteString(key + ": " + value + "\r\n")
+		}
+		req.WriteString("\r\n")
+		req.Write(r.Body)
+		req.WriteString("\r\n")
+
+		body.WriteString("--" + batchID + "\r\n")
+		if method == "GET" {
+			body.WriteString("Content-Type: application/http\r\nContent-Transfer-Encoding: binary\r\n\r\n")
+			body.Write(req.Bytes())
+			continue
+		}
+		changesetID := "changeset_" + uuid.New().String()
+		body.WriteString("Content-Type: multipart/mixed; boundary=\"" + changesetID + "\"\r\n\r\n")
+		body.WriteString("--" + changesetID + "\r\n")
+		body.WriteString("Content-Type: application/http\r\nContent-Transfer-Encoding: binary\r\n\r\n")
+		body.Write(req.Bytes())
+		body.WriteString("--" + changesetID + "--\r\n")
+	}
+	body.WriteString("--" + batchID + "--\r\n")
+
+	req, err := http.NewRequest("POST", endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a request: %w", err)
+	}
+	if conf != nil && conf.Context != nil {
+		req = req.WithContext(conf.Context)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "multipart/mixed; boundary=\""+batchID+"\"")
+
+	resp, err := client.sp.Execute(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to request api: %w", err)
+	}
+	defer shut(resp.Body)
+
+	responses, err := readBatchResponses(resp.Header.Get("Content-Type"), resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(responses) != len(requests) {
+		return nil, fmt.Errorf("unexpected batch response: %d responses for %d requests", len(responses), len(requests))
+	}
+	return responses, nil
+}
+
+// readBatchResponses reads multipart $batch response, nested changeset responses are flattened
+func readBatchResponses(contentType string, body io.Reader) ([]*BatchResponse, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("unexpected batch response content type: %s", contentType)
+	}
+	var responses []*BatchResponse
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return responses, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read batch response: %w", err)
+		}
+		partType := part.Header.Get("Content-Type")
+		if strings.HasPrefix(strings.ToLower(partType), "multipart/") {
+			nested, err := readBatchResponses(partType, part)
+			if err != nil {
+				return nil, err
+			}
+			responses = append(responses, nested...)
+			continue
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(part), nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read batch response: %w", err)
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		shut(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read batch response: %w", err)
+		}
+		res := &BatchResponse{StatusCode: resp.StatusCode, Body: bytes.TrimSpace(data)}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			res.Err = fmt.Errorf("%s :: %s", resp.Status, res.Body)
+		}
+		responses = append(responses, res)
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestItemsAddBatch(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	for mode := range mockModes {
+		t.Run(mode, func(t *testing.T) {
+			listTitle := "Batch " + mode
+			if _, err := srv.AddList(listTitle, 100); err != nil {
+				t.Fatal(err)
+			}
+			items := newMockSP(t, srv, mode).Web().Lists().GetByTitle(listTitle).Items()
+
+			results, err := items.AddBatch([][]byte{
+				[]byte(`{"Title":"One"}`),
+				[]byte(`{"Unknown":"value"}`),
+				[]byte(`{"Title":"Three, \"quoted\"\r\n"}`),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 3 {
+				t.Fatalf("expected 3 results, got %d", len(results))
+			}
+			if results[0].Err != nil || results[0].Data.Data().Title != "One" {
+				t.Errorf("unexpected first result: %v, %s", results[0].Err, results[0].Data)
+			}
+			if results[1].Err == nil || !strings.Contains(results[1].Err.Error(), "400") {
+				t.Errorf("failed item error is expected, got %v", results[1].Err)
+			}
+			if results[2].Err != nil || results[2].Data.Data().Title != "Three, \"quoted\"\r\n" {
+				t.Errorf("unexpected third result: %v, %s", results[2].Err, results[2].Data)
+			}
+
+			all, err := items.Select("Id,Title").GetAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, item := range all {
+				titles = append(titles, item.Data().Title)
+			}
+			if fmt.Sprintf("%q", titles) != `["One" "Three, \"quoted\"\r\n"]` {
+				t.Errorf("unexpected items: %q", titles)
+			}
+
+			if results, err := items.AddBatch(nil); err != nil || len(results) != 0 {
+				t.Errorf("empty batch should be no-op: %v, %v", results, err)
+			}
+		})
+	}
+}
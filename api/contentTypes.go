@@ -3,8 +3,6 @@ package api
 import (
 	"bytes"
 	"fmt"
-	"regexp"
-	"strconv"
 
 	"github.com/koltyakov/gosip"
 	"github.com/koltyakov/gosip/csom"
@@ -80,46 +78,26 @@ func (contentTypes *ContentTypes) Create(contentTypeInfo *ContentTypeCreationInf
 	b.AddObject(csom.NewObject(`<Property Id="{{.ID}}" ParentId="{{.ParentID}}" Name="Web" />`), nil)
 	ctsObj, _ := b.AddObject(csom.NewObject(`<Property Id="{{.ID}}" ParentId="{{.ParentID}}" Name="ContentTypes" />`), nil)
 
-	parentContentTypeID := 0
-	if contentTypeInfo.ParentContentTypeID != "" {
-		pCtObj, _ := b.AddObject(csom.NewObject(`
-			<Method Id="{{.ID}}" ParentId="{{.ParentID}}" Name="GetById">
-				<Parameters>
-					<Parameter Type="String">`+contentTypeInfo.ParentContentTypeID+`</Parameter>
-				</Parameters>
-			</Method>
-		`), ctsObj)
-		id, err := b.GetObjectID(pCtObj)
-		if err != nil {
-			return "", err
-		}
-		parentContentTypeID = id
-	}
-
-	ctIDProp := `<Property Name="Id" Type="Null" />`
+	ctID := csom.NewParamNull()
 	if contentTypeInfo.ID != "" {
-		ctIDProp = `<Property Name="Id" Type="String">` + contentTypeInfo.ID + `</Property>`
+		ctID = csom.NewParamString(contentTypeInfo.ID)
 	}
-	pctIDProp := `<Property Name="ParentContentType" Type="Null" />`
+	parentCt := csom.NewParamNull()
 	if contentTypeInfo.ParentContentTypeID != "" {
-		pctIDProp = `<Property Name="ParentContentType" ObjectPathId="` + strconv.Itoa(parentContentTypeID) + `" />`
+		pCtObj, _ := b.AddObject(csom.NewObjectMethodWithParams("GetById", csom.NewParamString(contentTypeInfo.ParentContentTypeID)), ctsObj)
+		parentCt = csom.NewParamObjectPath(pCtObj)
 	}
 
-	b.AddObject(csom.NewObject(`
-		<Method Id="{{.ID}}" ParentId="{{.ParentID}}" Name="Add">
-			<Parameters>
-				<Parameter TypeId="{168f3091-4554-4f14-8866-b20d48e45b54}">
-					`+ctIDProp+`
-					<Property Name="Name" Type="String">`+contentTypeInfo.Name+`</Property>
-					<Property Name="Group" Type="String">`+contentTypeInfo.Group+`</Property>
-					<Property Name="Description" Type="String">`+contentTypeInfo.Description+`</Property>
-					`+pctIDProp+`
-				</Parameter>
-			</Parameters>
-		</Method>
-	`), ctsObj)
-
-	b.AddAction(csom.NewAction(`<ObjectIdentityQuery Id="{{.ID}}" ObjectPathId="{{.ObjectID}}" />`), nil)
+	b.AddObject(csom.NewObjectMethodWithParams("Add", csom.NewParamObject(
+		"168f3091-4554-4f14-8866-b20d48e45b54", // ContentTypeCreationInformation
+		csom.NewProperty("Id", ctID),
+		csom.NewProperty("Name", csom.NewParamString(contentTypeInfo.Name)),
+		csom.NewProperty("Group", csom.NewParamString(contentTypeInfo.Group)),
+		csom.NewProperty("Description", csom.NewParamString(contentTypeInfo.Description)),
+		csom.NewProperty("ParentContentType", parentCt),
+	)), ctsObj)
+
+	identityQuery, _ := b.AddAction(csom.NewAction(`<ObjectIdentityQuery Id="{{.ID}}" ObjectPathId="{{.ObjectID}}" />`), nil)
 
 	csomPkg, err := b.Compile()
 	if err != nil {
@@ -130,7 +108,5 @@ func (contentTypes *ContentTypes) Create(contentTypeInfo *ContentTypeCreationInf
 	if err != nil {
 		return "", nil
 	}
-	rgx := regexp.MustCompile(`:contenttype:(.*?)"`)
-	rs := rgx.FindStringSubmatch(fmt.Sprintf("%s", resp))
-	return rs[1], nil
+	return csomIdentityPart(resp, identityQuery, "contenttype")
 }
@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (contentTypes *ContentTypes) Conf(config *RequestConfig) *ContentTypes {
 	contentTypes.config = config
@@ -26,6 +30,12 @@ func (contentTypes *ContentTypes) Filter(oDataFilter string) *ContentTypes {
 	return contentTypes
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (contentTypes *ContentTypes) FilterExpr(oDataFilter odata.Expr) *ContentTypes {
+	contentTypes.modifiers.AddFilter(oDataFilter.String())
+	return contentTypes
+}
+
 // Top adds $top OData modifier
 func (contentTypes *ContentTypes) Top(oDataTop int) *ContentTypes {
 	contentTypes.modifiers.AddTop(oDataTop)
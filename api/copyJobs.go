@@ -0,0 +1,261 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CopyJobOptions provides optional settings for copy migration jobs
+type CopyJobOptions struct {
+	Move                 bool // move items, the source items are removed after they are copied
+	Overwrite            bool // replace existing items, the conflicting items fail by default
+	KeepBoth             bool // copy the conflicting items with new names
+	IgnoreVersionHistory bool // copy only the latest version of files, the version history is preserved by default
+	BypassSharedLock     bool // copy files locked for co-authoring
+	AllowSchemaMismatch  bool // allow copying to a library with different fields
+	ExcludeChildren      bool // copy folders without their content
+
+	PollInterval time.Duration // job progress polling interval, 2 seconds by default
+
+	// Progress callback is called after each job progress request
+	Progress func(status *CopyJobStatus)
+}
+
+// CopyJobInfo describes a created copy migration job, it's required to request the job progress
+type CopyJobInfo struct {
+	EncryptionKey           string   `json:"EncryptionKey"`
+	JobID                   string   `json:"JobId"`
+	JobQueueURI             string   `json:"JobQueueUri"`
+	SourceListItemUniqueIDs []string `json:"SourceListItemUniqueIds"`
+}
+
+// Copy migration job states
+const (
+	CopyJobStateNone       = 0 // the job is finished or is not found
+	CopyJobStateQueued     = 2
+	CopyJobStateProcessing = 4
+)
+
+// CopyJobProgress describes GetCopyJobProgress response, the logs are the new entries since the previous request
+type CopyJobProgress struct {
+	JobState int
+	Logs     []*CopyJobLog
+}
+
+// CopyJobLog describes a copy job log entry, e.g. "JobStart", "JobProgress", "JobError", "JobFatalError" or "JobEnd" event
+type CopyJobLog struct {
+	Event                  string `json:"Event"`
+	Time                   string `json:"Time"`
+	Message                string `json:"Message"`
+	ObjectType             string `json:"ObjectType"`
+	URL                    string `json:"Url"`
+	ErrorCode              string `json:"ErrorCode"`
+	ErrorType              string `json:"ErrorType"`
+	SourceObjectFullURL    string `json:"SourceObjectFullUrl"`
+	TargetObjectFullURL    string `json:"TargetObjectFullUrl"`
+	ObjectsProcessed       string `json:"ObjectsProcessed"`
+	TotalExpectedSPObjects string `json:"TotalExpectedSPObjects"`
+	TotalErrors            string `json:"TotalErrors"`
+	Raw                    string `json:"-"` // the log entry JSON
+}
+
+// CopyJobStatus describes a copy job progress accumulated while waiting for the job
+type CopyJobStatus struct {
+	Job       *CopyJobInfo
+	JobState  int
+	Done      bool          // the job is ended
+	Processed int           // objects processed
+	Total     int           // objects expected, 0 until the job reports it
+	Errors    []*CopyJobLog // per-item and fatal errors
+	Logs      []*CopyJobLog // all log entries
+}
+
+// CreateCopyJobs creates copy migration jobs copying or moving files and folders to a destination folder,
+// the source and destination URLs are absolute, server relative or web relative URLs and can belong to different site collections.
+// The jobs are processed in background, use WaitCopyJobs to wait for the jobs to end or RunCopyJobs to do both.
+func (site *Site) CreateCopyJobs(sourceURLs []string, destinationURL string, options *CopyJobOptions) ([]*CopyJobInfo, error) {
+	if options == nil {
+		options = &CopyJobOptions{}
+	}
+	nameConflict := 0 // fail
+	if options.Overwrite {
+		nameConflict = 1
+	}
+	if options.KeepBoth {
+		nameConflict = 2
+	}
+	exportURIs := make([]string, len(sourceURLs))
+	for i, sourceURL := range sourceURLs {
+		exportURIs[i] = absoluteURL(sourceURL, site.endpoint)
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"exportObjectUris": map[string][]string{"results": exportURIs},
+		"destinationUri":   absoluteURL(destinationURL, site.endpoint),
+		"options": map[string]interface{}{
+			"__metadata":           map[string]string{"type": "SP.CopyMigrationOptions"},
+			"NameConflictBehavior": nameConflict,
+			"IsMoveMode":           options.Move,
+			"IgnoreVersionHistory": options.IgnoreVersionHistory,
+			"BypassSharedLock":     options.BypassSharedLock,
+			"AllowSchemaMismatch":  options.AllowSchemaMismatch,
+			"ExcludeChildren":      options.ExcludeChildren,
+		},
+	})
+
+	client := NewHTTPClient(site.client)
+	data, err := client.Post(site.endpoint+"/CreateCopyJobs", bytes.NewBuffer(body), site.config)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*CopyJobInfo
+	if err := json.Unmarshal(methodResult(data, "CreateCopyJobs"), &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// GetCopyJobProgress requests copy migration job progress
+func (site *Site) GetCopyJobProgress(job *CopyJobInfo) (*CopyJobProgress, error) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"copyJobInfo": map[string]interface{}{
+			"__metadata":    map[string]string{"type": "SP.CopyMigrationInfo"},
+			"EncryptionKey": job.EncryptionKey,
+			"JobId":         job.JobID,
+			"JobQueueUri":   job.JobQueueURI,
+		},
+	})
+
+	client := NewHTTPClient(site.client)
+	data, err := client.Post(site.endpoint+"/GetCopyJobProgress", bytes.NewBuffer(body), site.config)
+	if err != nil {
+		return nil, err
+	}
+	res := &struct {
+		JobState int      `json:"JobState"`
+		Logs     []string `json:"Logs"`
+	}{}
+	if err := json.Unmarshal(methodResult(data, "GetCopyJobProgress"), res); err != nil {
+		return nil, err
+	}
+	progress := &CopyJobProgress{JobState: res.JobState}
+	for _, entry := range res.Logs {
+		log := &CopyJobLog{Raw: entry}
+		if err := json.Unmarshal([]byte(entry), log); err != nil {
+			return nil, fmt.Errorf("can't parse copy job log: %w", err)
+		}
+		progress.Logs = append(progress.Logs, log)
+	}
+	return progress, nil
+}
+
+// WaitCopyJobs polls the jobs progress until all the jobs end or the request context is canceled.
+// The statuses are returned in the jobs order, an error is returned when any job reported errors.
+func (site *Site) WaitCopyJobs(jobs []*CopyJobInfo, options *CopyJobOptions) ([]*CopyJobStatus, error) {
+	if options == nil {
+		options = &CopyJobOptions{}
+	}
+	interval := options.PollInterval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	ctx := context.Background()
+	if site.config != nil && site.config.Context != nil {
+		ctx = site.config.Context
+	}
+
+	statuses := make([]*CopyJobStatus, len(jobs))
+	for i, job := range jobs {
+		statuses[i] = &CopyJobStatus{Job: job, JobState: CopyJobStateQueued}
+	}
+	for {
+		pending := 0
+		for _, status := range statuses {
+			if status.Done {
+				continue
+			}
+			progress, err := site.GetCopyJobProgress(status.Job)
+			if err != nil {
+				return statuses, err
+			}
+			status.update(progress)
+			if options.Progress != nil {
+				options.Progress(status)
+			}
+			if !status.Done {
+				pending++
+			}
+		}
+		if pending == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return statuses, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+
+	var failed []string
+	for _, status := range statuses {
+		for _, log := range status.Errors {
+			failed = append(failed, log.Message)
+		}
+	}
+	if len(failed) > 0 {
+		return statuses, fmt.Errorf("copy jobs ended with %d error(s): %s", len(failed), strings.Join(failed, "; "))
+	}
+	return statuses, nil
+}
+
+// RunCopyJobs creates copy migration jobs and waits for them to end, see CreateCopyJobs and WaitCopyJobs
+func (site *Site) RunCopyJobs(sourceURLs []string, destinationURL string, options *CopyJobOptions) ([]*CopyJobStatus, error) {
+	jobs, err := site.CreateCopyJobs(sourceURLs, destinationURL, options)
+	if err != nil {
+		return nil, err
+	}
+	return site.WaitCopyJobs(jobs, options)
+}
+
+// update applies job progress response to the status
+func (status *CopyJobStatus) update(progress *CopyJobProgress) {
+	status.JobState = progress.JobState
+	ended := false
+	for _, log := range progress.Logs {
+		status.Logs = append(status.Logs, log)
+		if n, err := strconv.Atoi(log.ObjectsProcessed); err == nil {
+			status.Processed = n
+		}
+		if n, err := strconv.Atoi(log.TotalExpectedSPObjects); err == nil {
+			status.Total = n
+		}
+		switch log.Event {
+		case "JobError", "JobFatalError":
+			status.Errors = append(status.Errors, log)
+		case "JobEnd":
+			ended = true
+		}
+	}
+	// A finished job is removed from the queue, its state is None then
+	status.Done = ended || progress.JobState == CopyJobStateNone
+}
+
+// methodResult gets service method result from verbose (`d.<method>`), minimal and no metadata (`value` or the payload) responses
+func methodResult(data []byte, method string) []byte {
+	data = NormalizeODataItem(data)
+	res := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return data
+	}
+	if value, ok := res[method]; ok {
+		return value
+	}
+	if value, ok := res["value"]; ok {
+		return value
+	}
+	return data
+}
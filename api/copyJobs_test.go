@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestCopyJobs(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+	docs := spmock.SitePath + "/Shared Documents"
+	for _, fileURL := range []string{"Src/a.txt", "Src/Sub/b.txt", "c.txt"} {
+		if err := srv.AddFile(docs+"/"+fileURL, []byte(fileURL)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := srv.AddFolder(docs + "/Dest"); err != nil {
+		t.Fatal(err)
+	}
+	exists := func(fileURL string) bool {
+		_, err := NewSP(srv.Client()).Web().GetFile(docs + "/" + fileURL).Get()
+		return err == nil
+	}
+
+	for mode := range mockModes {
+		t.Run("Copy/"+mode, func(t *testing.T) {
+			site := newMockSP(t, srv, mode).Site()
+			polls := 0
+			statuses, err := site.RunCopyJobs([]string{docs + "/Src", srv.URL + docs + "/c.txt"}, "Shared Documents/Dest", &CopyJobOptions{
+				Overwrite:    true,
+				PollInterval: time.Millisecond,
+				Progress: func(status *CopyJobStatus) {
+					polls++
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(statuses) != 1 || !statuses[0].Done || statuses[0].Processed != 5 || polls != 2 {
+				t.Errorf("unexpected job status: %+v, %d polls", statuses[0], polls)
+			}
+			if len(statuses[0].Job.SourceListItemUniqueIDs) != 2 {
+				t.Errorf("unexpected job info: %+v", statuses[0].Job)
+			}
+			if !exists("Dest/Src/Sub/b.txt") || !exists("Dest/c.txt") || !exists("c.txt") {
+				t.Error("items are not copied")
+			}
+		})
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		site := NewSP(srv.Client()).Site()
+		statuses, err := site.RunCopyJobs([]string{docs + "/c.txt", docs + "/missing.txt"}, docs+"/Dest", &CopyJobOptions{PollInterval: time.Millisecond})
+		if err == nil || !strings.Contains(err.Error(), "2 error(s)") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(statuses[0].Errors) != 2 || !strings.Contains(statuses[0].Errors[0].Message, "already exists") {
+			t.Errorf("unexpected errors: %+v", statuses[0].Errors)
+		}
+	})
+
+	t.Run("KeepBothAndMove", func(t *testing.T) {
+		site := NewSP(srv.Client()).Site()
+		options := &CopyJobOptions{Move: true, KeepBoth: true, PollInterval: time.Millisecond}
+		if _, err := site.RunCopyJobs([]string{docs + "/c.txt"}, docs+"/Dest", options); err != nil {
+			t.Fatal(err)
+		}
+		if !exists("Dest/c 1.txt") || exists("c.txt") {
+			t.Error("file is not moved with a new name")
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		site := NewSP(srv.Client()).Site().Conf(&RequestConfig{Context: ctx})
+		_, err := site.RunCopyJobs([]string{docs + "/Src/a.txt"}, docs+"/Dest", &CopyJobOptions{
+			Overwrite:    true,
+			PollInterval: time.Hour,
+			Progress: func(status *CopyJobStatus) {
+				cancel()
+			},
+		})
+		if err != context.Canceled {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("MoveCopyUtil", func(t *testing.T) {
+		util := NewSP(srv.Client()).MoveCopyUtil()
+		if _, err := util.CopyFile(docs+"/Src/a.txt", "Shared Documents/Dest/a.txt", false, nil); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := util.CopyFile(docs+"/Src/a.txt", docs+"/Dest/a.txt", false, nil); err == nil {
+			t.Error("existing file is overwritten")
+		}
+		if _, err := util.MoveFile(docs+"/Dest/a.txt", docs+"/Dest/moved.txt", true, nil); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := util.CopyFolder(docs+"/Src", docs+"/Copy", nil); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := util.MoveFolder(docs+"/Copy", docs+"/Dest/Copy", &MoveCopyOptions{KeepBoth: true}); err != nil {
+			t.Fatal(err)
+		}
+		if !exists("Dest/moved.txt") || exists("Dest/a.txt") || !exists("Dest/Copy/Sub/b.txt") || exists("Copy/a.txt") {
+			t.Error("items are not copied or moved")
+		}
+	})
+}
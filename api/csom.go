@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/koltyakov/gosip/csom"
+)
+
+// csomResponse processes CSOM query and gets the last action result as a generic map, CSOM literals are kept as is
+func csomResponse(httpClient *HTTPClient, siteURL string, config *RequestConfig, csomBuilder csom.Builder) (map[string]interface{}, error) {
+	res, err := csomLastResult(httpClient, siteURL, config, csomBuilder)
+	if err != nil {
+		return nil, err
+	}
+	return res.RawMap()
+}
+
+func csomRespChildItems(httpClient *HTTPClient, siteURL string, config *RequestConfig, csomBuilder csom.Builder) ([]map[string]interface{}, error) {
+	res, err := csomLastResult(httpClient, siteURL, config, csomBuilder)
+	if err != nil {
+		return nil, err
+	}
+	return csomChildItemsToMaps(res)
+}
+
+func csomRespChildItemsInProp(httpClient *HTTPClient, siteURL string, config *RequestConfig, csomBuilder csom.Builder, prop string) ([]map[string]interface{}, error) {
+	res, err := csomLastResult(httpClient, siteURL, config, csomBuilder)
+	if err != nil {
+		return nil, err
+	}
+	propRes, err := res.Prop(prop)
+	if err != nil {
+		return nil, fmt.Errorf("can't get property data: %w", err)
+	}
+	return csomChildItemsToMaps(propRes)
+}
+
+// csomLastResult processes CSOM query and gets the result of the last builder's action
+func csomLastResult(httpClient *HTTPClient, siteURL string, config *RequestConfig, csomBuilder csom.Builder) (*csom.Result, error) {
+	resp, err := csomProcess(httpClient, siteURL, config, csomBuilder)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := csomBuilder.GetActions()
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("no actions in CSOM package")
+	}
+
+	res, err := resp.Result(actions[len(actions)-1])
+	if err != nil || res.IsNull() {
+		return nil, fmt.Errorf("object not found")
+	}
+
+	return res, nil
+}
+
+// csomProcess processes CSOM query and parses typed response, retries Terms update conflicts
+func csomProcess(httpClient *HTTPClient, siteURL string, config *RequestConfig, csomBuilder csom.Builder) (*csom.Response, error) {
+	csomPkg, err := csomBuilder.Compile()
+	if err != nil {
+		return nil, err
+	}
+
+	jsomResp, err := httpClient.ProcessQuery(siteURL, bytes.NewBuffer([]byte(csomPkg)), config)
+	if err != nil {
+		// Retry Terms update conflicts
+		if strings.Index(err.Error(), "Term update failed because of save conflict") != -1 {
+			if config == nil {
+				config = &RequestConfig{}
+			}
+			if config.Headers == nil {
+				config.Headers = map[string]string{}
+			}
+			retryStr, ok := config.Headers["X-Gosip-Retry"]
+			if !ok {
+				retryStr = "0"
+			}
+			retry, _ := strconv.Atoi(retryStr)
+			config.Headers["X-Gosip-Retry"] = strconv.Itoa(retry + 1)
+			if retry+1 <= 5 {
+				return csomProcess(httpClient, siteURL, config, csomBuilder)
+			}
+		}
+		return nil, err
+	}
+
+	return csom.ParseResponse(jsomResp)
+}
+
+// csomChildItemsToMaps converts CSOM collection result to generic maps, the values are kept as is
+func csomChildItemsToMaps(res *csom.Result) ([]map[string]interface{}, error) {
+	items, err := res.ChildItems()
+	if err != nil {
+		return nil, err
+	}
+
+	var resItems []map[string]interface{}
+	for _, item := range items {
+		resItem, err := item.RawMap()
+		if err != nil {
+			return nil, fmt.Errorf("can't get child item")
+		}
+		resItems = append(resItems, resItem)
+	}
+
+	return resItems, nil
+}
+
+// csomIdentityPart gets a part of an action result's _ObjectIdentity_ following the `:key:` token
+func csomIdentityPart(jsomResp []byte, action csom.Action, key string) (string, error) {
+	resp, err := csom.ParseResponse(jsomResp)
+	if err != nil {
+		return "", err
+	}
+	res, err := resp.Result(action)
+	if err != nil {
+		return "", err
+	}
+	identity := res.ObjectIdentity()
+	token := ":" + key + ":"
+	pos := strings.LastIndex(identity, token)
+	if pos == -1 {
+		return "", fmt.Errorf("can't find %s in object identity: %s", key, identity)
+	}
+	return identity[pos+len(token):], nil
+}
@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (customActions *CustomActions) Conf(config *RequestConfig) *CustomActions {
 	customActions.config = config
@@ -20,6 +24,12 @@ func (customActions *CustomActions) Filter(oDataFilter string) *CustomActions {
 	return customActions
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (customActions *CustomActions) FilterExpr(oDataFilter odata.Expr) *CustomActions {
+	customActions.modifiers.AddFilter(oDataFilter.String())
+	return customActions
+}
+
 // Top adds $top OData modifier
 func (customActions *CustomActions) Top(oDataTop int) *CustomActions {
 	customActions.modifiers.AddTop(oDataTop)
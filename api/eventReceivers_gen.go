@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (eventReceivers *EventReceivers) Conf(config *RequestConfig) *EventReceivers {
 	eventReceivers.config = config
@@ -20,6 +24,12 @@ func (eventReceivers *EventReceivers) Filter(oDataFilter string) *EventReceivers
 	return eventReceivers
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (eventReceivers *EventReceivers) FilterExpr(oDataFilter odata.Expr) *EventReceivers {
+	eventReceivers.modifiers.AddFilter(oDataFilter.String())
+	return eventReceivers
+}
+
 // Top adds $top OData modifier
 func (eventReceivers *EventReceivers) Top(oDataTop int) *EventReceivers {
 	eventReceivers.modifiers.AddTop(oDataTop)
@@ -3,8 +3,6 @@ package api
 import (
 	"bytes"
 	"fmt"
-	"regexp"
-	"strconv"
 
 	"github.com/koltyakov/gosip"
 	"github.com/koltyakov/gosip/csom"
@@ -153,18 +151,16 @@ func (fieldLinks *FieldLinks) Add(name string) (string, error) {
 	b := csom.NewBuilder()
 	webObj, _ := b.AddObject(csom.NewObjectProperty("Web"), nil)
 	b.AddObject(csom.NewObjectProperty("Fields"), nil)
-	fieldObj, _ := b.AddObject(csom.NewObjectMethod("GetByInternalNameOrTitle", []string{`<Parameter Type="String">` + name + `</Parameter>`}), nil)
-	fieldID, _ := b.GetObjectID(fieldObj)
+	fieldObj, _ := b.AddObject(csom.NewObjectMethodWithParams("GetByInternalNameOrTitle", csom.NewParamString(name)), nil)
 	b.AddObject(csom.NewObjectProperty("ContentTypes"), webObj)
-	ctObj, _ := b.AddObject(csom.NewObjectMethod("GetById", []string{`<Parameter Type="String">` + fieldLinks.contentTypeID + `</Parameter>`}), nil)
+	ctObj, _ := b.AddObject(csom.NewObjectMethodWithParams("GetById", csom.NewParamString(fieldLinks.contentTypeID)), nil)
 	b.AddObject(csom.NewObject(`<Property Id="{{.ID}}" ParentId="{{.ParentID}}" Name="FieldLinks" />`), nil)
-	addObj, _ := b.AddObject(csom.NewObjectMethod("Add", []string{`
-		<Parameter TypeId="{63fb2c92-8f65-4bbb-a658-b6cd294403f4}">
-			<Property Name="Field" ObjectPathId="` + strconv.Itoa(fieldID) + `" />
-		</Parameter>
-	`}), nil)
-	b.AddAction(csom.NewActionIdentityQuery(), addObj)
-	b.AddAction(csom.NewActionMethod("Update", []string{`<Parameter Type="Boolean">false</Parameter>`}), ctObj)
+	addObj, _ := b.AddObject(csom.NewObjectMethodWithParams("Add", csom.NewParamObject(
+		"63fb2c92-8f65-4bbb-a658-b6cd294403f4", // FieldLinkCreationInformation
+		csom.NewProperty("Field", csom.NewParamObjectPath(fieldObj)),
+	)), nil)
+	identityQuery, _ := b.AddAction(csom.NewActionIdentityQuery(), addObj)
+	b.AddAction(csom.NewActionMethodWithParams("Update", csom.NewParamBool(false)), ctObj)
 
 	csomPkg, err := b.Compile()
 	if err != nil {
@@ -176,9 +172,5 @@ func (fieldLinks *FieldLinks) Add(name string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	rgx := regexp.MustCompile(`:fl:(.*?)"`)
-	rs := rgx.FindStringSubmatch(fmt.Sprintf("%s", resp))
-	fieldLinkID := rs[1]
-
-	return fieldLinkID, nil
+	return csomIdentityPart(resp, identityQuery, "fl")
 }
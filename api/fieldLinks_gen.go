@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (fieldLinks *FieldLinks) Conf(config *RequestConfig) *FieldLinks {
 	fieldLinks.config = config
@@ -20,6 +24,12 @@ func (fieldLinks *FieldLinks) Filter(oDataFilter string) *FieldLinks {
 	return fieldLinks
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (fieldLinks *FieldLinks) FilterExpr(oDataFilter odata.Expr) *FieldLinks {
+	fieldLinks.modifiers.AddFilter(oDataFilter.String())
+	return fieldLinks
+}
+
 // Top adds $top OData modifier
 func (fieldLinks *FieldLinks) Top(oDataTop int) *FieldLinks {
 	fieldLinks.modifiers.AddTop(oDataTop)
@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (fields *Fields) Conf(config *RequestConfig) *Fields {
 	fields.config = config
@@ -26,6 +30,12 @@ func (fields *Fields) Filter(oDataFilter string) *Fields {
 	return fields
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (fields *Fields) FilterExpr(oDataFilter odata.Expr) *Fields {
+	fields.modifiers.AddFilter(oDataFilter.String())
+	return fields
+}
+
 // Top adds $top OData modifier
 func (fields *Fields) Top(oDataTop int) *Fields {
 	fields.modifiers.AddTop(oDataTop)
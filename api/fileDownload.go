@@ -0,0 +1,255 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// DownloadOptions provides optional settings for DownloadTo method
+type DownloadOptions struct {
+	Parallelism int   // concurrent range requests, 4 by default
+	SegmentSize int64 // range request size in bytes, 10 MB by default
+
+	// Offset is the number of bytes already downloaded to the writer, e.g. a partial file size
+	// or FileDownloadProgressData.Offset of an interrupted download, the download is resumed from the offset
+	Offset int64
+	// ETag of the file when the interrupted download was started, the download fails when the file is changed since then
+	ETag string
+
+	// Progress callback is called after each downloaded segment, return "false" to cancel the download
+	Progress func(data *FileDownloadProgressData) bool
+}
+
+// FileDownloadProgressData describes download Progress callback data
+type FileDownloadProgressData struct {
+	ETag       string // file ETag
+	Size       int64  // file size
+	Downloaded int64  // bytes downloaded, including the resumed offset
+	Offset     int64  // contiguous downloaded bytes from the beginning, a safe offset to resume from
+}
+
+// DownloadTo downloads file content to w with parallel HTTP Range requests on `$value`.
+// When the server ignores ranges the content is downloaded with a single request.
+// The returned data describes the progress, for a failed download its Offset and ETag can be used to resume.
+// The downloaded size and the file ETag are verified at the end.
+func (file *File) DownloadTo(w io.WriterAt, options *DownloadOptions) (*FileDownloadProgressData, error) {
+	opts := &DownloadOptions{}
+	if options != nil {
+		*opts = *options
+	}
+	if opts.Parallelism <= 0 {
+		opts.Parallelism = 4
+	}
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = 10485760
+	}
+	if opts.Progress == nil {
+		opts.Progress = func(data *FileDownloadProgressData) bool {
+			return true
+		}
+	}
+
+	info, err := file.downloadInfo()
+	if err != nil {
+		return nil, err
+	}
+	state := &FileDownloadProgressData{
+		ETag:       info.ETag,
+		Size:       int64(info.Length),
+		Downloaded: opts.Offset,
+		Offset:     opts.Offset,
+	}
+	if opts.ETag != "" && opts.ETag != info.ETag {
+		state.Downloaded, state.Offset = 0, 0
+		return state, fmt.Errorf("file was changed since the download was started")
+	}
+	if opts.Offset > state.Size {
+		state.Downloaded, state.Offset = 0, 0
+		return state, fmt.Errorf("download offset %d exceeds file size %d", opts.Offset, state.Size)
+	}
+
+	d := &rangedDownload{file: file, w: w, options: opts, state: state, done: map[int64]int64{}}
+	if err := d.run(); err != nil {
+		return state, err
+	}
+
+	if state.Downloaded != state.Size {
+		return state, fmt.Errorf("downloaded %d bytes of %d", state.Downloaded, state.Size)
+	}
+	if info, err = file.downloadInfo(); err != nil {
+		return state, err
+	}
+	if info.ETag != state.ETag {
+		return state, fmt.Errorf("file was changed during the download")
+	}
+	return state, nil
+}
+
+// downloadInfo gets file size and ETag
+func (file *File) downloadInfo() (*FileInfo, error) {
+	resp, err := NewFile(file.client, file.endpoint, file.config).Select("Length,ETag").Get()
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data(), nil
+}
+
+// errDownloadCanceled download canceled in Progress callback
+var errDownloadCanceled = fmt.Errorf("file download was canceled")
+
+// rangedDownload parallel ranged download
+type rangedDownload struct {
+	file    *File
+	w       io.WriterAt
+	options *DownloadOptions
+
+	mu    sync.Mutex
+	state *FileDownloadProgressData
+	done  map[int64]int64 // downloaded segments ends by starts, beyond the contiguous offset
+	err   error
+}
+
+// run downloads the rest of the file, the first segment probes ranges support
+func (d *rangedDownload) run() error {
+	start := d.state.Offset
+	if start >= d.state.Size {
+		return nil
+	}
+	ranged, err := d.segment(start, d.end(start))
+	if err != nil || !ranged {
+		return err
+	}
+
+	segments := make(chan int64)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < d.options.Parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range segments {
+				if d.failed() != nil {
+					continue
+				}
+				if _, err := d.segment(start, d.end(start)); err != nil {
+					d.fail(err)
+				}
+			}
+		}()
+	}
+	for start += d.options.SegmentSize; start < d.state.Size && d.failed() == nil; start += d.options.SegmentSize {
+		segments <- start
+	}
+	close(segments)
+	wg.Wait()
+	return d.failed()
+}
+
+// end gets segment's end offset, exclusive
+func (d *rangedDownload) end(start int64) int64 {
+	if end := start + d.options.SegmentSize; end < d.state.Size {
+		return end
+	}
+	return d.state.Size
+}
+
+// segment downloads [start, end) range, returns false when the server responded with the whole content
+func (d *rangedDownload) segment(start int64, end int64) (bool, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/$value", d.file.endpoint), nil)
+	if err != nil {
+		return false, err
+	}
+	if d.file.config != nil && d.file.config.Context != nil {
+		req = req.WithContext(d.file.config.Context)
+	}
+	for key, value := range getConfHeaders(d.file.config) {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+
+	resp, err := d.file.client.Execute(req)
+	if err != nil {
+		return false, err
+	}
+	defer shut(resp.Body)
+
+	ranged := resp.StatusCode == http.StatusPartialContent
+	if !ranged {
+		// Ranges are not supported, the whole content is written from the beginning
+		d.mu.Lock()
+		d.state.Downloaded, d.state.Offset = 0, 0
+		d.mu.Unlock()
+		start, end = 0, d.state.Size
+	}
+
+	buf := make([]byte, 32*1024)
+	pos, reported := start, start
+	for pos < end {
+		n, err := resp.Body.Read(buf)
+		if int64(n) > end-pos {
+			n = int(end - pos)
+		}
+		if n > 0 {
+			if _, err := d.w.WriteAt(buf[:n], pos); err != nil {
+				return ranged, err
+			}
+			pos += int64(n)
+			// The whole content progress is reported by segment size portions
+			if !ranged && (pos-reported >= d.options.SegmentSize || pos == end) {
+				if err := d.complete(reported, pos); err != nil {
+					return ranged, err
+				}
+				reported = pos
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return ranged, err
+		}
+	}
+	if pos != end {
+		return ranged, fmt.Errorf("unexpected end of range %d-%d at %d", start, end-1, pos)
+	}
+	if ranged {
+		return ranged, d.complete(start, end)
+	}
+	return ranged, nil
+}
+
+// complete registers downloaded range, advances the contiguous offset and reports the progress
+func (d *rangedDownload) complete(start int64, end int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.state.Downloaded += end - start
+	d.done[start] = end
+	for {
+		next, ok := d.done[d.state.Offset]
+		if !ok {
+			break
+		}
+		delete(d.done, d.state.Offset)
+		d.state.Offset = next
+	}
+	progress := *d.state
+	if !d.options.Progress(&progress) {
+		return errDownloadCanceled
+	}
+	return nil
+}
+
+func (d *rangedDownload) fail(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *rangedDownload) failed() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
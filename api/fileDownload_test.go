@@ -0,0 +1,94 @@
+package api
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+// memWriterAt in-memory io.WriterAt
+type memWriterAt struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (w *memWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if end := int(off) + len(p); end > len(w.data) {
+		w.data = append(w.data, make([]byte, end-len(w.data))...)
+	}
+	return copy(w.data[off:], p), nil
+}
+
+func TestDownloadTo(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	content := bytes.Repeat([]byte("0123456789"), 10)
+	fileURL := spmock.SitePath + "/Shared Documents/ranged.bin"
+	if err := srv.AddFile(fileURL, content); err != nil {
+		t.Fatal(err)
+	}
+	file := NewSP(srv.Client()).Web().GetFile(fileURL)
+	options := &DownloadOptions{Parallelism: 3, SegmentSize: 16}
+
+	t.Run("Parallel", func(t *testing.T) {
+		w := &memWriterAt{}
+		res, err := file.DownloadTo(w, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(w.data, content) || res.Size != 100 || res.Offset != 100 || res.ETag == "" {
+			t.Errorf("unexpected download result: %+v", res)
+		}
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		w := &memWriterAt{}
+		canceled := *options
+		canceled.Parallelism = 1
+		canceled.Progress = func(data *FileDownloadProgressData) bool {
+			return data.Downloaded < 48
+		}
+		res, err := file.DownloadTo(w, &canceled)
+		if err == nil || res.Offset != 48 {
+			t.Fatalf("download should be canceled at 48, got %+v, %v", res, err)
+		}
+
+		resumed := *options
+		resumed.Offset = res.Offset
+		resumed.ETag = res.ETag
+		var first int64
+		resumed.Progress = func(data *FileDownloadProgressData) bool {
+			if first == 0 {
+				first = data.Downloaded
+			}
+			return true
+		}
+		if _, err := file.DownloadTo(w, &resumed); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(w.data, content) || first != 64 {
+			t.Errorf("download is not resumed from the offset, first progress at %d", first)
+		}
+	})
+
+	t.Run("ChangedFile", func(t *testing.T) {
+		res, err := file.DownloadTo(&memWriterAt{}, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := srv.AddFile(fileURL, content); err != nil {
+			t.Fatal(err)
+		}
+		resumed := *options
+		resumed.Offset = 16
+		resumed.ETag = res.ETag
+		if _, err := file.DownloadTo(&memWriterAt{}, &resumed); err == nil {
+			t.Error("changed file should not be resumed")
+		}
+	})
+}
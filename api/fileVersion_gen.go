@@ -0,0 +1,40 @@
+// Code generated by `ggen -ent FileVersion -conf -mods Select,Expand -helpers Data,Normalized`; DO NOT EDIT.
+
+package api
+
+import (
+	"encoding/json"
+)
+
+// Conf receives custom request config definition, e.g. custom headers, custom OData mod
+func (fileVersion *FileVersion) Conf(config *RequestConfig) *FileVersion {
+	fileVersion.config = config
+	return fileVersion
+}
+
+// Select adds $select OData modifier
+func (fileVersion *FileVersion) Select(oDataSelect string) *FileVersion {
+	fileVersion.modifiers.AddSelect(oDataSelect)
+	return fileVersion
+}
+
+// Expand adds $expand OData modifier
+func (fileVersion *FileVersion) Expand(oDataExpand string) *FileVersion {
+	fileVersion.modifiers.AddExpand(oDataExpand)
+	return fileVersion
+}
+
+/* Response helpers */
+
+// Data response helper
+func (fileVersionResp *FileVersionResp) Data() *FileVersionInfo {
+	data := NormalizeODataItem(*fileVersionResp)
+	res := &FileVersionInfo{}
+	json.Unmarshal(data, &res)
+	return res
+}
+
+// Normalized returns normalized body
+func (fileVersionResp *FileVersionResp) Normalized() []byte {
+	return NormalizeODataItem(*fileVersionResp)
+}
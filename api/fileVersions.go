@@ -0,0 +1,265 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/koltyakov/gosip"
+)
+
+//go:generate ggen -ent FileVersions -item FileVersion -conf -coll -mods Select,Expand,Filter,Top,OrderBy -helpers Data,Normalized
+//go:generate ggen -ent FileVersion -conf -mods Select,Expand -helpers Data,Normalized
+
+// FileVersions represent SharePoint File Versions API queryable collection struct
+// Always use NewFileVersions constructor instead of &FileVersions{}
+type FileVersions struct {
+	client    *gosip.SPClient
+	config    *RequestConfig
+	endpoint  string
+	modifiers *ODataMods
+}
+
+// FileVersion represents SharePoint File Version API queryable object struct
+// Always use NewFileVersion constructor instead of &FileVersion{}
+type FileVersion struct {
+	client    *gosip.SPClient
+	config    *RequestConfig
+	endpoint  string
+	modifiers *ODataMods
+}
+
+// FileVersionInfo - file version API response payload structure
+type FileVersionInfo struct {
+	ID               int       `json:"ID"`
+	VersionLabel     string    `json:"VersionLabel"`
+	IsCurrentVersion bool      `json:"IsCurrentVersion"`
+	Created          time.Time `json:"Created"`
+	CheckInComment   string    `json:"CheckInComment"`
+	Size             int       `json:"Size"`
+	URL              string    `json:"Url"`
+	CreatedBy        *UserInfo `json:"CreatedBy"` // available when expanded
+}
+
+// FileVersionsResp - file versions response type with helper processor methods
+type FileVersionsResp []byte
+
+// FileVersionResp - file version response type with helper processor methods
+type FileVersionResp []byte
+
+// NewFileVersions - FileVersions struct constructor function
+func NewFileVersions(client *gosip.SPClient, endpoint string, config *RequestConfig) *FileVersions {
+	return &FileVersions{
+		client:    client,
+		endpoint:  endpoint,
+		config:    config,
+		modifiers: NewODataMods(),
+	}
+}
+
+// NewFileVersion - FileVersion struct constructor function
+func NewFileVersion(client *gosip.SPClient, endpoint string, config *RequestConfig) *FileVersion {
+	return &FileVersion{
+		client:    client,
+		endpoint:  endpoint,
+		config:    config,
+		modifiers: NewODataMods(),
+	}
+}
+
+// Versions gets file's versions API instance queryable collection
+func (file *File) Versions() *FileVersions {
+	return NewFileVersions(
+		file.client,
+		fmt.Sprintf("%s/Versions", file.endpoint),
+		file.config,
+	)
+}
+
+// ToURL gets endpoint with modificators raw URL
+func (fileVersions *FileVersions) ToURL() string {
+	return toURL(fileVersions.endpoint, fileVersions.modifiers)
+}
+
+// Get gets file's previous versions collection, the current version is not included
+func (fileVersions *FileVersions) Get() (FileVersionsResp, error) {
+	client := NewHTTPClient(fileVersions.client)
+	return client.Get(fileVersions.ToURL(), fileVersions.config)
+}
+
+// GetByID gets file version by its ID, e.g. 512 for version 1.0
+func (fileVersions *FileVersions) GetByID(versionID int) *FileVersion {
+	return NewFileVersion(
+		fileVersions.client,
+		fmt.Sprintf("%s(%d)", fileVersions.endpoint, versionID),
+		fileVersions.config,
+	)
+}
+
+// DeleteAll deletes all previous versions of the file
+func (fileVersions *FileVersions) DeleteAll() error {
+	return fileVersions.call("DeleteAll()")
+}
+
+// DeleteByID deletes a version by its ID
+func (fileVersions *FileVersions) DeleteByID(versionID int) error {
+	return fileVersions.call(fmt.Sprintf("DeleteByID(vid=%d)", versionID))
+}
+
+// DeleteByLabel deletes a version by its label, e.g. "1.0"
+func (fileVersions *FileVersions) DeleteByLabel(label string) error {
+	return fileVersions.call(fmt.Sprintf("DeleteByLabel(versionlabel='%s')", label))
+}
+
+// RecycleByID moves a version to the recycle bin by its ID
+func (fileVersions *FileVersions) RecycleByID(versionID int) error {
+	return fileVersions.call(fmt.Sprintf("RecycleByID(vid=%d)", versionID))
+}
+
+// RecycleByLabel moves a version to the recycle bin by its label
+func (fileVersions *FileVersions) RecycleByLabel(label string) error {
+	return fileVersions.call(fmt.Sprintf("RecycleByLabel(versionlabel='%s')", label))
+}
+
+// RestoreByLabel restores a version by its label creating a new current version
+func (fileVersions *FileVersions) RestoreByLabel(label string) error {
+	return fileVersions.call(fmt.Sprintf("RestoreByLabel(versionlabel='%s')", label))
+}
+
+// Trim deletes old versions keeping the most recent keepMajor major and keepMinor minor versions,
+// negative values keep all the versions of the kind, the current version is never deleted.
+// Deleted versions IDs are returned.
+func (fileVersions *FileVersions) Trim(keepMajor int, keepMinor int) ([]int, error) {
+	versions := NewFileVersions(fileVersions.client, fileVersions.endpoint, fileVersions.config)
+	resp, err := versions.Select("ID,VersionLabel").Get()
+	if err != nil {
+		return nil, err
+	}
+	var refs []*versionRef
+	for _, v := range resp.Data() {
+		data := v.Data()
+		refs = append(refs, &versionRef{ID: data.ID, Label: data.VersionLabel})
+	}
+	var deleted []int
+	for _, id := range versionsToTrim(refs, keepMajor, keepMinor) {
+		if err := fileVersions.DeleteByID(id); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, id)
+	}
+	return deleted, nil
+}
+
+func (fileVersions *FileVersions) call(method string) error {
+	client := NewHTTPClient(fileVersions.client)
+	endpoint := fmt.Sprintf("%s/%s", fileVersions.endpoint, method)
+	_, err := client.Post(endpoint, nil, fileVersions.config)
+	return err
+}
+
+// ToURL gets endpoint with modificators raw URL
+func (fileVersion *FileVersion) ToURL() string {
+	return toURL(fileVersion.endpoint, fileVersion.modifiers)
+}
+
+// Get gets file version data object
+func (fileVersion *FileVersion) Get() (FileVersionResp, error) {
+	client := NewHTTPClient(fileVersion.client)
+	return client.Get(fileVersion.ToURL(), fileVersion.config)
+}
+
+// GetReader gets file version content io.ReadCloser
+func (fileVersion *FileVersion) GetReader() (io.ReadCloser, error) {
+	endpoint := fmt.Sprintf("%s/$value", fileVersion.endpoint)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply context
+	if fileVersion.config != nil && fileVersion.config.Context != nil {
+		req = req.WithContext(fileVersion.config.Context)
+	}
+
+	req.TransferEncoding = []string{"null"}
+	for key, value := range getConfHeaders(fileVersion.config) {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := fileVersion.client.Execute(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Download file version bytes
+func (fileVersion *FileVersion) Download() ([]byte, error) {
+	body, err := fileVersion.GetReader()
+	if err != nil {
+		return nil, err
+	}
+	defer shut(body)
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+/* Versions helpers */
+
+// versionRef version identity used in trimming
+type versionRef struct {
+	ID    int
+	Label string
+}
+
+// parseVersionLabel parses version label, e.g. "3.2" is major 3 and minor 2
+func parseVersionLabel(label string) (int, int, error) {
+	parts := strings.SplitN(label, ".", 2)
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("wrong version label %s", label)
+	}
+	minor := 0
+	if len(parts) == 2 {
+		if minor, err = strconv.Atoi(parts[1]); err != nil {
+			return 0, 0, fmt.Errorf("wrong version label %s", label)
+		}
+	}
+	return major, minor, nil
+}
+
+// versionsToTrim gets IDs of the versions exceeding keepMajor major and keepMinor minor most recent versions
+func versionsToTrim(versions []*versionRef, keepMajor int, keepMinor int) []int {
+	sorted := append([]*versionRef{}, versions...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID > sorted[j].ID })
+	var ids []int
+	majors, minors := 0, 0
+	for _, v := range sorted {
+		_, minor, err := parseVersionLabel(v.Label)
+		if err != nil {
+			continue
+		}
+		if minor == 0 {
+			majors++
+			if keepMajor >= 0 && majors > keepMajor {
+				ids = append(ids, v.ID)
+			}
+			continue
+		}
+		minors++
+		if keepMinor >= 0 && minors > keepMinor {
+			ids = append(ids, v.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestVersionsToTrim(t *testing.T) {
+	versions := []*versionRef{
+		{ID: 512, Label: "1.0"},
+		{ID: 513, Label: "1.1"},
+		{ID: 1024, Label: "2.0"},
+		{ID: 1025, Label: "2.1"},
+		{ID: 1026, Label: "2.2"},
+		{ID: 1536, Label: "3.0"},
+		{ID: 1537, Label: "3.1"},
+	}
+	cases := []struct {
+		keepMajor int
+		keepMinor int
+		expected  string
+	}{
+		{-1, -1, "[]"},
+		{1, -1, "[1024 512]"},
+		{-1, 1, "[1026 1025 513]"},
+		{0, 0, "[1537 1536 1026 1025 1024 513 512]"},
+		{2, 2, "[1025 513 512]"},
+	}
+	for _, c := range cases {
+		ids := versionsToTrim(versions, c.keepMajor, c.keepMinor)
+		if actual := fmt.Sprintf("%v", ids); actual != c.expected {
+			t.Errorf("keep %d/%d: expected %s, got %s", c.keepMajor, c.keepMinor, c.expected, actual)
+		}
+	}
+
+	if major, minor, err := parseVersionLabel("12.3"); err != nil || major != 12 || minor != 3 {
+		t.Errorf("unexpected label parsing: %d.%d, %v", major, minor, err)
+	}
+	if _, _, err := parseVersionLabel("draft"); err == nil {
+		t.Error("wrong label should fail")
+	}
+}
@@ -42,6 +42,11 @@ func (files *Files) Get() (FilesResp, error) {
 	return client.Get(files.ToURL(), files.config)
 }
 
+// Iterator gets files collection pages iterator, page size is controlled with Top modifier
+func (files *Files) Iterator() *Iterator {
+	return NewSkipIterator(files.client, files.ToURL(), files.config)
+}
+
 // GetByName gets a file by its name
 func (files *Files) GetByName(fileName string) *File {
 	return NewFile(
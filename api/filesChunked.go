@@ -27,6 +27,7 @@ type FileUploadProgressData struct {
 }
 
 // AddChunked uploads a file in chunks (streaming), is a good fit for large files. Supported starting from SharePoint 2016.
+// A started upload session is canceled when reading the stream or uploading a chunk fails.
 func (files *Files) AddChunked(name string, stream io.Reader, options *AddChunkedOptions) (FileResp, error) {
 	web := NewSP(files.client).Web().Conf(files.config)
 	var file *File
@@ -63,12 +64,25 @@ func (files *Files) AddChunked(name string, stream io.Reader, options *AddChunke
 		FileOffset:  0,
 	}
 
+	// abortUpload cancels upload session of the added file on a failure, the failure is returned as is,
+	// a cancel is attempted even if the session wasn't confirmed as StartUpload may fail after the server started it
+	abortUpload := func(err error) (FileResp, error) {
+		if file != nil {
+			_ = file.cancelUpload(uploadID)
+		}
+		return nil, err
+	}
+
 	slot := make([]byte, options.ChunkSize)
 	for {
-		size, err := stream.Read(slot)
+		// Partial reads, e.g. from network streams, are not treated as the last chunk
+		size, err := io.ReadFull(stream, slot)
 		if err == io.EOF {
 			break
 		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return abortUpload(err)
+		}
 		chunk := slot[:size]
 
 		// Upload in a call if file size is less than chunk size
@@ -85,7 +99,11 @@ func (files *Files) AddChunked(name string, stream io.Reader, options *AddChunke
 			if file == nil {
 				return nil, fmt.Errorf("can't get file object")
 			}
-			return file.finishUpload(uploadID, progress.FileOffset, chunk)
+			fileResp, err := file.finishUpload(uploadID, progress.FileOffset, chunk)
+			if err != nil {
+				return abortUpload(err)
+			}
+			return fileResp, nil
 		}
 
 		// Initial chunked upload
@@ -96,12 +114,12 @@ func (files *Files) AddChunked(name string, stream io.Reader, options *AddChunke
 			}
 			fileResp, err := files.Add(name, nil, options.Overwrite)
 			if err != nil {
-				return nil, err
+				return abortUpload(err)
 			}
 			file = web.GetFile(fileResp.Data().ServerRelativeURL)
 			offset, err := file.startUpload(uploadID, chunk)
 			if err != nil {
-				return nil, err
+				return abortUpload(err)
 			}
 			progress.FileOffset = offset
 		} else { // or continue chunk upload
@@ -114,7 +132,7 @@ func (files *Files) AddChunked(name string, stream io.Reader, options *AddChunke
 			}
 			offset, err := file.continueUpload(uploadID, progress.FileOffset, chunk)
 			if err != nil {
-				return nil, err
+				return abortUpload(err)
 			}
 			progress.FileOffset = offset
 		}
@@ -129,7 +147,11 @@ func (files *Files) AddChunked(name string, stream io.Reader, options *AddChunke
 	if file == nil {
 		return nil, fmt.Errorf("can't get file object")
 	}
-	return file.finishUpload(uploadID, progress.FileOffset, nil)
+	fileResp, err := file.finishUpload(uploadID, progress.FileOffset, nil)
+	if err != nil {
+		return abortUpload(err)
+	}
+	return fileResp, nil
 }
 
 // startUpload starts uploading a document using chunk API
@@ -140,17 +162,7 @@ func (file *File) startUpload(uploadID string, chunk []byte) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	data = NormalizeODataItem(data)
-	if res, err := strconv.Atoi(fmt.Sprintf("%s", data)); err == nil {
-		return res, nil
-	}
-	res := &struct {
-		StartUpload int `json:"StartUpload,string"`
-	}{}
-	if err := json.Unmarshal(data, &res); err != nil {
-		return 0, err
-	}
-	return res.StartUpload, nil
+	return parseUploadOffset(data, "StartUpload")
 }
 
 // continueUpload continues uploading a document using chunk API
@@ -161,17 +173,7 @@ func (file *File) continueUpload(uploadID string, fileOffset int, chunk []byte)
 	if err != nil {
 		return 0, err
 	}
-	data = NormalizeODataItem(data)
-	if res, err := strconv.Atoi(fmt.Sprintf("%s", data)); err == nil {
-		return res, nil
-	}
-	res := &struct {
-		ContinueUpload int `json:"ContinueUpload,string"`
-	}{}
-	if err := json.Unmarshal(data, &res); err != nil {
-		return 0, err
-	}
-	return res.ContinueUpload, nil
+	return parseUploadOffset(data, "ContinueUpload")
 }
 
 // cancelUpload cancels document upload using chunk API
@@ -188,3 +190,24 @@ func (file *File) finishUpload(uploadID string, fileOffset int, chunk []byte) (F
 	endpoint := fmt.Sprintf("%s/FinishUpload(uploadId=guid'%s',fileOffset=%d)", file.endpoint, uploadID, fileOffset)
 	return client.Post(endpoint, bytes.NewBuffer(chunk), file.config)
 }
+
+// parseUploadOffset parses StartUpload/ContinueUpload response offset,
+// the offset is a string in `d.<method>` (verbose), `value` (minimal and no metadata) or a plain number
+func parseUploadOffset(data []byte, method string) (int, error) {
+	data = NormalizeODataItem(data)
+	if res, err := strconv.Atoi(fmt.Sprintf("%s", data)); err == nil {
+		return res, nil
+	}
+	res := map[string]interface{}{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return 0, err
+	}
+	value, ok := res[method]
+	if !ok {
+		value, ok = res["value"]
+	}
+	if !ok {
+		return 0, fmt.Errorf("can't get %s offset from the response", method)
+	}
+	return strconv.Atoi(fmt.Sprintf("%v", value))
+}
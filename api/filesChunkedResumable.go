@@ -0,0 +1,249 @@
+package api
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UploadState describes resumable chunked upload state
+type UploadState struct {
+	UploadID    string    `json:"uploadId"`    // upload session ID
+	FileURL     string    `json:"fileUrl"`     // target file server relative URL
+	Offset      int       `json:"offset"`      // last offset confirmed by the server
+	ChunkSize   int       `json:"chunkSize"`   // chunk size in bytes
+	Fingerprint string    `json:"fingerprint"` // source size and confirmed content SHA-256 hash
+	Updated     time.Time `json:"updated"`     // last confirmed chunk time
+}
+
+// UploadStateStore persists resumable uploads state between the process runs,
+// the key identifies target folder and file name
+type UploadStateStore interface {
+	Load(key string) (*UploadState, error) // should return nil state and nil error when there is no saved state
+	Save(key string, state *UploadState) error
+	Delete(key string) error
+}
+
+// AddChunkedResumable uploads a file in chunks persisting the progress to the store after each confirmed chunk.
+// When the store has a state for the same target, the already uploaded part of the stream is verified against
+// the state fingerprint, and the upload continues from the last confirmed offset within the same upload session.
+// Stale sessions, i.e. sessions of a changed source, different chunk size, or sessions rejected by the server,
+// are canceled and the upload starts over.
+// The state is removed from the store when the upload is finished or canceled in Progress callback.
+// Supported starting from SharePoint 2016.
+func (files *Files) AddChunkedResumable(name string, stream io.ReadSeeker, store UploadStateStore, options *AddChunkedOptions) (FileResp, error) {
+	opts := &AddChunkedOptions{Overwrite: true}
+	if options != nil {
+		*opts = *options
+	}
+	if opts.Progress == nil {
+		opts.Progress = func(data *FileUploadProgressData) bool {
+			return true
+		}
+	}
+	if opts.ChunkSize == 0 {
+		opts.ChunkSize = 10485760
+	}
+
+	size, err := stream.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stream.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	// Upload in a call if file size is not greater than chunk size
+	if size <= int64(opts.ChunkSize) {
+		content := make([]byte, size)
+		if _, err := io.ReadFull(stream, content); err != nil {
+			return nil, err
+		}
+		return files.Add(name, content, opts.Overwrite)
+	}
+
+	u := &resumableUpload{
+		files:     files,
+		name:      name,
+		key:       files.endpoint + "/" + name,
+		stream:    stream,
+		size:      size,
+		store:     store,
+		options:   opts,
+		overwrite: opts.Overwrite,
+	}
+
+	state, err := store.Load(u.key)
+	if err != nil {
+		return nil, err
+	}
+	if state != nil && (state.ChunkSize != opts.ChunkSize || int64(state.Offset) >= u.size) {
+		if err := u.cancel(state); err != nil {
+			return nil, err
+		}
+		state = nil
+	}
+	if state != nil {
+		fileResp, err := u.upload(state)
+		if err != errStaleUpload && !isUploadRejected(err) {
+			return fileResp, err
+		}
+		// The source is changed, the session is expired or the offset is not confirmed, starting over
+		if err := u.cancel(state); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.upload(&UploadState{
+		UploadID:  uuid.New().String(),
+		ChunkSize: opts.ChunkSize,
+	})
+}
+
+var (
+	errUploadCanceled = fmt.Errorf("file upload was canceled")            // upload canceled in Progress callback
+	errStaleUpload    = fmt.Errorf("the source doesn't match the upload") // uploaded content fingerprint mismatch
+)
+
+// resumableUpload resumable chunked upload
+type resumableUpload struct {
+	files   *Files
+	name    string
+	key     string
+	stream  io.ReadSeeker
+	size    int64
+	store   UploadStateStore
+	options *AddChunkedOptions
+
+	overwrite bool // the restarted upload overwrites the file created by the stale session
+}
+
+// upload uploads the stream starting from the state's offset, a new session is started when the state has no file URL
+func (u *resumableUpload) upload(state *UploadState) (FileResp, error) {
+	web := NewSP(u.files.client).Web().Conf(u.files.config)
+	if _, err := u.stream.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	if _, err := io.CopyN(hash, u.stream, int64(state.Offset)); err != nil {
+		return nil, err
+	}
+	if state.Offset > 0 && u.fingerprint(hash) != state.Fingerprint {
+		return nil, errStaleUpload
+	}
+
+	progress := &FileUploadProgressData{
+		UploadID:    state.UploadID,
+		Stage:       "continue",
+		ChunkSize:   state.ChunkSize,
+		BlockNumber: state.Offset / state.ChunkSize,
+		FileOffset:  state.Offset,
+	}
+
+	var file *File
+	if state.FileURL != "" {
+		file = web.GetFile(state.FileURL)
+	}
+
+	chunk := make([]byte, state.ChunkSize)
+	for {
+		size, err := io.ReadFull(u.stream, chunk)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		last := int64(state.Offset+size) >= u.size
+
+		if file == nil {
+			progress.Stage = "starting"
+			if !u.options.Progress(progress) {
+				return nil, errUploadCanceled
+			}
+			fileResp, err := u.files.Add(u.name, nil, u.overwrite)
+			if err != nil {
+				return nil, err
+			}
+			state.FileURL = fileResp.Data().ServerRelativeURL
+			file = web.GetFile(state.FileURL)
+			offset, err := file.startUpload(state.UploadID, chunk[:size])
+			if err != nil {
+				return nil, err
+			}
+			state.Offset = offset
+		} else if last {
+			progress.Stage = "finishing"
+			if !u.options.Progress(progress) {
+				return nil, u.cancelByCallback(state)
+			}
+			fileResp, err := file.finishUpload(state.UploadID, state.Offset, chunk[:size])
+			if err != nil {
+				return nil, err
+			}
+			return fileResp, u.store.Delete(u.key)
+		} else {
+			progress.Stage = "continue"
+			if !u.options.Progress(progress) {
+				return nil, u.cancelByCallback(state)
+			}
+			offset, err := file.continueUpload(state.UploadID, state.Offset, chunk[:size])
+			if err != nil {
+				return nil, err
+			}
+			state.Offset = offset
+		}
+
+		_, _ = hash.Write(chunk[:size])
+		state.Fingerprint = u.fingerprint(hash)
+		state.Updated = time.Now().UTC()
+		if err := u.store.Save(u.key, state); err != nil {
+			return nil, err
+		}
+		progress.FileOffset = state.Offset
+		progress.BlockNumber++
+	}
+}
+
+// cancel cancels stale upload session and removes its state
+func (u *resumableUpload) cancel(state *UploadState) error {
+	if state.FileURL != "" {
+		web := NewSP(u.files.client).Web().Conf(u.files.config)
+		// The session can be already expired or finished on the server
+		_ = web.GetFile(state.FileURL).cancelUpload(state.UploadID)
+		u.overwrite = true
+	}
+	return u.store.Delete(u.key)
+}
+
+// cancelByCallback cancels the upload canceled in Progress callback
+func (u *resumableUpload) cancelByCallback(state *UploadState) error {
+	if err := u.cancel(state); err != nil {
+		return err
+	}
+	return errUploadCanceled
+}
+
+// isUploadRejected checks the upload session is rejected by the server, other errors, e.g. network failures,
+// keep the state to resume later
+func isUploadRejected(err error) bool {
+	if err == errUploadCanceled {
+		return false
+	}
+	// HTTP errors are "<status> :: <details>" wrapped by the API client
+	for ; err != nil; err = errors.Unwrap(err) {
+		for _, status := range []string{"400 ", "404 ", "410 "} {
+			if strings.HasPrefix(err.Error(), status) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fingerprint gets source fingerprint by the hash of the confirmed content
+func (u *resumableUpload) fingerprint(hash hash.Hash) string {
+	return fmt.Sprintf("%d:%x", u.size, hash.Sum(nil))
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+// uploadStore in-memory UploadStateStore
+type uploadStore map[string]UploadState
+
+func (s uploadStore) Load(key string) (*UploadState, error) {
+	state, ok := s[key]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (s uploadStore) Save(key string, state *UploadState) error {
+	s[key] = *state
+	return nil
+}
+
+func (s uploadStore) Delete(key string) error {
+	delete(s, key)
+	return nil
+}
+
+// failingReader fails reading after the limit is reached
+type failingReader struct {
+	*bytes.Reader
+	limit int64
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	pos, _ := r.Seek(0, io.SeekCurrent)
+	if pos >= r.limit {
+		return 0, fmt.Errorf("connection lost")
+	}
+	if rest := r.limit - pos; int64(len(p)) > rest {
+		p = p[:rest]
+	}
+	return r.Reader.Read(p)
+}
+
+func TestChunkedUploadResume(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	sp := NewSP(srv.Client())
+	files := sp.Web().GetFolder("Shared Documents").Files()
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	store := uploadStore{}
+
+	download := func(t *testing.T, fileResp FileResp) []byte {
+		data, err := sp.Web().GetFile(fileResp.Data().ServerRelativeURL).Download()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	t.Run("Resume", func(t *testing.T) {
+		source := &failingReader{Reader: bytes.NewReader(content), limit: 20}
+		if _, err := files.AddChunkedResumable("resume.txt", source, store, &AddChunkedOptions{ChunkSize: 8}); err == nil {
+			t.Fatal("interrupted upload should fail")
+		}
+		var state UploadState
+		for _, s := range store {
+			state = s
+		}
+		if len(store) != 1 || state.Offset != 16 {
+			t.Fatalf("unexpected saved state: %+v", store)
+		}
+
+		var offsets []int
+		options := &AddChunkedOptions{
+			ChunkSize: 8,
+			Progress: func(data *FileUploadProgressData) bool {
+				if data.UploadID != state.UploadID {
+					t.Errorf("upload session is not resumed: %s", data.UploadID)
+				}
+				offsets = append(offsets, data.FileOffset)
+				return true
+			},
+		}
+		fileResp, err := files.AddChunkedResumable("resume.txt", bytes.NewReader(content), store, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(offsets) != "[16 24 32]" {
+			t.Errorf("unexpected resumed offsets: %v", offsets)
+		}
+		if !bytes.Equal(download(t, fileResp), content) || len(store) != 0 || srv.UploadSessions() != 0 {
+			t.Error("resumed upload is not finished")
+		}
+	})
+
+	t.Run("ChangedSource", func(t *testing.T) {
+		source := &failingReader{Reader: bytes.NewReader(content), limit: 20}
+		if _, err := files.AddChunkedResumable("changed.txt", source, store, &AddChunkedOptions{ChunkSize: 8}); err == nil {
+			t.Fatal("interrupted upload should fail")
+		}
+		changed := bytes.ToUpper(content)
+		fileResp, err := files.AddChunkedResumable("changed.txt", bytes.NewReader(changed), store, &AddChunkedOptions{ChunkSize: 8})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(download(t, fileResp), changed) || srv.UploadSessions() != 0 {
+			t.Error("stale session is not replaced")
+		}
+	})
+
+	t.Run("RejectedSession", func(t *testing.T) {
+		source := &failingReader{Reader: bytes.NewReader(content), limit: 20}
+		if _, err := files.AddChunkedResumable("rejected.txt", source, store, &AddChunkedOptions{ChunkSize: 8}); err == nil {
+			t.Fatal("interrupted upload should fail")
+		}
+		// The session is gone with the file
+		if err := sp.Web().GetFile(spmock.SitePath + "/Shared Documents/rejected.txt").Delete(); err != nil {
+			t.Fatal(err)
+		}
+		fileResp, err := files.AddChunkedResumable("rejected.txt", bytes.NewReader(content), store, &AddChunkedOptions{ChunkSize: 8})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(download(t, fileResp), content) || srv.UploadSessions() != 0 {
+			t.Error("rejected session is not restarted")
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		options := &AddChunkedOptions{
+			ChunkSize: 8,
+			Progress: func(data *FileUploadProgressData) bool {
+				return data.BlockNumber < 2
+			},
+		}
+		if _, err := files.AddChunkedResumable("cancel.txt", bytes.NewReader(content), store, options); err == nil {
+			t.Fatal("canceled upload should fail")
+		}
+		if len(store) != 0 || srv.UploadSessions() != 0 {
+			t.Error("canceled upload is not cleaned up")
+		}
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/koltyakov/gosip/faults"
+	"github.com/koltyakov/gosip/test/spmock"
 )
 
 func TestFilesChunked(t *testing.T) {
@@ -126,3 +128,52 @@ func TestFilesChunked(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestFilesChunkedStartFailed(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	client := srv.Client()
+	// The session is started by the server, but the response is lost
+	faults.New(faults.Script(`/StartUpload`, faults.Truncate(0))).Attach(client)
+	web := NewSP(client).Web()
+
+	uploadID := ""
+	_, err := web.GetFolder("Shared Documents").Files().AddChunked("chunked.txt", strings.NewReader("0123456789"), &AddChunkedOptions{
+		Overwrite: true,
+		ChunkSize: 4,
+		Progress: func(data *FileUploadProgressData) bool {
+			uploadID = data.UploadID
+			return true
+		},
+	})
+	if err == nil {
+		t.Fatal("failed StartUpload is expected to fail the upload")
+	}
+
+	file := web.GetFile("Shared Documents/chunked.txt")
+	if _, err := file.continueUpload(uploadID, 4, []byte("4567")); err == nil || !strings.Contains(err.Error(), "was not found") {
+		t.Errorf("upload session should be canceled: %v", err)
+	}
+}
+
+func TestParseUploadOffset(t *testing.T) {
+	cases := map[string]int{
+		`{"d":{"StartUpload":"10"}}`: 10,
+		`{"value":"20"}`:             20,
+		`{"odata.metadata":"https://contoso.sharepoint.com/_api/$metadata#Edm.Int64","value":"30"}`: 30,
+		`40`: 40,
+	}
+	for payload, expected := range cases {
+		offset, err := parseUploadOffset([]byte(payload), "StartUpload")
+		if err != nil {
+			t.Error(err)
+		}
+		if offset != expected {
+			t.Errorf("expected %d offset for %s, got %d", expected, payload, offset)
+		}
+	}
+	if _, err := parseUploadOffset([]byte(`{}`), "StartUpload"); err == nil {
+		t.Error("missing offset should fail")
+	}
+}
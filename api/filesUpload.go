@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// UploadOptions provides optional settings for Upload method
+type UploadOptions struct {
+	Overwrite bool // should overwrite existing file
+
+	// ChunkThreshold is the size in bytes starting from which files are uploaded in chunks, ChunkSize by default
+	ChunkThreshold int64
+	ChunkSize      int                                     // chunk size in bytes, 10 MB by default
+	Progress       func(data *FileUploadProgressData) bool // chunked upload progress callback, see AddChunkedOptions
+
+	// Metadata list item fields values to set on the uploaded file, e.g. {"Title": "Report"}
+	Metadata map[string]interface{}
+}
+
+// Upload uploads a file from the stream, size is the content length or -1 when it's unknown.
+// Files up to the threshold are streamed in a single request, larger files and files of unknown size
+// are uploaded in chunks. On servers without chunked upload API (SharePoint 2013, detected by LibraryVersion)
+// files are always uploaded in a single request. Metadata is applied to the file's list item after the upload,
+// when metadata update fails the uploaded file response is returned along with the error.
+func (files *Files) Upload(name string, content io.Reader, size int64, options *UploadOptions) (FileResp, error) {
+	opts := &UploadOptions{}
+	if options != nil {
+		*opts = *options
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = 10485760
+	}
+	if opts.ChunkThreshold <= 0 {
+		opts.ChunkThreshold = int64(opts.ChunkSize)
+	}
+	if size >= 0 {
+		content = io.LimitReader(content, size)
+	}
+
+	var fileResp FileResp
+	var err error
+	if size >= 0 && size <= opts.ChunkThreshold {
+		fileResp, err = files.addStream(name, content, size, opts.Overwrite)
+	} else if chunked, e := files.chunkedUploadSupported(); e != nil {
+		return nil, e
+	} else if chunked {
+		fileResp, err = files.AddChunked(name, content, &AddChunkedOptions{
+			Overwrite: opts.Overwrite,
+			Progress:  opts.Progress,
+			ChunkSize: opts.ChunkSize,
+		})
+	} else {
+		fileResp, err = files.addStream(name, content, size, opts.Overwrite)
+	}
+	if err != nil || len(opts.Metadata) == 0 {
+		return fileResp, err
+	}
+
+	body, err := json.Marshal(opts.Metadata)
+	if err != nil {
+		return fileResp, err
+	}
+	web := NewSP(files.client).Web().Conf(files.config)
+	item, err := web.GetFile(fileResp.Data().ServerRelativeURL).GetItem()
+	if err != nil {
+		return fileResp, fmt.Errorf("file is uploaded but metadata is not set: %w", err)
+	}
+	if _, err := item.Update(body); err != nil {
+		return fileResp, fmt.Errorf("file is uploaded but metadata is not set: %w", err)
+	}
+	return fileResp, nil
+}
+
+// addStream uploads file content in a single request without buffering it in a byte slice
+func (files *Files) addStream(name string, content io.Reader, size int64, overwrite bool) (FileResp, error) {
+	endpoint := fmt.Sprintf("%s/Add(overwrite=%t,url='%s')", files.endpoint, overwrite, name)
+	req, err := http.NewRequest("POST", endpoint, content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a request: %w", err)
+	}
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+
+	// Apply context
+	if files.config != nil && files.config.Context != nil {
+		req = req.WithContext(files.config.Context)
+	}
+
+	req.Header.Set("Accept", "application/json;odata=verbose")
+	for key, value := range getConfHeaders(files.config) {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := files.client.Execute(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to request api: %w", err)
+	}
+	defer shut(resp.Body)
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// chunkedUploadSupported checks the server supports chunked upload API, LibraryVersion is cached per site
+func (files *Files) chunkedUploadSupported() (bool, error) {
+	siteURL := getPriorEndpoint(files.endpoint, "/_api")
+	cacheKey := strings.ToLower(siteURL + "@libraryversion")
+	version, found := storage.Get(cacheKey)
+	if !found {
+		info, err := NewContext(files.client, siteURL, files.config).Get()
+		if err != nil {
+			return false, err
+		}
+		version = info.LibraryVersion
+		storage.Set(cacheKey, version, 0)
+	}
+	major, _ := strconv.Atoi(strings.Split(version.(string), ".")[0])
+	return major >= 16, nil
+}
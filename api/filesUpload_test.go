@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+// partialReader returns at most n bytes per read, like network streams
+type partialReader struct {
+	io.Reader
+	n int
+}
+
+func (r *partialReader) Read(p []byte) (int, error) {
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+	return r.Reader.Read(p)
+}
+
+func TestUpload(t *testing.T) {
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	upload := func(t *testing.T, srv *spmock.Server, name string, size int64, options *UploadOptions) (int, []byte) {
+		sp := NewSP(srv.Client())
+		chunks := 0
+		options.ChunkSize = 8
+		options.Progress = func(data *FileUploadProgressData) bool {
+			chunks++
+			return true
+		}
+		stream := &partialReader{Reader: bytes.NewReader(content), n: 3}
+		fileResp, err := sp.Web().GetFolder("Shared Documents").Files().Upload(name, stream, size, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := sp.Web().GetFile(fileResp.Data().ServerRelativeURL).Download()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return chunks, data
+	}
+
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	t.Run("Small", func(t *testing.T) {
+		options := &UploadOptions{ChunkThreshold: 100, Metadata: map[string]interface{}{"Title": "Small file"}}
+		chunks, data := upload(t, srv, "small.txt", int64(len(content)), options)
+		if chunks != 0 || !bytes.Equal(data, content) {
+			t.Errorf("unexpected upload: %d chunks, %s", chunks, data)
+		}
+		item, err := NewSP(srv.Client()).Web().GetFile(spmock.SitePath + "/Shared Documents/small.txt").GetItem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		itemResp, err := item.Select("Title").Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if itemResp.Data().Title != "Small file" {
+			t.Errorf("metadata is not applied: %s", itemResp)
+		}
+	})
+
+	t.Run("Chunked", func(t *testing.T) {
+		for _, size := range []int64{int64(len(content)), -1} {
+			chunks, data := upload(t, srv, "large.txt", size, &UploadOptions{Overwrite: true})
+			if chunks < 5 || !bytes.Equal(data, content) {
+				t.Errorf("unexpected upload of %d size: %d chunks, %s", size, chunks, data)
+			}
+		}
+	})
+
+	t.Run("SP2013", func(t *testing.T) {
+		srv := spmock.NewServer()
+		defer srv.Close()
+		srv.LibraryVersion = "15.0.0.0"
+		for _, size := range []int64{int64(len(content)), -1} {
+			chunks, data := upload(t, srv, "large.txt", size, &UploadOptions{Overwrite: true})
+			if chunks != 0 || !bytes.Equal(data, content) {
+				t.Errorf("unexpected upload of %d size: %d chunks, %s", size, chunks, data)
+			}
+		}
+	})
+}
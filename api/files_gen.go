@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (files *Files) Conf(config *RequestConfig) *Files {
 	files.config = config
@@ -26,6 +30,12 @@ func (files *Files) Filter(oDataFilter string) *Files {
 	return files
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (files *Files) FilterExpr(oDataFilter odata.Expr) *Files {
+	files.modifiers.AddFilter(oDataFilter.String())
+	return files
+}
+
 // Top adds $top OData modifier
 func (files *Files) Top(oDataTop int) *Files {
 	files.modifiers.AddTop(oDataTop)
@@ -36,6 +36,14 @@ type FolderInfo struct {
 	WelcomePage       string    `json:"WelcomePage"`
 }
 
+// StorageMetricsInfo - folder storage metrics response payload structure
+type StorageMetricsInfo struct {
+	LastModified        time.Time `json:"LastModified"`
+	TotalFileCount      int64     `json:"TotalFileCount,string"`
+	TotalFileStreamSize int64     `json:"TotalFileStreamSize,string"` // current versions size
+	TotalSize           int64     `json:"TotalSize,string"`           // size including versions and metadata
+}
+
 // FolderResp - folder response type with helper processor methods
 type FolderResp []byte
 
@@ -173,8 +181,20 @@ func (folder *Folder) ContextInfo() (*ContextInfo, error) {
 	return NewContext(folder.client, folder.ToURL(), folder.config).Get()
 }
 
-// ToDo:
-// StorageMetrics
+// StorageMetrics gets folder storage metrics: total size including versions, files count and last modification date,
+// the metrics are calculated for the whole folder tree. Supported in SharePoint Online
+func (folder *Folder) StorageMetrics() (*StorageMetricsInfo, error) {
+	client := NewHTTPClient(folder.client)
+	data, err := client.Get(fmt.Sprintf("%s/StorageMetrics", folder.endpoint), folder.config)
+	if err != nil {
+		return nil, err
+	}
+	res := &StorageMetricsInfo{}
+	if err := json.Unmarshal(NormalizeODataItem(data), res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
 
 func ensureFolder(web *Web, serverRelativeURL string, currentRelativeURL string) ([]byte, error) {
 	headers := map[string]string{}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StorageReportOptions provides optional settings for StorageReport method
+type StorageReportOptions struct {
+	Top         int  // number of the largest folders and files to report, 10 by default
+	Versions    bool // include previous versions size, file versions are requested for each file
+	Concurrency int  // concurrent folder content and file versions requests, 4 by default
+}
+
+// StorageEntry describes folder or file storage usage
+type StorageEntry struct {
+	Path              string    // slash separated path relative to the report root, empty for the root
+	ServerRelativeURL string    // folder or file server relative URL
+	IsDir             bool      // folder flag
+	Size              int64     // current versions size, for folders it's the whole folder tree size
+	VersionsSize      int64     // previous versions size, when StorageReportOptions.Versions is on
+	FileCount         int       // files in the folder tree, 1 for a file
+	LastModified      time.Time // the latest modification in the folder tree
+}
+
+// TotalSize gets size including versions
+func (entry *StorageEntry) TotalSize() int64 {
+	return entry.Size + entry.VersionsSize
+}
+
+// StorageReport describes folder tree storage usage
+type StorageReport struct {
+	Root       *StorageEntry   // the folder totals
+	Folders    []*StorageEntry // all subfolders sorted by total size, the largest first
+	TopFolders []*StorageEntry // the largest subfolders
+	TopFiles   []*StorageEntry // the largest files
+}
+
+// StorageReport walks the folder tree and computes per-folder sizes, optionally including versions storage,
+// and reports the largest folders and files. For a library use its root folder, e.g. `web.GetFolder("Shared Documents")`.
+// The totals of a folder can be requested with a single call with StorageMetrics in SharePoint Online.
+func (folder *Folder) StorageReport(options *StorageReportOptions) (*StorageReport, error) {
+	opts := &StorageReportOptions{}
+	if options != nil {
+		*opts = *options
+	}
+	if opts.Top <= 0 {
+		opts.Top = 10
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	resp, err := NewFolder(folder.client, folder.endpoint, folder.config).Select("ServerRelativeUrl,TimeLastModified").Get()
+	if err != nil {
+		return nil, err
+	}
+	rootInfo := resp.Data()
+	root := &StorageEntry{ServerRelativeURL: rootInfo.ServerRelativeURL, IsDir: true, LastModified: rootInfo.TimeLastModified}
+	folders := map[string]*StorageEntry{"": root}
+	var files []*StorageEntry
+
+	err = folder.Walk(func(entry *WalkEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir {
+			folders[entry.Path] = &StorageEntry{
+				Path:              entry.Path,
+				ServerRelativeURL: entry.Folder.ServerRelativeURL,
+				IsDir:             true,
+				LastModified:      entry.Folder.TimeLastModified,
+			}
+			return nil
+		}
+		files = append(files, &StorageEntry{
+			Path:              entry.Path,
+			ServerRelativeURL: entry.File.ServerRelativeURL,
+			Size:              int64(entry.File.Length),
+			FileCount:         1,
+			LastModified:      entry.File.TimeLastModified,
+		})
+		return nil
+	}, &WalkOptions{Concurrency: opts.Concurrency})
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Versions {
+		if err := folder.versionsSize(files, opts.Concurrency); err != nil {
+			return nil, err
+		}
+	}
+
+	// File sizes are summed up to all the parent folders
+	for _, file := range files {
+		for p := path.Dir(file.Path); ; p = path.Dir(p) {
+			if p == "." {
+				p = ""
+			}
+			if parent, ok := folders[p]; ok {
+				parent.Size += file.Size
+				parent.VersionsSize += file.VersionsSize
+				parent.FileCount++
+				if file.LastModified.After(parent.LastModified) {
+					parent.LastModified = file.LastModified
+				}
+			}
+			if p == "" {
+				break
+			}
+		}
+	}
+
+	report := &StorageReport{Root: root}
+	for p, f := range folders {
+		if p != "" {
+			report.Folders = append(report.Folders, f)
+		}
+	}
+	sortBySize(report.Folders)
+	sortBySize(files)
+	report.TopFolders = topEntries(report.Folders, opts.Top)
+	report.TopFiles = topEntries(files, opts.Top)
+	return report, nil
+}
+
+// versionsSize requests previous versions size of the files concurrently
+func (folder *Folder) versionsSize(files []*StorageEntry, concurrency int) error {
+	web := NewSP(folder.client).Web().Conf(folder.config)
+	sem := make(chan struct{}, concurrency)
+	wg := &sync.WaitGroup{}
+	mu := &sync.Mutex{}
+	var firstErr error
+	for _, file := range files {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(file *StorageEntry) {
+			defer func() { <-sem; wg.Done() }()
+			resp, err := web.GetFile(strings.Replace(file.ServerRelativeURL, "'", "''", -1)).Versions().Select("Size").Get()
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			for _, v := range resp.Data() {
+				file.VersionsSize += int64(v.Data().Size)
+			}
+		}(file)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// sortBySize sorts storage entries by total size, the largest first, entries of the same size by path
+func sortBySize(entries []*StorageEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].TotalSize(), entries[j].TotalSize()
+		if a == b {
+			return entries[i].Path < entries[j].Path
+		}
+		return a > b
+	})
+}
+
+// topEntries gets the first n entries
+func topEntries(entries []*StorageEntry, n int) []*StorageEntry {
+	if len(entries) > n {
+		return entries[:n]
+	}
+	return entries
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestStorageReport(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+	docs := spmock.SitePath + "/Shared Documents/"
+	files := []struct {
+		url  string
+		size int
+	}{
+		{"Reports/2020/big.bin", 100},
+		{"Reports/2020/big.bin", 120}, // the previous version is kept
+		{"Reports/small.txt", 10},
+		{"Archive/a.bin", 50},
+		{"root.txt", 5},
+	}
+	for _, f := range files {
+		if err := srv.AddFile(docs+f.url, bytes.Repeat([]byte("x"), f.size)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for mode := range mockModes {
+		t.Run("StorageMetrics/"+mode, func(t *testing.T) {
+			metrics, err := newMockSP(t, srv, mode).Web().GetFolder("Shared Documents").StorageMetrics()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metrics.TotalFileCount != 4 || metrics.TotalFileStreamSize != 185 || metrics.TotalSize != 285 || metrics.LastModified.IsZero() {
+				t.Errorf("unexpected metrics: %+v", metrics)
+			}
+		})
+	}
+
+	entries := func(entries []*StorageEntry) string {
+		var res []string
+		for _, e := range entries {
+			res = append(res, fmt.Sprintf("%s:%d", e.Path, e.TotalSize()))
+		}
+		return strings.Join(res, " ")
+	}
+
+	t.Run("Versions", func(t *testing.T) {
+		folder := NewSP(srv.Client()).Web().GetFolder("Shared Documents")
+		report, err := folder.StorageReport(&StorageReportOptions{Top: 2, Versions: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Root.Size != 185 || report.Root.VersionsSize != 100 || report.Root.FileCount != 4 {
+			t.Errorf("unexpected totals: %+v", report.Root)
+		}
+		if len(report.Folders) != 3 {
+			t.Errorf("unexpected folders: %s", entries(report.Folders))
+		}
+		if actual := entries(report.TopFolders); actual != "Reports:230 Reports/2020:220" {
+			t.Errorf("unexpected top folders: %s", actual)
+		}
+		if actual := entries(report.TopFiles); actual != "Reports/2020/big.bin:220 Archive/a.bin:50" {
+			t.Errorf("unexpected top files: %s", actual)
+		}
+	})
+
+	t.Run("CurrentVersions", func(t *testing.T) {
+		folder := NewSP(srv.Client()).Web().GetFolder("Shared Documents/Reports")
+		report, err := folder.StorageReport(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Root.TotalSize() != 130 || report.Root.FileCount != 2 {
+			t.Errorf("unexpected totals: %+v", report.Root)
+		}
+		if actual := entries(report.TopFiles); actual != "2020/big.bin:120 small.txt:10" {
+			t.Errorf("unexpected top files: %s", actual)
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// SkipDir is used as a return value from WalkFunc to skip the folder's content,
+// when returned for a file the rest of the parent folder is skipped. It's the same value as filepath.SkipDir
+var SkipDir = filepath.SkipDir
+
+// WalkEntry describes a folder or a file visited by Folder.Walk
+type WalkEntry struct {
+	Path   string                 // slash separated path relative to the walk root
+	Depth  int                    // nesting level, 1 for the root's children
+	IsDir  bool                   // folder flag
+	Folder *FolderInfo            // folder properties, for folders
+	File   *FileInfo              // file properties, for files
+	Item   map[string]interface{} // list item fields, when WalkOptions.ItemFields are requested
+}
+
+// WalkFunc is called for each folder and file, err is a folder's content request error, entry is the folder then
+type WalkFunc func(entry *WalkEntry, err error) error
+
+// WalkOptions provides optional settings for Folder.Walk method
+type WalkOptions struct {
+	MaxDepth    int                         // maximum nesting level to visit, no limit by default
+	Filter      func(entry *WalkEntry) bool // entries filter, not matching folders are not passed to WalkFunc but are still walked
+	ItemFields  []string                    // list item fields to include, e.g. "Id", "Title", "Editor/Title"
+	Concurrency int                         // concurrent folder content requests, 4 by default
+	Sorted      bool                        // visit folder's content by names, folders go first in server order otherwise
+}
+
+// Walk walks the folder tree calling fn for each folder and file in depth-first order, the root is not passed to fn.
+// Folder contents are requested concurrently ahead of the walk, while fn is called sequentially
+// and a folder's content is visited only after fn returned for the folder, so SkipDir prevents descending.
+func (folder *Folder) Walk(fn WalkFunc, options *WalkOptions) error {
+	opts := &WalkOptions{}
+	if options != nil {
+		*opts = *options
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	w := &walker{fn: fn, options: opts, sem: make(chan struct{}, opts.Concurrency)}
+	defer w.stop()
+	root := &WalkEntry{IsDir: true}
+	err := w.walk(root, w.list(NewFolder(folder.client, folder.endpoint, folder.config)))
+	if err == SkipDir {
+		return nil
+	}
+	return err
+}
+
+// walker folder tree walk state
+type walker struct {
+	fn      WalkFunc
+	options *WalkOptions
+	sem     chan struct{}
+	stopped int32
+}
+
+// folderListing folder content request, canceled requests are not sent
+type folderListing struct {
+	folder   *Folder
+	result   chan error
+	entries  []*WalkEntry
+	canceled int32
+}
+
+// list requests folder's content in background
+func (w *walker) list(folder *Folder) *folderListing {
+	l := &folderListing{folder: folder, result: make(chan error, 1)}
+	go func() {
+		w.sem <- struct{}{}
+		defer func() { <-w.sem }()
+		if atomic.LoadInt32(&l.canceled) == 1 || atomic.LoadInt32(&w.stopped) == 1 {
+			l.result <- nil
+			return
+		}
+		var err error
+		l.entries, err = w.read(folder)
+		l.result <- err
+	}()
+	return l
+}
+
+// walk visits folder's content
+func (w *walker) walk(parent *WalkEntry, l *folderListing) error {
+	if err := <-l.result; err != nil {
+		if err := w.fn(parent, err); err != SkipDir {
+			return err
+		}
+		return nil
+	}
+	for _, e := range l.entries {
+		e.Depth = parent.Depth + 1
+		e.Path = strings.TrimPrefix(parent.Path+"/"+e.Path, "/")
+	}
+
+	// Subfolders contents are requested ahead
+	listings := make([]*folderListing, len(l.entries))
+	if w.options.MaxDepth == 0 || parent.Depth+1 < w.options.MaxDepth {
+		for i, e := range l.entries {
+			if e.IsDir {
+				listings[i] = w.list(l.folder.Folders().GetByName(strings.Replace(e.Folder.Name, "'", "''", -1)))
+			}
+		}
+	}
+	cancel := func(from int, to int) {
+		for _, l := range listings[from:to] {
+			if l != nil {
+				atomic.StoreInt32(&l.canceled, 1)
+			}
+		}
+	}
+
+	for i, e := range l.entries {
+		if w.options.Filter == nil || w.options.Filter(e) {
+			if err := w.fn(e, nil); err != nil {
+				if err == SkipDir && e.IsDir {
+					cancel(i, i+1)
+					continue
+				}
+				cancel(i, len(listings))
+				if err == SkipDir {
+					return nil
+				}
+				return err
+			}
+		}
+		if listings[i] != nil {
+			if err := w.walk(e, listings[i]); err != nil {
+				cancel(i+1, len(listings))
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stop cancels pending folder requests
+func (w *walker) stop() {
+	atomic.StoreInt32(&w.stopped, 1)
+}
+
+// read gets folder's subfolders and files entries
+func (w *walker) read(folder *Folder) ([]*WalkEntry, error) {
+	var entries []*WalkEntry
+	add := func(data []byte, isDir bool) error {
+		e := &WalkEntry{IsDir: isDir}
+		if isDir {
+			folderResp := FolderResp(data)
+			e.Folder = folderResp.Data()
+			e.Path = e.Folder.Name
+		} else {
+			fileResp := FileResp(data)
+			e.File = fileResp.Data()
+			e.Path = e.File.Name
+		}
+		if len(w.options.ItemFields) > 0 {
+			props := &struct {
+				Item map[string]interface{} `json:"ListItemAllFields"`
+			}{}
+			if err := json.Unmarshal(data, props); err != nil {
+				return err
+			}
+			if props.Item != nil {
+				delete(props.Item, "__metadata")
+				e.Item = normalizeMultiLookupsMap(props.Item)
+			}
+		}
+		entries = append(entries, e)
+		return nil
+	}
+
+	folders := folder.Folders()
+	w.query(folders.modifiers, "Name,ServerRelativeUrl,UniqueId,ItemCount,Exists,TimeCreated,TimeLastModified")
+	err := folders.Iterator().ForEach(func(data []byte) error { return add(data, true) })
+	if err != nil {
+		return nil, err
+	}
+	files := folder.Files()
+	w.query(files.modifiers, "Name,ServerRelativeUrl,UniqueId,Length,ETag,Exists,TimeCreated,TimeLastModified,"+
+		"MajorVersion,MinorVersion,UIVersionLabel,CheckOutType,Title")
+	if err := files.Iterator().ForEach(func(data []byte) error { return add(data, false) }); err != nil {
+		return nil, err
+	}
+
+	if w.options.Sorted {
+		sort.SliceStable(entries, func(i, j int) bool {
+			a, b := strings.ToLower(entries[i].Path), strings.ToLower(entries[j].Path)
+			if a == b {
+				return entries[i].Path < entries[j].Path
+			}
+			return a < b
+		})
+	}
+	return entries, nil
+}
+
+// query selects entry properties and item fields when item fields are requested
+func (w *walker) query(modifiers *ODataMods, props string) {
+	if len(w.options.ItemFields) == 0 {
+		return
+	}
+	selects := []string{props, "ListItemAllFields/Id"}
+	expands := []string{"ListItemAllFields"}
+	for _, field := range w.options.ItemFields {
+		selects = append(selects, "ListItemAllFields/"+field)
+		if parts := strings.SplitN(field, "/", 2); len(parts) == 2 {
+			expands = append(expands, "ListItemAllFields/"+parts[0])
+		}
+	}
+	modifiers.AddSelect(strings.Join(selects, ","))
+	modifiers.AddExpand(strings.Join(expands, ","))
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestFolderWalk(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	for _, fileURL := range []string{"Z/z.txt", "A/B/C/c1.txt", "A/B/b1.txt", "A/a1.txt", "root.txt"} {
+		if err := srv.AddFile(spmock.SitePath+"/Shared Documents/"+fileURL, []byte(fileURL)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sp := NewSP(srv.Client())
+	root := sp.Web().GetFolder("Shared Documents")
+
+	walk := func(t *testing.T, options *WalkOptions, fn func(entry *WalkEntry) error) []string {
+		var paths []string
+		err := root.Walk(func(entry *WalkEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			paths = append(paths, entry.Path)
+			if fn != nil {
+				return fn(entry)
+			}
+			return nil
+		}, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return paths
+	}
+
+	t.Run("Sorted", func(t *testing.T) {
+		paths := walk(t, &WalkOptions{Sorted: true, Concurrency: 2}, nil)
+		expected := "[A A/a1.txt A/B A/B/b1.txt A/B/C A/B/C/c1.txt root.txt Z Z/z.txt]"
+		if fmt.Sprint(paths) != expected {
+			t.Errorf("unexpected walk order: %v", paths)
+		}
+	})
+
+	t.Run("SkipDir", func(t *testing.T) {
+		paths := walk(t, &WalkOptions{Sorted: true}, func(entry *WalkEntry) error {
+			if entry.IsDir && entry.Path == "A/B" {
+				return SkipDir
+			}
+			if entry.Path == "root.txt" {
+				return SkipDir
+			}
+			return nil
+		})
+		if fmt.Sprint(paths) != "[A A/a1.txt A/B root.txt]" {
+			t.Errorf("unexpected skip dir walk: %v", paths)
+		}
+	})
+
+	t.Run("MaxDepthAndFilter", func(t *testing.T) {
+		options := &WalkOptions{
+			Sorted:   true,
+			MaxDepth: 2,
+			Filter:   func(entry *WalkEntry) bool { return !entry.IsDir },
+		}
+		paths := walk(t, options, nil)
+		if fmt.Sprint(paths) != "[A/a1.txt root.txt Z/z.txt]" {
+			t.Errorf("unexpected filtered walk: %v", paths)
+		}
+	})
+
+	t.Run("ItemFields", func(t *testing.T) {
+		walk(t, &WalkOptions{ItemFields: []string{"FileRef", "Editor/Title"}}, func(entry *WalkEntry) error {
+			fileRef := spmock.SitePath + "/Shared Documents/" + entry.Path
+			if entry.Item == nil || entry.Item["FileRef"] != fileRef {
+				t.Errorf("%s item fields are not expanded: %v", entry.Path, entry.Item)
+			}
+			if entry.IsDir != (entry.Folder != nil) || entry.IsDir == (entry.File != nil) {
+				t.Errorf("%s entry info mismatch", entry.Path)
+			}
+			return nil
+		})
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var walkErr error
+		err := sp.Web().GetFolder("Shared Documents/Missing").Walk(func(entry *WalkEntry, err error) error {
+			walkErr = err
+			return nil
+		}, nil)
+		if err != nil || walkErr == nil {
+			t.Errorf("folder error should be passed to WalkFunc: %v, %v", err, walkErr)
+		}
+	})
+}
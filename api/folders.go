@@ -42,6 +42,11 @@ func (folders *Folders) Get() (FoldersResp, error) {
 	return client.Get(folders.ToURL(), folders.config)
 }
 
+// Iterator gets folders collection pages iterator, page size is controlled with Top modifier
+func (folders *Folders) Iterator() *Iterator {
+	return NewSkipIterator(folders.client, folders.ToURL(), folders.config)
+}
+
 // Add created a folder with specified name in this folder
 func (folders *Folders) Add(folderName string) (FolderResp, error) {
 	client := NewHTTPClient(folders.client)
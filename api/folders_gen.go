@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (folders *Folders) Conf(config *RequestConfig) *Folders {
 	folders.config = config
@@ -26,6 +30,12 @@ func (folders *Folders) Filter(oDataFilter string) *Folders {
 	return folders
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (folders *Folders) FilterExpr(oDataFilter odata.Expr) *Folders {
+	folders.modifiers.AddFilter(oDataFilter.String())
+	return folders
+}
+
 // Top adds $top OData modifier
 func (folders *Folders) Top(oDataTop int) *Folders {
 	folders.modifiers.AddTop(oDataTop)
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FormDateLayout default AddValidate/UpdateValidate date layout, matches en-US regional settings
+const FormDateLayout = "1/2/2006 3:04 PM"
+
+// FormValueInfo - AddValidate/UpdateValidate field value result
+type FormValueInfo struct {
+	FieldName    string `json:"FieldName"`
+	FieldValue   string `json:"FieldValue"`
+	HasException bool   `json:"HasException"`
+	ErrorCode    int    `json:"ErrorCode"`
+	ErrorMessage string `json:"ErrorMessage"`
+}
+
+// ValidationError - AddValidate/UpdateValidate error, contains the field values which failed validation
+type ValidationError struct {
+	Fields []*FormValueInfo
+}
+
+// Error returns validation errors of all failed fields
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = fmt.Sprintf("%s: %s", f.FieldName, f.ErrorMessage)
+	}
+	return fmt.Sprintf("validation failed: %s", strings.Join(msgs, "; "))
+}
+
+// FormUserValue formats user or group field form value, logins are claims, e.g. "i:0#.f|membership|user@contoso.com",
+// multiple logins are for UserMulti fields
+func FormUserValue(logins ...string) string {
+	keys := make([]map[string]string, len(logins))
+	for i, login := range logins {
+		keys[i] = map[string]string{"Key": login}
+	}
+	value, _ := json.Marshal(keys)
+	return string(value)
+}
+
+// FormLookupValue formats lookup field form value, multiple IDs are for LookupMulti fields
+func FormLookupValue(ids ...int) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = fmt.Sprintf("%d", id)
+	}
+	return strings.Join(values, ";#;#")
+}
+
+// FormTaxonomyValue formats managed metadata field form value, multiple terms are for TaxonomyFieldTypeMulti fields
+func FormTaxonomyValue(terms ...*FieldTaxonomyValue) string {
+	value := ""
+	for _, term := range terms {
+		value += fmt.Sprintf("%s|%s;", term.Label, term.TermGUID)
+	}
+	return value
+}
+
+// FormURLValue formats hyperlink or picture field form value, commas in the URL are escaped
+func FormURLValue(url string, description string) string {
+	value := strings.Replace(url, ",", ",,", -1)
+	if description != "" {
+		value += ", " + description
+	}
+	return value
+}
+
+// FormDateValue formats date field form value. Dates are parsed with the web's regional settings,
+// the layout should match the web's locale, FormDateLayout (en-US) is used when empty,
+// and the date should be in the web's time zone.
+func FormDateValue(date time.Time, layout string) string {
+	if layout == "" {
+		layout = FormDateLayout
+	}
+	return date.Format(layout)
+}
+
+// FormMultiChoiceValue formats multi choice field form value
+func FormMultiChoiceValue(choices ...string) string {
+	if len(choices) == 0 {
+		return ""
+	}
+	return ";#" + strings.Join(choices, ";#") + ";#"
+}
+
+// parseFormValues parses AddValidate/UpdateValidate response field values
+func parseFormValues(payload []byte) []*FormValueInfo {
+	r := &struct {
+		D     map[string]json.RawMessage `json:"d"`
+		Value []*FormValueInfo           `json:"value"`
+	}{}
+	_ = json.Unmarshal(payload, &r)
+	if r.Value != nil {
+		return r.Value
+	}
+	// Verbose mode wraps results with the method name, e.g. `{"d":{"ValidateUpdateListItem":{"results":[]}}}`
+	for _, raw := range r.D {
+		res := &struct {
+			Results []*FormValueInfo `json:"results"`
+		}{}
+		if err := json.Unmarshal(raw, &res); err == nil && res.Results != nil {
+			return res.Results
+		}
+	}
+	return nil
+}
+
+// formValuesError gets validation error for field values with exceptions
+func formValuesError(values []*FormValueInfo) error {
+	var failed []*FormValueInfo
+	for _, v := range values {
+		if v.HasException {
+			failed = append(failed, v)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: failed}
+}
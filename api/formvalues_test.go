@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFormValues(t *testing.T) {
+	cases := []struct {
+		actual   string
+		expected string
+	}{
+		{FormUserValue("i:0#.f|membership|user@contoso.com"), `[{"Key":"i:0#.f|membership|user@contoso.com"}]`},
+		{FormUserValue("a", "b"), `[{"Key":"a"},{"Key":"b"}]`},
+		{FormLookupValue(1), "1"},
+		{FormLookupValue(1, 2), "1;#;#2"},
+		{FormTaxonomyValue(&FieldTaxonomyValue{Label: "Term", TermGUID: "9e9f7c32-5a3e-4d5e-a3c6-e5d4f6b8d7a1"}), "Term|9e9f7c32-5a3e-4d5e-a3c6-e5d4f6b8d7a1;"},
+		{FormURLValue("https://contoso.com/a,b", "Contoso"), "https://contoso.com/a,,b, Contoso"},
+		{FormURLValue("https://contoso.com", ""), "https://contoso.com"},
+		{FormDateValue(time.Date(2020, 3, 5, 14, 30, 0, 0, time.UTC), ""), "3/5/2020 2:30 PM"},
+		{FormDateValue(time.Date(2020, 3, 5, 14, 30, 0, 0, time.UTC), "02.01.2006 15:04"), "05.03.2020 14:30"},
+		{FormMultiChoiceValue("One", "Two"), ";#One;#Two;#"},
+		{FormMultiChoiceValue(), ""},
+	}
+	for _, c := range cases {
+		if c.actual != c.expected {
+			t.Errorf("expected %s, got %s", c.expected, c.actual)
+		}
+	}
+}
+
+func TestFormValuesResp(t *testing.T) {
+	t.Run("Verbose", func(t *testing.T) {
+		resp := AddValidateResp(`{"d":{"AddValidateUpdateItemUsingPath":{"__metadata":{"type":"Collection(SP.ListItemFormUpdateValue)"},"results":[
+			{"ErrorCode":0,"ErrorMessage":null,"FieldName":"Title","FieldValue":"New item","HasException":false},
+			{"ErrorCode":0,"ErrorMessage":null,"FieldName":"Id","FieldValue":"42","HasException":false}
+		]}}}`)
+		if err := formValuesError(resp.Values()); err != nil {
+			t.Error(err)
+		}
+		if resp.ID() != 42 {
+			t.Errorf("expected ID 42, got %d", resp.ID())
+		}
+	})
+
+	t.Run("ValidationError", func(t *testing.T) {
+		resp := UpdateValidateResp(`{"value":[
+			{"ErrorCode":-2146232832,"ErrorMessage":"You must specify a value for this required field.","FieldName":"Title","FieldValue":"","HasException":true},
+			{"ErrorCode":0,"ErrorMessage":null,"FieldName":"Amount","FieldValue":"10","HasException":false}
+		]}`)
+		err := formValuesError(resp.Values())
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+		if len(validationErr.Fields) != 1 || validationErr.Fields[0].FieldName != "Title" || validationErr.Fields[0].ErrorCode != -2146232832 {
+			t.Errorf("unexpected failed fields: %+v", validationErr.Fields)
+		}
+		if err.Error() != "validation failed: Title: You must specify a value for this required field." {
+			t.Errorf("unexpected error message: %s", err)
+		}
+		if (&AddValidateResp{}).ID() != 0 {
+			t.Error("empty response should have zero ID")
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/koltyakov/gosip"
 	"github.com/koltyakov/gosip/csom"
+	"github.com/koltyakov/gosip/odata"
 )
 
 //go:generate ggen -ent Group -conf -mods Select,Expand -helpers Data,Normalized
@@ -130,7 +131,7 @@ func (group *Group) SetOwner(ownerID int) error {
 	}
 
 	pType := "group"
-	pData, err := site.RootWeb().UserInfoList().Items().Expand("ContentType").Filter(fmt.Sprintf("Id eq %d", ownerID)).Get()
+	pData, err := site.RootWeb().UserInfoList().Items().Expand("ContentType").FilterExpr(odata.Field("Id").Eq(ownerID)).Get()
 	if err != nil {
 		return nil
 	}
@@ -146,8 +147,8 @@ func (group *Group) SetOwner(ownerID int) error {
 	b := csom.NewBuilder()
 	wo, _ := b.AddObject(csom.NewObjectProperty("Web"), nil)
 	sg, _ := b.AddObject(csom.NewObjectProperty("SiteGroups"), wo)
-	gr, _ := b.AddObject(csom.NewObjectMethod("GetById", []string{fmt.Sprintf(`<Parameter Type="Number">%d</Parameter>`, cg.Data().ID)}), sg)
-	owner := csom.NewObjectMethod("GetById", []string{fmt.Sprintf(`<Parameter Type="Number">%d</Parameter>`, ownerID)})
+	gr, _ := b.AddObject(csom.NewObjectMethodWithParams("GetById", csom.NewParamInt32(int32(cg.Data().ID))), sg)
+	owner := csom.NewObjectMethodWithParams("GetById", csom.NewParamInt32(int32(ownerID)))
 
 	if pType == "group" {
 		owner, _ = b.AddObject(owner, sg)
@@ -155,13 +156,7 @@ func (group *Group) SetOwner(ownerID int) error {
 		su, _ := b.AddObject(csom.NewObjectProperty("SiteUsers"), wo)
 		owner, _ = b.AddObject(owner, su)
 	}
-	_, _ = b.Compile() // force object nodes IDs calc
-
-	b.AddAction(csom.NewAction(fmt.Sprintf(`
-		<SetProperty Id="{{.ID}}" ObjectPathId="{{.ObjectID}}" Name="Owner">
-			<Parameter ObjectPathId="%d" />
-		</SetProperty>
-	`, owner.GetID())), gr)
+	b.AddAction(csom.NewSetPropertyWithParam("Owner", csom.NewParamObjectPath(owner)), gr)
 	b.AddAction(csom.NewAction(`<Method Name="Update" Id="{{.ID}}" ObjectPathId="{{.ObjectID}}" />`), gr)
 
 	csomPkg, err := b.Compile()
@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (groups *Groups) Conf(config *RequestConfig) *Groups {
 	groups.config = config
@@ -26,6 +30,12 @@ func (groups *Groups) Filter(oDataFilter string) *Groups {
 	return groups
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (groups *Groups) FilterExpr(oDataFilter odata.Expr) *Groups {
+	groups.modifiers.AddFilter(oDataFilter.String())
+	return groups
+}
+
 // Top adds $top OData modifier
 func (groups *Groups) Top(oDataTop int) *Groups {
 	groups.modifiers.AddTop(oDataTop)
@@ -3,7 +3,6 @@ package api
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -11,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/koltyakov/gosip"
+	"github.com/koltyakov/gosip/csom"
 )
 
 // HTTPClient HTTP methods helper
@@ -234,41 +234,10 @@ func (client *HTTPClient) ProcessQuery(endpoint string, body io.Reader, conf *Re
 	// https://stackoverflow.com/questions/31398044/got-error-invalid-character-ï-looking-for-beginning-of-value-from-json-unmar
 	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // removing BOM
 
-	var arrRes []interface{}
-	if err := json.Unmarshal(data, &arrRes); err != nil {
-		return data, err
-	}
-
-	res := &struct {
-		SchemaVersion  string `json:"SchemaVersion"`
-		LibraryVersion string `json:"LibraryVersion"`
-		ErrorInfo      *struct {
-			ErrorMessage  string `json:"ErrorMessage"`
-			ErrorValue    string `json:"ErrorValue"`
-			ErrorCode     int    `json:"ErrorCode"`
-			ErrorTypeName string `json:"ErrorTypeName"`
-		} `json:"ErrorInfo"`
-		TraceCorrelationID string `json:"TraceCorrelationId"`
-	}{}
-
-	arrEl1, err := json.Marshal(arrRes[0])
+	res, err := csom.ParseResponse(data)
 	if err != nil {
 		return data, err
 	}
 
-	if err := json.Unmarshal(arrEl1, &res); err != nil {
-		return data, err
-	}
-
-	if res.ErrorInfo != nil {
-		return data, fmt.Errorf(
-			"%s (Code: %d, %s, Correlation ID: %s)",
-			res.ErrorInfo.ErrorMessage,
-			res.ErrorInfo.ErrorCode,
-			res.ErrorInfo.ErrorTypeName,
-			res.TraceCorrelationID,
-		)
-	}
-
-	return data, nil
+	return data, res.Err()
 }
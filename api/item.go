@@ -98,9 +98,14 @@ type ValidateUpdateOptions struct {
 	CheckInComment    string
 }
 
+// UpdateValidateResp - update validate response type with helper processor methods
+type UpdateValidateResp []byte
+
 // UpdateValidate updates an item in this list using ValidateUpdateListItem method.
-// formValues fingerprints https://github.com/koltyakov/sp-sig-20180705-demo/blob/master/src/03-pnp/FieldTypes.md#field-data-types-fingerprints-sample
-func (item *Item) UpdateValidate(formValues map[string]string, options *ValidateUpdateOptions) ([]byte, error) {
+// formValues fingerprints https://github.com/koltyakov/sp-sig-20180705-demo/blob/master/src/03-pnp/FieldTypes.md#field-data-types-fingerprints-sample,
+// use Form*Value helpers to format complex field types values.
+// When some of the fields fail validation, the response is returned along with *ValidationError.
+func (item *Item) UpdateValidate(formValues map[string]string, options *ValidateUpdateOptions) (UpdateValidateResp, error) {
 	endpoint := fmt.Sprintf("%s/ValidateUpdateListItem", item.endpoint)
 	client := NewHTTPClient(item.client)
 	type formValue struct {
@@ -120,7 +125,16 @@ func (item *Item) UpdateValidate(formValues map[string]string, options *Validate
 		payload["checkInComment"] = options.CheckInComment
 	}
 	body, _ := json.Marshal(payload)
-	return client.Post(endpoint, bytes.NewBuffer(body), item.config)
+	resp, err := client.Post(endpoint, bytes.NewBuffer(body), item.config)
+	if err != nil {
+		return resp, err
+	}
+	return resp, formValuesError(parseFormValues(resp))
+}
+
+// Values gets field values validation results
+func (uvResp *UpdateValidateResp) Values() []*FormValueInfo {
+	return parseFormValues(*uvResp)
 }
 
 // Roles gets Roles API instance queryable collection for this Item
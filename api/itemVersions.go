@@ -0,0 +1,273 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/koltyakov/gosip"
+)
+
+//go:generate ggen -ent ItemVersions -item ItemVersion -conf -coll -mods Select,Expand,Filter,Top,OrderBy -helpers Data,Normalized
+//go:generate ggen -ent ItemVersion -conf -mods Select,Expand -helpers Data,Normalized,ToMap
+
+// ItemVersions represent SharePoint List Item Versions API queryable collection struct
+// Always use NewItemVersions constructor instead of &ItemVersions{}
+type ItemVersions struct {
+	client    *gosip.SPClient
+	config    *RequestConfig
+	endpoint  string
+	modifiers *ODataMods
+}
+
+// ItemVersion represents SharePoint List Item Version API queryable object struct
+// Always use NewItemVersion constructor instead of &ItemVersion{}
+type ItemVersion struct {
+	client    *gosip.SPClient
+	config    *RequestConfig
+	endpoint  string
+	modifiers *ODataMods
+}
+
+// ItemVersionInfo - item version API response payload structure,
+// version's field values are available with ToMap or Unmarshal helpers
+type ItemVersionInfo struct {
+	VersionID        int       `json:"VersionId"`
+	VersionLabel     string    `json:"VersionLabel"`
+	IsCurrentVersion bool      `json:"IsCurrentVersion"`
+	Created          time.Time `json:"Created"`
+	CheckInComment   string    `json:"OData__CheckinComment"`
+	CreatedBy        *UserInfo `json:"CreatedBy"` // available when expanded
+}
+
+// ItemVersionsResp - item versions response type with helper processor methods
+type ItemVersionsResp []byte
+
+// ItemVersionResp - item version response type with helper processor methods
+type ItemVersionResp []byte
+
+// NewItemVersions - ItemVersions struct constructor function
+func NewItemVersions(client *gosip.SPClient, endpoint string, config *RequestConfig) *ItemVersions {
+	return &ItemVersions{
+		client:    client,
+		endpoint:  endpoint,
+		config:    config,
+		modifiers: NewODataMods(),
+	}
+}
+
+// NewItemVersion - ItemVersion struct constructor function
+func NewItemVersion(client *gosip.SPClient, endpoint string, config *RequestConfig) *ItemVersion {
+	return &ItemVersion{
+		client:    client,
+		endpoint:  endpoint,
+		config:    config,
+		modifiers: NewODataMods(),
+	}
+}
+
+// Versions gets item's versions API instance queryable collection
+func (item *Item) Versions() *ItemVersions {
+	return NewItemVersions(
+		item.client,
+		fmt.Sprintf("%s/Versions", item.endpoint),
+		item.config,
+	)
+}
+
+// ToURL gets endpoint with modificators raw URL
+func (itemVersions *ItemVersions) ToURL() string {
+	return toURL(itemVersions.endpoint, itemVersions.modifiers)
+}
+
+// Get gets item versions collection, the current version is included
+func (itemVersions *ItemVersions) Get() (ItemVersionsResp, error) {
+	client := NewHTTPClient(itemVersions.client)
+	return client.Get(itemVersions.ToURL(), itemVersions.config)
+}
+
+// GetByID gets item version by its ID, e.g. 512 for version 1.0
+func (itemVersions *ItemVersions) GetByID(versionID int) *ItemVersion {
+	return NewItemVersion(
+		itemVersions.client,
+		fmt.Sprintf("%s(%d)", itemVersions.endpoint, versionID),
+		itemVersions.config,
+	)
+}
+
+// GetByLabel gets item version by its label, e.g. "1.0"
+func (itemVersions *ItemVersions) GetByLabel(label string) *ItemVersion {
+	major, minor, err := parseVersionLabel(label)
+	if err != nil {
+		// the request fails with the not existing version
+		major, minor = 0, 0
+	}
+	return itemVersions.GetByID(major*512 + minor)
+}
+
+// Trim deletes old versions keeping the most recent keepMajor major and keepMinor minor versions,
+// negative values keep all the versions of the kind, the current version is never deleted.
+// Deleted versions IDs are returned.
+func (itemVersions *ItemVersions) Trim(keepMajor int, keepMinor int) ([]int, error) {
+	versions := NewItemVersions(itemVersions.client, itemVersions.endpoint, itemVersions.config)
+	resp, err := versions.Select("VersionId,VersionLabel,IsCurrentVersion").Get()
+	if err != nil {
+		return nil, err
+	}
+	var refs []*versionRef
+	for _, v := range resp.Data() {
+		data := v.Data()
+		if data.IsCurrentVersion {
+			continue
+		}
+		refs = append(refs, &versionRef{ID: data.VersionID, Label: data.VersionLabel})
+	}
+	var deleted []int
+	for _, id := range versionsToTrim(refs, keepMajor, keepMinor) {
+		if err := itemVersions.GetByID(id).Delete(); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, id)
+	}
+	return deleted, nil
+}
+
+// DeleteAll deletes all the previous versions, the current version is kept.
+// Deleted versions IDs are returned.
+func (itemVersions *ItemVersions) DeleteAll() ([]int, error) {
+	return itemVersions.Trim(0, 0)
+}
+
+// ToURL gets endpoint with modificators raw URL
+func (itemVersion *ItemVersion) ToURL() string {
+	return toURL(itemVersion.endpoint, itemVersion.modifiers)
+}
+
+// Get gets item version with the field values as of this version
+func (itemVersion *ItemVersion) Get() (ItemVersionResp, error) {
+	client := NewHTTPClient(itemVersion.client)
+	return client.Get(itemVersion.ToURL(), itemVersion.config)
+}
+
+// Delete deletes this version, the current version can't be deleted
+func (itemVersion *ItemVersion) Delete() error {
+	client := NewHTTPClient(itemVersion.client)
+	endpoint := fmt.Sprintf("%s/DeleteObject()", itemVersion.endpoint)
+	_, err := client.Post(endpoint, nil, itemVersion.config)
+	return err
+}
+
+// Restore restores item's field values as of this version creating a new version.
+// Only writable fields are restored, multi-value taxonomy fields are skipped as not supported by REST payloads.
+func (itemVersion *ItemVersion) Restore() (ItemResp, error) {
+	data, err := NewItemVersion(itemVersion.client, itemVersion.endpoint, itemVersion.config).Get()
+	if err != nil {
+		return nil, err
+	}
+	values := data.ToMap()
+
+	item := NewItem(itemVersion.client, getPriorEndpoint(itemVersion.endpoint, "/Versions"), itemVersion.config)
+	fieldsResp, err := item.ParentList().Fields().
+		Select("InternalName,EntityPropertyName,TypeAsString").
+		Filter("ReadOnlyField eq false and Hidden eq false").
+		Get()
+	if err != nil {
+		return nil, err
+	}
+	var fields []*FieldInfo
+	for _, f := range fieldsResp.Data() {
+		fields = append(fields, f.Data())
+	}
+
+	body, err := json.Marshal(versionRestorePayload(values, fields))
+	if err != nil {
+		return nil, err
+	}
+	return item.Update(body)
+}
+
+/* Response helpers */
+
+// Unmarshal decodes version's field values to a struct with `sp:"InternalName"` tags
+func (itemVersionResp *ItemVersionResp) Unmarshal(v interface{}) error {
+	return UnmarshalItem(*itemVersionResp, v)
+}
+
+// versionRestoreSkipTypes fields types which can't be restored
+var versionRestoreSkipTypes = map[string]bool{
+	"Computed": true, "Calculated": true, "Attachments": true, "File": true,
+	"ContentTypeId": true, "TaxonomyFieldTypeMulti": true,
+}
+
+// versionRestorePayload builds item update payload from version's field values
+func versionRestorePayload(values map[string]interface{}, fields []*FieldInfo) map[string]interface{} {
+	values = normalizeMultiLookupsMap(values)
+	payload := map[string]interface{}{}
+	for _, f := range fields {
+		if versionRestoreSkipTypes[f.TypeAsString] {
+			continue
+		}
+		key := f.EntityPropertyName
+		if key == "" {
+			key = f.InternalName
+		}
+		value, ok := values[key]
+		if !ok {
+			if value, ok = values[f.InternalName]; !ok {
+				continue
+			}
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			switch {
+			case v["LookupId"] != nil:
+				id, _ := toFloat(v["LookupId"])
+				payload[key+"Id"] = nil
+				if id > 0 {
+					payload[key+"Id"] = int(id)
+				}
+			case v["Url"] != nil:
+				payload[key] = map[string]interface{}{
+					"__metadata":  map[string]string{"type": "SP.FieldUrlValue"},
+					"Url":         v["Url"],
+					"Description": v["Description"],
+				}
+			case v["TermGuid"] != nil:
+				payload[key] = map[string]interface{}{
+					"__metadata": map[string]string{"type": "SP.Taxonomy.TaxonomyFieldValue"},
+					"Label":      v["Label"],
+					"TermGuid":   v["TermGuid"],
+					"WssId":      v["WssId"],
+				}
+			}
+		case []interface{}:
+			results := []interface{}{}
+			collectionType := "Collection(Edm.String)"
+			isLookup := false
+			for _, el := range v {
+				if m, ok := el.(map[string]interface{}); ok {
+					id, _ := toFloat(m["LookupId"])
+					results = append(results, int(id))
+					collectionType = "Collection(Edm.Int32)"
+					isLookup = true
+					continue
+				}
+				results = append(results, el)
+			}
+			if isLookup || f.TypeAsString == "LookupMulti" || f.TypeAsString == "UserMulti" {
+				collectionType = "Collection(Edm.Int32)"
+				key += "Id"
+			}
+			payload[key] = map[string]interface{}{
+				"__metadata": map[string]string{"type": collectionType},
+				"results":    results,
+			}
+		default:
+			if value == nil && (f.TypeAsString == "Lookup" || f.TypeAsString == "User") {
+				key += "Id"
+			}
+			payload[key] = value
+		}
+	}
+	return payload
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestVersionRestorePayload(t *testing.T) {
+	version := ItemVersionResp(`{"d":{
+		"__metadata":{"type":"SP.ListItemVersion"},
+		"VersionId":512,"VersionLabel":"1.0","IsCurrentVersion":false,
+		"Title":"Old title","Amount":10.5,"Notes":null,
+		"Category":{"__metadata":{"type":"SP.FieldLookupValue"},"LookupId":2,"LookupValue":"Cat"},
+		"Manager":{"__metadata":{"type":"SP.FieldUserValue"},"LookupId":5,"LookupValue":"John","Email":"john@contoso.com"},
+		"Reviewer":null,
+		"Tags":{"__metadata":{"type":"Collection(SP.FieldLookupValue)"},"results":[{"LookupId":3,"LookupValue":"A"}]},
+		"Choices":{"__metadata":{"type":"Collection(Edm.String)"},"results":["One","Two"]},
+		"Link":{"__metadata":{"type":"SP.FieldUrlValue"},"Url":"https://contoso.com","Description":"Contoso"},
+		"OData__Hidden":"hidden","Calc":"42"
+	}}`)
+	if version.Data().VersionLabel != "1.0" {
+		t.Errorf("unexpected version: %+v", version.Data())
+	}
+
+	fields := []*FieldInfo{
+		{InternalName: "Title", EntityPropertyName: "Title", TypeAsString: "Text"},
+		{InternalName: "Amount", EntityPropertyName: "Amount", TypeAsString: "Number"},
+		{InternalName: "Notes", EntityPropertyName: "Notes", TypeAsString: "Note"},
+		{InternalName: "Category", EntityPropertyName: "Category", TypeAsString: "Lookup"},
+		{InternalName: "Manager", EntityPropertyName: "Manager", TypeAsString: "User"},
+		{InternalName: "Reviewer", EntityPropertyName: "Reviewer", TypeAsString: "User"},
+		{InternalName: "Tags", EntityPropertyName: "Tags", TypeAsString: "LookupMulti"},
+		{InternalName: "Choices", EntityPropertyName: "Choices", TypeAsString: "MultiChoice"},
+		{InternalName: "Link", EntityPropertyName: "Link", TypeAsString: "URL"},
+		{InternalName: "_Hidden", EntityPropertyName: "OData__Hidden", TypeAsString: "Text"},
+		{InternalName: "Calc", EntityPropertyName: "Calc", TypeAsString: "Calculated"},
+		{InternalName: "Missing", EntityPropertyName: "Missing", TypeAsString: "Text"},
+	}
+	payload, _ := json.Marshal(versionRestorePayload(version.ToMap(), fields))
+
+	expected := `{` +
+		`"Amount":10.5,` +
+		`"CategoryId":2,` +
+		`"Choices":{"__metadata":{"type":"Collection(Edm.String)"},"results":["One","Two"]},` +
+		`"Link":{"Description":"Contoso","Url":"https://contoso.com","__metadata":{"type":"SP.FieldUrlValue"}},` +
+		`"ManagerId":5,` +
+		`"Notes":null,` +
+		`"OData__Hidden":"hidden",` +
+		`"ReviewerId":null,` +
+		`"TagsId":{"__metadata":{"type":"Collection(Edm.Int32)"},"results":[3]},` +
+		`"Title":"Old title"` +
+		`}`
+	if string(payload) != expected {
+		t.Errorf("unexpected payload:\n%s\nexpected:\n%s", payload, expected)
+	}
+}
+
+func TestItemVersionsDeleteAll(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	for mode := range mockModes {
+		t.Run(mode, func(t *testing.T) {
+			listTitle := "Versions " + mode
+			if _, err := srv.AddList(listTitle, 100); err != nil {
+				t.Fatal(err)
+			}
+			items := newMockSP(t, srv, mode).Web().Lists().GetByTitle(listTitle).Items()
+			itemResp, err := items.Add([]byte(`{"Title":"1.0"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			item := items.GetByID(itemResp.Data().ID)
+			for _, title := range []string{"2.0", "3.0"} {
+				if _, err := item.Update([]byte(fmt.Sprintf(`{"Title":"%s"}`, title))); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			deleted, err := item.Versions().DeleteAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(deleted) != "[1024 512]" {
+				t.Errorf("unexpected deleted versions: %v", deleted)
+			}
+			versions, err := item.Versions().Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(versions.Data()) != 1 || !versions.Data()[0].Data().IsCurrentVersion || versions.Data()[0].Data().VersionLabel != "3.0" {
+				t.Errorf("only the current version is expected to be kept: %s", versions)
+			}
+
+			if deleted, err := item.Versions().DeleteAll(); err != nil || len(deleted) != 0 {
+				t.Errorf("no versions are expected to be deleted: %v, %v", deleted, err)
+			}
+		})
+	}
+}
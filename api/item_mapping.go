@@ -0,0 +1,617 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FieldLookupValue - lookup field value
+type FieldLookupValue struct {
+	ID    int    // lookup item ID
+	Value string // lookup show field value, `Title` by default or the one provided with `show=` tag option
+}
+
+// FieldUserValue - user or group field value
+type FieldUserValue struct {
+	ID        int
+	Title     string
+	Email     string
+	LoginName string
+}
+
+// FieldURLValue - hyperlink or picture field value
+type FieldURLValue struct {
+	URL         string
+	Description string
+}
+
+// FieldTaxonomyValue - managed metadata field value
+type FieldTaxonomyValue struct {
+	Label    string
+	TermGUID string
+	WssID    int
+}
+
+// itemField struct field mapping defined with `sp:"InternalName,options"` tag
+// Supported options:
+//   - omitempty: skips zero values in Add/Update payloads
+//   - readonly: never sent in Add/Update payloads
+//   - show=Field: lookup field's projected field, `Title` by default
+type itemField struct {
+	index     []int
+	name      string
+	show      string
+	omitEmpty bool
+	readOnly  bool
+	typ       reflect.Type
+}
+
+// readOnlyItemFields system fields which can't be set via Add/Update
+var readOnlyItemFields = map[string]bool{
+	"Id": true, "ID": true, "GUID": true, "Created": true, "Modified": true, "Author": true, "Editor": true,
+	"FileRef": true, "FileDirRef": true, "FSObjType": true, "FileSystemObjectType": true,
+	"ContentTypeId": true, "Attachments": true,
+}
+
+var (
+	timeType         = reflect.TypeOf(time.Time{})
+	lookupType       = reflect.TypeOf(FieldLookupValue{})
+	userType         = reflect.TypeOf(FieldUserValue{})
+	urlValueType     = reflect.TypeOf(FieldURLValue{})
+	taxonomyType     = reflect.TypeOf(FieldTaxonomyValue{})
+	userProjections  = []string{"Id", "Title", "EMail", "Name"}
+	itemDateFormats  = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
+	itemFieldsByType sync.Map // reflect.Type -> []*itemField
+)
+
+// UnmarshalItem decodes item payload of any OData mode to a struct with `sp:"InternalName"` tags
+func UnmarshalItem(payload []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("can't unmarshal item to %T, a pointer to struct is expected", v)
+	}
+	props := map[string]interface{}{}
+	if err := json.Unmarshal(NormalizeODataItem(payload), &props); err != nil {
+		return err
+	}
+	return decodeItem(props, rv.Elem())
+}
+
+// UnmarshalItems decodes items collection payload of any OData mode to a pointer to slice of tagged structs
+func UnmarshalItems(payload []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("can't unmarshal items to %T, a pointer to slice is expected", v)
+	}
+	items, _ := normalizeODataCollection(payload)
+	slice := reflect.MakeSlice(rv.Elem().Type(), 0, len(items))
+	elemType := rv.Elem().Type().Elem()
+	for _, item := range items {
+		props := map[string]interface{}{}
+		if err := json.Unmarshal(item, &props); err != nil {
+			return err
+		}
+		props = normalizeMultiLookupsMap(props)
+		elem := reflect.New(derefType(elemType)).Elem()
+		if elem.Kind() != reflect.Struct {
+			return fmt.Errorf("can't unmarshal items to %T, a slice of structs is expected", v)
+		}
+		if err := decodeItem(props, elem); err != nil {
+			return err
+		}
+		if elemType.Kind() == reflect.Ptr {
+			elem = elem.Addr()
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	rv.Elem().Set(slice)
+	return nil
+}
+
+// MarshalItem converts a struct with `sp:"InternalName"` tags to item Add/Update payload,
+// `__metadata` is added when entityType is provided, otherwise Items.Add and Item.Update resolve it
+func MarshalItem(v interface{}, entityType string) ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can't marshal item from %T, a struct is expected", v)
+	}
+	fields, err := getItemFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]interface{}{}
+	if entityType != "" {
+		payload["__metadata"] = map[string]string{"type": entityType}
+	}
+	for _, f := range fields {
+		if f.readOnly || readOnlyItemFields[f.name] {
+			continue
+		}
+		fv := rv.FieldByIndex(f.index)
+		if f.omitEmpty && isZeroValue(fv) {
+			continue
+		}
+		key, value, err := encodeItemValue(f, fv)
+		if err != nil {
+			return nil, err
+		}
+		payload[key] = value
+	}
+	return json.Marshal(payload)
+}
+
+// ItemSelect derives $select and $expand values from the struct's `sp` tags
+func ItemSelect(v interface{}) (string, string) {
+	t := derefType(reflect.TypeOf(v))
+	if t != nil && t.Kind() == reflect.Slice {
+		t = derefType(t.Elem())
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", ""
+	}
+	fields, err := getItemFields(t)
+	if err != nil {
+		return "", ""
+	}
+	var selects, expands []string
+	for _, f := range fields {
+		switch derefType(sliceElem(f.typ)) {
+		case lookupType:
+			selects = append(selects, f.name+"/Id", f.name+"/"+f.show)
+			expands = append(expands, f.name)
+		case userType:
+			for _, p := range userProjections {
+				selects = append(selects, f.name+"/"+p)
+			}
+			expands = append(expands, f.name)
+		default:
+			selects = append(selects, f.name)
+		}
+	}
+	return strings.Join(selects, ","), strings.Join(expands, ",")
+}
+
+// SelectFor adds $select and $expand OData modifiers derived from the struct's `sp` tags
+func (items *Items) SelectFor(v interface{}) *Items {
+	sel, exp := ItemSelect(v)
+	if sel != "" {
+		items.modifiers.AddSelect(sel)
+	}
+	if exp != "" {
+		items.modifiers.AddExpand(exp)
+	}
+	return items
+}
+
+// SelectFor adds $select and $expand OData modifiers derived from the struct's `sp` tags
+func (item *Item) SelectFor(v interface{}) *Item {
+	sel, exp := ItemSelect(v)
+	if sel != "" {
+		item.modifiers.AddSelect(sel)
+	}
+	if exp != "" {
+		item.modifiers.AddExpand(exp)
+	}
+	return item
+}
+
+// Unmarshal decodes item response to a struct with `sp:"InternalName"` tags
+func (itemResp *ItemResp) Unmarshal(v interface{}) error {
+	return UnmarshalItem(*itemResp, v)
+}
+
+// Unmarshal decodes items response to a pointer to slice of structs with `sp:"InternalName"` tags
+func (itemsResp *ItemsResp) Unmarshal(v interface{}) error {
+	return UnmarshalItems(*itemsResp, v)
+}
+
+// getItemFields parses and caches struct's tagged fields
+func getItemFields(t reflect.Type) ([]*itemField, error) {
+	if fields, ok := itemFieldsByType.Load(t); ok {
+		return fields.([]*itemField), nil
+	}
+	var fields []*itemField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup("sp")
+		if sf.Anonymous && !tagged && derefType(sf.Type).Kind() == reflect.Struct {
+			embedded, err := getItemFields(derefType(sf.Type))
+			if err != nil {
+				return nil, err
+			}
+			if sf.Type.Kind() == reflect.Ptr {
+				return nil, fmt.Errorf("embedded pointer %s is not supported", sf.Name)
+			}
+			for _, f := range embedded {
+				nested := *f
+				nested.index = append([]int{i}, f.index...)
+				fields = append(fields, &nested)
+			}
+			continue
+		}
+		if !tagged || tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		f := &itemField{index: []int{i}, name: strings.TrimSpace(opts[0]), show: "Title", typ: sf.Type}
+		if f.name == "" {
+			f.name = sf.Name
+		}
+		for _, opt := range opts[1:] {
+			opt = strings.TrimSpace(opt)
+			switch {
+			case opt == "omitempty":
+				f.omitEmpty = true
+			case opt == "readonly":
+				f.readOnly = true
+			case strings.HasPrefix(opt, "show="):
+				f.show = strings.TrimPrefix(opt, "show=")
+			default:
+				return nil, fmt.Errorf("unknown sp tag option %q in %s.%s", opt, t.Name(), sf.Name)
+			}
+		}
+		fields = append(fields, f)
+	}
+	itemFieldsByType.Store(t, fields)
+	return fields, nil
+}
+
+// decodeItem populates struct fields from item properties
+func decodeItem(props map[string]interface{}, rv reflect.Value) error {
+	fields, err := getItemFields(rv.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		raw, _ := itemProp(props, f.name)
+		// Not expanded lookups are only available as IDs
+		if raw == nil {
+			if t := derefType(sliceElem(f.typ)); t == lookupType || t == userType {
+				raw, _ = itemProp(props, f.name+"Id")
+			}
+		}
+		if err := decodeItemValue(raw, rv.FieldByIndex(f.index), f); err != nil {
+			return fmt.Errorf("can't decode %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// itemProp gets item property by field internal name
+func itemProp(props map[string]interface{}, name string) (interface{}, bool) {
+	candidates := []string{name}
+	// Fields starting with underscore are prefixed in REST API
+	if strings.HasPrefix(name, "_") {
+		candidates = append(candidates, "OData_"+name)
+	}
+	for _, key := range candidates {
+		if val, ok := props[key]; ok {
+			return deferredToNil(val), true
+		}
+	}
+	for key, val := range props {
+		if strings.EqualFold(key, name) {
+			return deferredToNil(val), true
+		}
+	}
+	return nil, false
+}
+
+// deferredToNil treats not expanded verbose navigation properties as empty values
+func deferredToNil(val interface{}) interface{} {
+	if m, ok := val.(map[string]interface{}); ok {
+		if _, deferred := m["__deferred"]; deferred {
+			return nil
+		}
+	}
+	return val
+}
+
+func decodeItemValue(raw interface{}, fv reflect.Value, f *itemField) error {
+	if fv.Kind() == reflect.Ptr {
+		if raw == nil {
+			fv.Set(reflect.Zero(fv.Type()))
+			return nil
+		}
+		ptr := reflect.New(fv.Type().Elem())
+		if err := decodeItemValue(raw, ptr.Elem(), f); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	if raw == nil {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+
+	switch fv.Type() {
+	case timeType:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("unexpected date value %v", raw)
+		}
+		d, err := parseItemDate(s)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(d))
+		return nil
+	case lookupType:
+		fv.Set(reflect.ValueOf(toLookupValue(raw, f.show)))
+		return nil
+	case userType:
+		fv.Set(reflect.ValueOf(toUserValue(raw)))
+		return nil
+	case urlValueType:
+		m, _ := raw.(map[string]interface{})
+		fv.Set(reflect.ValueOf(FieldURLValue{URL: toString(m["Url"]), Description: toString(m["Description"])}))
+		return nil
+	case taxonomyType:
+		fv.Set(reflect.ValueOf(toTaxonomyValue(raw)))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			break // []byte is decoded from base64 string
+		}
+		values, ok := raw.([]interface{})
+		if !ok {
+			values = []interface{}{raw}
+		}
+		slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, val := range values {
+			if err := decodeItemValue(val, slice.Index(i), f); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	case reflect.String:
+		fv.SetString(toString(raw))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toFloat(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(n))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := toFloat(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetUint(uint64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		n, err := toFloat(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+		return nil
+	case reflect.Bool:
+		switch b := raw.(type) {
+		case bool:
+			fv.SetBool(b)
+		case string:
+			fv.SetBool(b == "1" || strings.EqualFold(b, "true") || strings.EqualFold(b, "yes"))
+		case float64:
+			fv.SetBool(b != 0)
+		}
+		return nil
+	}
+
+	// Fallback to JSON conversion for custom types
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, fv.Addr().Interface())
+}
+
+// encodeItemValue converts struct field value to payload property
+func encodeItemValue(f *itemField, fv reflect.Value) (string, interface{}, error) {
+	key := f.name
+	if strings.HasPrefix(key, "_") {
+		key = "OData_" + key
+	}
+	elemType := derefType(sliceElem(f.typ))
+	isSlice := f.typ.Kind() == reflect.Slice && f.typ.Elem().Kind() != reflect.Uint8
+
+	if elemType == lookupType || elemType == userType {
+		key += "Id"
+	}
+	if fv.Kind() == reflect.Ptr && fv.IsNil() {
+		return key, nil, nil
+	}
+	fv = reflect.Indirect(fv)
+
+	if isSlice && elemType == taxonomyType {
+		return "", nil, fmt.Errorf("multi-value taxonomy field %s can't be set with REST payload, use ValidateUpdateListItem", f.name)
+	}
+	if isSlice {
+		var results []interface{}
+		collectionType := "Collection(Edm.String)"
+		for i := 0; i < fv.Len(); i++ {
+			el := reflect.Indirect(fv.Index(i))
+			switch elemType {
+			case lookupType:
+				results = append(results, el.Interface().(FieldLookupValue).ID)
+				collectionType = "Collection(Edm.Int32)"
+			case userType:
+				results = append(results, el.Interface().(FieldUserValue).ID)
+				collectionType = "Collection(Edm.Int32)"
+			default:
+				v, err := encodeScalar(el)
+				if err != nil {
+					return "", nil, err
+				}
+				results = append(results, v)
+				if k := el.Kind(); k >= reflect.Int && k <= reflect.Uint64 {
+					collectionType = "Collection(Edm.Int32)"
+				}
+			}
+		}
+		if results == nil {
+			results = []interface{}{}
+		}
+		return key, map[string]interface{}{
+			"__metadata": map[string]string{"type": collectionType},
+			"results":    results,
+		}, nil
+	}
+
+	switch elemType {
+	case lookupType:
+		if id := fv.Interface().(FieldLookupValue).ID; id > 0 {
+			return key, id, nil
+		}
+		return key, nil, nil
+	case userType:
+		if id := fv.Interface().(FieldUserValue).ID; id > 0 {
+			return key, id, nil
+		}
+		return key, nil, nil
+	case urlValueType:
+		u := fv.Interface().(FieldURLValue)
+		return key, map[string]interface{}{
+			"__metadata":  map[string]string{"type": "SP.FieldUrlValue"},
+			"Url":         u.URL,
+			"Description": u.Description,
+		}, nil
+	case taxonomyType:
+		t := fv.Interface().(FieldTaxonomyValue)
+		wssID := t.WssID
+		if wssID == 0 {
+			wssID = -1
+		}
+		return key, map[string]interface{}{
+			"__metadata": map[string]string{"type": "SP.Taxonomy.TaxonomyFieldValue"},
+			"Label":      t.Label,
+			"TermGuid":   t.TermGUID,
+			"WssId":      wssID,
+		}, nil
+	}
+	v, err := encodeScalar(fv)
+	return key, v, err
+}
+
+func encodeScalar(fv reflect.Value) (interface{}, error) {
+	if fv.Type() == timeType {
+		d := fv.Interface().(time.Time)
+		if d.IsZero() {
+			return nil, nil
+		}
+		return d.UTC().Format(time.RFC3339), nil
+	}
+	return fv.Interface(), nil
+}
+
+func toLookupValue(raw interface{}, show string) FieldLookupValue {
+	if m, ok := raw.(map[string]interface{}); ok {
+		id, _ := toFloat(firstOf(m, "Id", "ID"))
+		return FieldLookupValue{ID: int(id), Value: toString(m[show])}
+	}
+	id, _ := toFloat(raw)
+	return FieldLookupValue{ID: int(id)}
+}
+
+func toUserValue(raw interface{}) FieldUserValue {
+	if m, ok := raw.(map[string]interface{}); ok {
+		id, _ := toFloat(firstOf(m, "Id", "ID"))
+		return FieldUserValue{
+			ID:        int(id),
+			Title:     toString(m["Title"]),
+			Email:     toString(firstOf(m, "EMail", "Email")),
+			LoginName: toString(firstOf(m, "Name", "LoginName")),
+		}
+	}
+	id, _ := toFloat(raw)
+	return FieldUserValue{ID: int(id)}
+}
+
+func toTaxonomyValue(raw interface{}) FieldTaxonomyValue {
+	m, _ := raw.(map[string]interface{})
+	wssID, _ := toFloat(m["WssId"])
+	return FieldTaxonomyValue{Label: toString(m["Label"]), TermGUID: toString(m["TermGuid"]), WssID: int(wssID)}
+}
+
+func firstOf(m map[string]interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		if val, ok := m[key]; ok {
+			return val
+		}
+	}
+	return nil
+}
+
+func toString(raw interface{}) string {
+	switch v := raw.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", raw)
+}
+
+func toFloat(raw interface{}) (float64, error) {
+	switch v := raw.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return v, nil
+	case string:
+		if v == "" {
+			return 0, nil
+		}
+		return strconv.ParseFloat(v, 64)
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unexpected numeric value %v", raw)
+}
+
+func parseItemDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range itemDateFormats {
+		if d, err := time.Parse(layout, value); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("can't parse date %s", value)
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func sliceElem(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		return t.Elem()
+	}
+	return t
+}
+
+func isZeroValue(fv reflect.Value) bool {
+	return reflect.DeepEqual(fv.Interface(), reflect.Zero(fv.Type()).Interface())
+}
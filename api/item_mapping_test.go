@@ -0,0 +1,242 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+type mappedItem struct {
+	ID        int                  `sp:"Id"`
+	Title     string               `sp:"Title"`
+	Amount    float64              `sp:"Amount,omitempty"`
+	Due       time.Time            `sp:"DueDate"`
+	Closed    *time.Time           `sp:"ClosedDate"`
+	Category  FieldLookupValue     `sp:"Category,show=Name"`
+	Tags      []FieldLookupValue   `sp:"Tags"`
+	Manager   FieldUserValue       `sp:"Manager"`
+	Choices   []string             `sp:"Choices"`
+	Link      FieldURLValue        `sp:"Link"`
+	Term      FieldTaxonomyValue   `sp:"Term"`
+	Terms     []FieldTaxonomyValue `sp:"Terms,readonly"`
+	Hidden    string               `sp:"_Hidden,omitempty"`
+	Untagged  string
+	Created   time.Time `sp:"Created"`
+	Cancelled bool      `sp:"Cancelled"`
+}
+
+func TestItemMappingUnmarshal(t *testing.T) {
+	payloads := map[string]string{
+		"Verbose": `{"d":{
+			"__metadata":{"type":"SP.Data.TasksListItem"},
+			"Id":1,"Title":"Task","Amount":10.5,"DueDate":"2020-01-02T03:04:05Z","ClosedDate":null,
+			"Category":{"__metadata":{"type":"SP.Data.CategoriesListItem"},"Id":2,"Name":"Cat"},
+			"Tags":{"results":[{"Id":3,"Title":"A"},{"Id":4,"Title":"B"}]},
+			"Manager":{"Id":5,"Title":"John","EMail":"john@contoso.com","Name":"i:0#.f|membership|john"},
+			"Choices":{"__metadata":{"type":"Collection(Edm.String)"},"results":["One","Two"]},
+			"Link":{"__metadata":{"type":"SP.FieldUrlValue"},"Url":"https://contoso.com","Description":"Contoso"},
+			"Term":{"__metadata":{"type":"SP.Taxonomy.TaxonomyFieldValue"},"Label":"7","TermGuid":"a-b","WssId":7},
+			"Terms":{"results":[{"Label":"8","TermGuid":"c-d","WssId":8}]},
+			"OData__Hidden":"hidden","Created":"2020-01-01T00:00:00","Cancelled":false
+		}}`,
+		"Minimalmetadata": `{
+			"odata.type":"SP.Data.TasksListItem","Category@odata.navigationLinkUrl":"Items(1)/Category",
+			"Id":1,"Title":"Task","Amount":10.5,"DueDate":"2020-01-02T03:04:05Z","ClosedDate":null,
+			"Category":{"odata.type":"SP.Data.CategoriesListItem","Id":2,"Name":"Cat"},
+			"Tags":[{"Id":3,"Title":"A"},{"Id":4,"Title":"B"}],
+			"Manager":{"Id":5,"Title":"John","EMail":"john@contoso.com","Name":"i:0#.f|membership|john"},
+			"Choices":["One","Two"],
+			"Link":{"Url":"https://contoso.com","Description":"Contoso"},
+			"Term":{"Label":"7","TermGuid":"a-b","WssId":7},
+			"Terms":[{"Label":"8","TermGuid":"c-d","WssId":8}],
+			"OData__Hidden":"hidden","Created":"2020-01-01T00:00:00Z","Cancelled":false
+		}`,
+		"Nometadata": `{
+			"Id":1,"Title":"Task","Amount":10.5,"DueDate":"2020-01-02T03:04:05Z","ClosedDate":null,
+			"Category":{"Id":2,"Name":"Cat"},
+			"Tags":[{"Id":3,"Title":"A"},{"Id":4,"Title":"B"}],
+			"Manager":{"Id":5,"Title":"John","EMail":"john@contoso.com","Name":"i:0#.f|membership|john"},
+			"Choices":["One","Two"],
+			"Link":{"Url":"https://contoso.com","Description":"Contoso"},
+			"Term":{"Label":"7","TermGuid":"a-b","WssId":7},
+			"Terms":[{"Label":"8","TermGuid":"c-d","WssId":8}],
+			"OData__Hidden":"hidden","Created":"2020-01-01T00:00:00Z","Cancelled":false
+		}`,
+	}
+
+	for mode, payload := range payloads {
+		t.Run(mode, func(t *testing.T) {
+			item := &mappedItem{}
+			resp := ItemResp(payload)
+			if err := resp.Unmarshal(item); err != nil {
+				t.Fatal(err)
+			}
+			if item.ID != 1 || item.Title != "Task" || item.Amount != 10.5 {
+				t.Errorf("unexpected plain fields: %+v", item)
+			}
+			if !item.Due.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) || item.Closed != nil {
+				t.Errorf("unexpected dates: %v, %v", item.Due, item.Closed)
+			}
+			if !item.Created.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("unexpected created date: %v", item.Created)
+			}
+			if item.Category != (FieldLookupValue{ID: 2, Value: "Cat"}) {
+				t.Errorf("unexpected lookup: %+v", item.Category)
+			}
+			if len(item.Tags) != 2 || item.Tags[1] != (FieldLookupValue{ID: 4, Value: "B"}) {
+				t.Errorf("unexpected multi lookup: %+v", item.Tags)
+			}
+			if item.Manager.ID != 5 || item.Manager.Email != "john@contoso.com" || item.Manager.LoginName == "" {
+				t.Errorf("unexpected user: %+v", item.Manager)
+			}
+			if len(item.Choices) != 2 || item.Choices[1] != "Two" {
+				t.Errorf("unexpected choices: %+v", item.Choices)
+			}
+			if item.Link.URL != "https://contoso.com" || item.Link.Description != "Contoso" {
+				t.Errorf("unexpected URL: %+v", item.Link)
+			}
+			if item.Term.TermGUID != "a-b" || item.Term.WssID != 7 || len(item.Terms) != 1 {
+				t.Errorf("unexpected taxonomy: %+v, %+v", item.Term, item.Terms)
+			}
+			if item.Hidden != "hidden" {
+				t.Errorf("unexpected underscored field: %s", item.Hidden)
+			}
+		})
+	}
+}
+
+func TestItemMappingUnmarshalIDs(t *testing.T) {
+	payload := `{"d":{"results":[
+		{"Id":1,"Category":{"__deferred":{}},"CategoryId":2,"TagsId":{"results":[3,4]},"ManagerId":5}
+	]}}`
+	var items []*mappedItem
+	resp := ItemsResp(payload)
+	if err := resp.Unmarshal(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	item := items[0]
+	if item.Category.ID != 2 || len(item.Tags) != 2 || item.Tags[0].ID != 3 || item.Manager.ID != 5 {
+		t.Errorf("unexpected not expanded lookups: %+v", item)
+	}
+}
+
+func TestItemMappingMarshal(t *testing.T) {
+	closed := time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)
+	item := &mappedItem{
+		ID:       1,
+		Title:    "Task",
+		Due:      time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("+3", 3*60*60)),
+		Closed:   &closed,
+		Category: FieldLookupValue{ID: 2},
+		Tags:     []FieldLookupValue{{ID: 3}, {ID: 4}},
+		Manager:  FieldUserValue{ID: 5},
+		Choices:  []string{"One"},
+		Link:     FieldURLValue{URL: "https://contoso.com", Description: "Contoso"},
+		Term:     FieldTaxonomyValue{Label: "Term", TermGUID: "a-b"},
+		Terms:    []FieldTaxonomyValue{{Label: "Term"}},
+	}
+	data, err := MarshalItem(item, "SP.Data.TasksListItem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := map[string]interface{}{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{` +
+		`"Cancelled":false,` +
+		`"CategoryId":2,` +
+		`"Choices":{"__metadata":{"type":"Collection(Edm.String)"},"results":["One"]},` +
+		`"ClosedDate":"2020-02-03T04:05:06Z",` +
+		`"DueDate":"2020-01-02T00:04:05Z",` +
+		`"Link":{"Description":"Contoso","Url":"https://contoso.com","__metadata":{"type":"SP.FieldUrlValue"}},` +
+		`"ManagerId":5,` +
+		`"TagsId":{"__metadata":{"type":"Collection(Edm.Int32)"},"results":[3,4]},` +
+		`"Term":{"Label":"Term","TermGuid":"a-b","WssId":-1,"__metadata":{"type":"SP.Taxonomy.TaxonomyFieldValue"}},` +
+		`"Title":"Task",` +
+		`"__metadata":{"type":"SP.Data.TasksListItem"}` +
+		`}`
+	expectedMap := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(expected), &expectedMap); err != nil {
+		t.Fatal(err)
+	}
+	actual, _ := json.Marshal(payload)
+	exp, _ := json.Marshal(expectedMap)
+	if string(actual) != string(exp) {
+		t.Errorf("unexpected payload:\n%s\nexpected:\n%s", actual, exp)
+	}
+
+	type multiTaxonomy struct {
+		Terms []FieldTaxonomyValue `sp:"Terms"`
+	}
+	if _, err := MarshalItem(&multiTaxonomy{}, ""); err == nil {
+		t.Error("multi-value taxonomy should not be supported")
+	}
+}
+
+func TestItemMappingSelect(t *testing.T) {
+	sel, exp := ItemSelect(&[]mappedItem{})
+	expectedSel := "Id,Title,Amount,DueDate,ClosedDate,Category/Id,Category/Name,Tags/Id,Tags/Title," +
+		"Manager/Id,Manager/Title,Manager/EMail,Manager/Name,Choices,Link,Term,Terms,_Hidden,Created,Cancelled"
+	if sel != expectedSel {
+		t.Errorf("unexpected $select: %s", sel)
+	}
+	if exp != "Category,Tags,Manager" {
+		t.Errorf("unexpected $expand: %s", exp)
+	}
+
+	items := NewItems(nil, "https://contoso.sharepoint.com/_api/Web/Lists/GetByTitle('Tasks')/Items", nil)
+	items.SelectFor(mappedItem{})
+	if items.modifiers.Get()["$expand"] != "Category,Tags,Manager" {
+		t.Errorf("unexpected modifiers: %v", items.modifiers.Get())
+	}
+}
+
+func TestItemMapping(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	type task struct {
+		ID       int            `sp:"Id"`
+		Title    string         `sp:"Title"`
+		Priority float64        `sp:"Priority"`
+		Author   FieldUserValue `sp:"Author"`
+	}
+
+	if _, err := srv.AddList("Tasks", 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.AddField("Tasks", "Priority", "Number"); err != nil {
+		t.Fatal(err)
+	}
+
+	for mode := range mockModes {
+		t.Run(mode, func(t *testing.T) {
+			list := newMockSP(t, srv, mode).Web().Lists().GetByTitle("Tasks")
+			payload, err := MarshalItem(&task{Title: "Mapped " + mode, Priority: 2}, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := list.Items().Add(payload); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := list.Items().SelectFor(task{}).Filter("Title eq 'Mapped " + mode + "'").Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var tasks []task
+			if err := resp.Unmarshal(&tasks); err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != 1 || tasks[0].Priority != 2 || tasks[0].Author.Title != "Mock Admin" {
+				t.Errorf("unexpected items: %+v", tasks)
+			}
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
-	"strconv"
 	"strings"
 
 	"github.com/koltyakov/gosip"
@@ -50,29 +49,73 @@ func (items *Items) Get() (ItemsResp, error) {
 	return data, nil
 }
 
-// GetAll gets all items in a list using internal page helper. The use case of the method is getting all the content from large lists.
-// Method ignores custom sorting and filtering as not supported for the large lists due to throttling limitations.
+// GetAll gets all items in a list using items iterator. The use case of the method is getting all the content from large lists.
+// All OData modifiers are applied, filtering and sorting by not indexed fields can be throttled in lists exceeding the view threshold.
+// On a failed page the items received so far are returned along with the error, use Iterator to resume from the failed page.
 func (items *Items) GetAll() ([]ItemResp, error) {
-	return getAll(nil, nil, items)
+	var res []ItemResp
+	it := items.Iterator()
+	for it.NextPage() {
+		for _, item := range it.Page() {
+			res = append(res, ItemResp(item))
+		}
+	}
+	return res, it.Err()
+}
+
+// Iterator gets items pages iterator, page size is controlled with Top modifier
+func (items *Items) Iterator() *Iterator {
+	return NewIterator(items.client, items.ToURL(), items.config)
 }
 
 // Add adds new item in this list. `body` parameter is byte array representation of JSON string payload relevant to item metadata object.
 func (items *Items) Add(body []byte) (ItemResp, error) {
-	body = patchMetadataTypeCB(body, func() string {
-		endpoint := getPriorEndpoint(items.endpoint, "/Items")
-		cacheKey := strings.ToLower(endpoint + "@entitytype")
-		if oDataType, found := storage.Get(cacheKey); found {
-			return oDataType.(string)
-		}
-		list := NewList(items.client, endpoint, nil)
-		oDataType, _ := list.GetEntityType()
-		storage.Set(cacheKey, oDataType, 0)
-		return oDataType
-	})
+	body = patchMetadataTypeCB(body, items.entityType)
 	client := NewHTTPClient(items.client)
 	return client.Post(items.endpoint, bytes.NewBuffer(body), items.config)
 }
 
+// ItemAddResult AddBatch item outcome
+type ItemAddResult struct {
+	Data ItemResp
+	Err  error
+}
+
+// AddBatch adds items with a single $batch request, each item is added in its own changeset.
+// Per-item outcomes are returned in bodies order, the error is returned when the batch itself fails.
+func (items *Items) AddBatch(bodies [][]byte) ([]*ItemAddResult, error) {
+	requests := make([]*BatchRequest, len(bodies))
+	for i, body := range bodies {
+		requests[i] = &BatchRequest{URL: items.endpoint, Body: patchMetadataTypeCB(body, items.entityType)}
+	}
+	client := NewHTTPClient(items.client)
+	responses, err := client.Batch(items.endpoint, requests, items.config)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*ItemAddResult, len(responses))
+	for i, resp := range responses {
+		results[i] = &ItemAddResult{Err: resp.Err}
+		if resp.Err == nil {
+			results[i].Data = ItemResp(resp.Body)
+		}
+	}
+	return results, nil
+}
+
+// entityType gets list items entity type name, the name is cached
+func (items *Items) entityType() string {
+	endpoint := getPriorEndpoint(items.endpoint, "/Items")
+	cacheKey := strings.ToLower(endpoint + "@entitytype")
+	if oDataType, found := storage.Get(cacheKey); found {
+		return oDataType.(string)
+	}
+	list := NewList(items.client, endpoint, nil)
+	oDataType, _ := list.GetEntityType()
+	storage.Set(cacheKey, oDataType, 0)
+	return oDataType
+}
+
 // GetByID gets item data object by its ID
 func (items *Items) GetByID(itemID int) *Item {
 	return NewItem(
@@ -110,44 +153,6 @@ func (items *Items) GetByCAML(caml string) (ItemsResp, error) {
 	return client.Post(apiURL.String(), bytes.NewBuffer(body), items.config)
 }
 
-// ToDo:
-// Batch
-
-// Helper methods
-
-func getAll(res []ItemResp, cur ItemsResp, items *Items) ([]ItemResp, error) {
-	if res == nil && cur == nil {
-		itemsCopy := NewItems(items.client, items.endpoint, items.config)
-		for key, val := range items.modifiers.Get() {
-			switch key {
-			case "$select":
-				itemsCopy.modifiers.AddSelect(val)
-			case "$expand":
-				itemsCopy.modifiers.AddExpand(val)
-			case "$top":
-				top, _ := strconv.Atoi(val)
-				itemsCopy.modifiers.AddTop(top)
-			}
-		}
-		itemsResp, err := itemsCopy.Get()
-		if err != nil {
-			return nil, err
-		}
-		res = itemsResp.Data()
-		cur = itemsResp
-	}
-	nextURL := getODataCollectionNextPageURL(cur)
-	if nextURL == "" {
-		return res, nil
-	}
-	nextItemsResp, err := NewItems(items.client, nextURL, items.config).Get()
-	if err != nil {
-		return res, err
-	}
-	res = append(res, nextItemsResp.Data()...)
-	return getAll(res, nextItemsResp, items)
-}
-
 /* Pagination helpers */
 
 // ItemsPage - paged items
@@ -18,7 +18,9 @@ type ValidateAddOptions struct {
 }
 
 // AddValidate adds new item in this list using AddValidateUpdateItemUsingPath method.
-// formValues fingerprints https://github.com/koltyakov/sp-sig-20180705-demo/blob/master/src/03-pnp/FieldTypes.md#field-data-types-fingerprints-sample
+// formValues fingerprints https://github.com/koltyakov/sp-sig-20180705-demo/blob/master/src/03-pnp/FieldTypes.md#field-data-types-fingerprints-sample,
+// use Form*Value helpers to format complex field types values.
+// When some of the fields fail validation, the response is returned along with *ValidationError.
 func (items *Items) AddValidate(formValues map[string]string, options *ValidateAddOptions) (AddValidateResp, error) {
 	endpoint := fmt.Sprintf("%s/AddValidateUpdateItemUsingPath()", getPriorEndpoint(items.endpoint, "/items"))
 	client := NewHTTPClient(items.client)
@@ -48,7 +50,11 @@ func (items *Items) AddValidate(formValues map[string]string, options *ValidateA
 		}
 	}
 	body, _ := json.Marshal(payload)
-	return client.Post(endpoint, bytes.NewBuffer(body), items.config)
+	resp, err := client.Post(endpoint, bytes.NewBuffer(body), items.config)
+	if err != nil {
+		return resp, err
+	}
+	return resp, formValuesError(parseFormValues(resp))
 }
 
 /* AddValidate response helpers */
@@ -74,13 +80,17 @@ func (avResp *AddValidateResp) Data() []map[string]interface{} {
 	return d
 }
 
-// ID gets created item's ID from the response
+// Values gets field values validation results
+func (avResp *AddValidateResp) Values() []*FormValueInfo {
+	return parseFormValues(*avResp)
+}
+
+// ID gets created item's ID from the response, 0 is returned when the item is not created
 func (avResp *AddValidateResp) ID() int {
-	dd := avResp.Data()
-	for _, d := range dd {
-		if d["FieldName"] == "Id" {
-			d, _ := strconv.Atoi(d["FieldValue"].(string))
-			return d
+	for _, v := range avResp.Values() {
+		if v.FieldName == "Id" {
+			id, _ := strconv.Atoi(v.FieldValue)
+			return id
 		}
 	}
 	return 0
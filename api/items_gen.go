@@ -4,6 +4,8 @@ package api
 
 import (
 	"encoding/json"
+
+	"github.com/koltyakov/gosip/odata"
 )
 
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
@@ -30,6 +32,12 @@ func (items *Items) Filter(oDataFilter string) *Items {
 	return items
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (items *Items) FilterExpr(oDataFilter odata.Expr) *Items {
+	items.modifiers.AddFilter(oDataFilter.String())
+	return items
+}
+
 // Top adds $top OData modifier
 func (items *Items) Top(oDataTop int) *Items {
 	items.modifiers.AddTop(oDataTop)
@@ -0,0 +1,342 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koltyakov/gosip/odata"
+)
+
+// UpsertStatus item upsert outcome
+type UpsertStatus string
+
+// Upsert outcomes
+const (
+	UpsertCreated   UpsertStatus = "created"
+	UpsertUpdated   UpsertStatus = "updated"
+	UpsertUnchanged UpsertStatus = "unchanged" // item's fields already match the payload
+	UpsertFailed    UpsertStatus = "failed"
+)
+
+// UpsertRow UpsertMany row, Body is item metadata JSON payload
+type UpsertRow struct {
+	Key  interface{}
+	Body []byte
+}
+
+// UpsertResult item upsert outcome
+type UpsertResult struct {
+	Key    interface{}
+	ID     int // created or updated item ID, 0 when failed before the item was found or created
+	Status UpsertStatus
+	Err    error
+}
+
+// UpsertOptions UpsertMany options
+type UpsertOptions struct {
+	LookupSize int // keys resolved with a single lookup query, defaults to 50
+}
+
+// upsertLocks striped in-process locks serializing upserts of the same key
+var upsertLocks [64]sync.Mutex
+
+// Upsert creates or updates the item which keyField equals to keyValue. `body` parameter is byte array representation
+// of JSON string payload relevant to item metadata object, keyField value is added to the payload on creation.
+// The key field should be indexed for the lists exceeding the list view threshold.
+// Concurrent upserts of the same key within the process are serialized, for the items created concurrently
+// by other processes the one with the lowest ID wins, the duplicate created by this call is deleted.
+// Enforced unique values of the key field make creation conflicts fail on the server, such conflicts are retried as updates.
+func (items *Items) Upsert(keyField string, keyValue interface{}, body []byte) (*UpsertResult, error) {
+	res := &UpsertResult{Key: keyValue, Status: UpsertFailed}
+	payload, err := upsertPayload(body)
+	if err != nil {
+		res.Err = err
+		return res, err
+	}
+
+	lock := upsertLock(items.endpoint, keyField, keyValue)
+	lock.Lock()
+	defer lock.Unlock()
+
+	existing, err := items.upsertLookup(keyField, []interface{}{keyValue}, payload)
+	if err != nil {
+		res.Err = err
+		return res, err
+	}
+	res = items.upsertRow(keyField, keyValue, payload, existing[upsertKey(keyValue)])
+	return res, res.Err
+}
+
+// UpsertMany creates or updates the items matching the rows keys in keyField. Existing keys are resolved with batched lookup queries,
+// then missing items are created and existing items are updated unless their fields already match the payload.
+// Rows are written under the same in-process key locks as Upsert.
+// Per-row outcomes are returned in rows order, the error reports failed rows count when some of the rows failed.
+func (items *Items) UpsertMany(keyField string, rows []*UpsertRow, options *UpsertOptions) ([]*UpsertResult, error) {
+	lookupSize := 50
+	if options != nil && options.LookupSize > 0 {
+		lookupSize = options.LookupSize
+	}
+
+	results := make([]*UpsertResult, len(rows))
+	payloads := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		results[i] = &UpsertResult{Key: row.Key, Status: UpsertFailed}
+		payloads[i], results[i].Err = upsertPayload(row.Body)
+	}
+
+	existing := map[string][]map[string]interface{}{}
+	for start := 0; start < len(rows); start += lookupSize {
+		end := start + lookupSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		var keys []interface{}
+		fields := map[string]interface{}{}
+		for i := start; i < end; i++ {
+			if results[i].Err != nil {
+				continue
+			}
+			keys = append(keys, rows[i].Key)
+			for key := range payloads[i] {
+				fields[key] = nil
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		found, err := items.upsertLookup(keyField, keys, fields)
+		for i := start; i < end; i++ {
+			if err != nil && results[i].Err == nil {
+				results[i].Err = err
+			}
+		}
+		for key, matches := range found {
+			existing[key] = matches
+		}
+	}
+
+	failed := 0
+	for i, row := range rows {
+		if results[i].Err == nil {
+			key := upsertKey(row.Key)
+			lock := upsertLock(items.endpoint, keyField, row.Key)
+			lock.Lock()
+			results[i] = items.upsertRow(keyField, row.Key, payloads[i], existing[key])
+			lock.Unlock()
+			// Later rows with the same key target the item created or updated by this row
+			if results[i].ID != 0 && len(existing[key]) == 0 {
+				created := map[string]interface{}{"Id": float64(results[i].ID)}
+				for k, v := range payloads[i] {
+					created[k] = v
+				}
+				existing[key] = []map[string]interface{}{created}
+			}
+		}
+		if results[i].Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d rows failed to upsert", failed, len(rows))
+	}
+	return results, nil
+}
+
+// upsertRow creates or updates a single item, existing are the items matching the key ordered by ID
+func (items *Items) upsertRow(keyField string, keyValue interface{}, payload map[string]interface{}, existing []map[string]interface{}) *UpsertResult {
+	res := &UpsertResult{Key: keyValue, Status: UpsertFailed}
+
+	if len(existing) > 0 {
+		current := existing[0]
+		id, _ := toFloat(current["Id"])
+		res.ID = int(id)
+		if upsertFieldsMatch(payload, current) {
+			res.Status = UpsertUnchanged
+			return res
+		}
+		body, _ := json.Marshal(payload)
+		if _, res.Err = items.GetByID(res.ID).Update(body); res.Err == nil {
+			res.Status = UpsertUpdated
+			for k, v := range payload {
+				current[k] = v
+			}
+		}
+		return res
+	}
+
+	create := map[string]interface{}{keyField: keyValue}
+	for k, v := range payload {
+		create[k] = v
+	}
+	body, _ := json.Marshal(create)
+	itemResp, addErr := items.Add(body)
+
+	// Re-check the key, the item could be created concurrently by another process
+	found, err := items.upsertLookup(keyField, []interface{}{keyValue}, payload)
+	if err != nil {
+		res.Err = err
+		if addErr != nil {
+			res.Err = addErr
+		}
+		return res
+	}
+	matches := found[upsertKey(keyValue)]
+	if addErr != nil {
+		if len(matches) == 0 {
+			res.Err = addErr
+			return res
+		}
+		// Unique values conflict, the item exists now
+		return items.upsertRow(keyField, keyValue, payload, matches)
+	}
+
+	res.ID = itemResp.Data().ID
+	res.Status = UpsertCreated
+	if len(matches) > 0 {
+		winnerID, _ := toFloat(matches[0]["Id"])
+		if int(winnerID) != res.ID {
+			if res.Err = items.GetByID(res.ID).Delete(); res.Err != nil {
+				res.Status = UpsertFailed
+				return res
+			}
+			return items.upsertRow(keyField, keyValue, payload, matches[:1])
+		}
+	}
+	return res
+}
+
+// upsertLookup gets items matching the keys, the items are grouped by key and ordered by ID
+func (items *Items) upsertLookup(keyField string, keys []interface{}, fields map[string]interface{}) (map[string][]map[string]interface{}, error) {
+	selectFields := []string{"Id", keyField}
+	for field := range fields {
+		if field != keyField && field != "Id" {
+			selectFields = append(selectFields, field)
+		}
+	}
+	sort.Strings(selectFields[2:])
+
+	lookup := NewItems(items.client, items.endpoint, items.config).
+		Select(strings.Join(selectFields, ",")).
+		Filter(odata.Field(keyField).In(keys...).String()).
+		OrderBy("Id", true).
+		Top(5000)
+	resp, err := lookup.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	found := map[string][]map[string]interface{}{}
+	for _, itemResp := range resp {
+		item := itemResp.ToMap()
+		key := upsertKey(item[keyField])
+		found[key] = append(found[key], item)
+	}
+	return found, nil
+}
+
+// upsertPayload parses item metadata payload
+func upsertPayload(body []byte) (map[string]interface{}, error) {
+	payload := map[string]interface{}{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("unable to parse upsert payload: %w", err)
+	}
+	delete(payload, "__metadata")
+	return payload, nil
+}
+
+// upsertKey gets comparable key representation, e.g. 42 and float64(42) from the response are the same key,
+// text keys are case insensitive the same way as `eq` text comparisons on the server
+func upsertKey(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case *time.Time:
+		return upsertKey(*v)
+	case odata.DateTime:
+		return upsertKey(time.Time(v))
+	case odata.GUID:
+		return strings.ToLower(strings.Trim(string(v), "{}"))
+	case string:
+		if d, err := time.Parse(time.RFC3339, v); err == nil {
+			return d.UTC().Format(time.RFC3339)
+		}
+		return strings.ToLower(v)
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("%d", rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("%d", rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// upsertFieldsMatch checks if the item's values already match the payload
+func upsertFieldsMatch(payload map[string]interface{}, item map[string]interface{}) bool {
+	for key, value := range payload {
+		current, ok := item[key]
+		if !ok || !upsertValuesMatch(value, current) {
+			return false
+		}
+	}
+	return true
+}
+
+func upsertValuesMatch(a interface{}, b interface{}) bool {
+	a, b = upsertNormalizeValue(a), upsertNormalizeValue(b)
+	if a == nil || b == nil {
+		// Empty text values are returned as nulls
+		return (a == nil || a == "") && (b == nil || b == "")
+	}
+	if s1, ok := a.(string); ok {
+		if s2, ok := b.(string); ok {
+			d1, err1 := time.Parse(time.RFC3339, s1)
+			d2, err2 := time.Parse(time.RFC3339, s2)
+			if err1 == nil && err2 == nil {
+				return d1.Equal(d2)
+			}
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// upsertNormalizeValue drops metadata and unwraps collections results
+func upsertNormalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if results, ok := v["results"]; ok {
+			return upsertNormalizeValue(results)
+		}
+		m := map[string]interface{}{}
+		for key, val := range v {
+			if key != "__metadata" {
+				m[key] = upsertNormalizeValue(val)
+			}
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = upsertNormalizeValue(val)
+		}
+		return s
+	}
+	return value
+}
+
+// upsertLock gets in-process lock of the list's key
+func upsertLock(endpoint string, keyField string, keyValue interface{}) *sync.Mutex {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.ToLower(endpoint + "|" + keyField + "|" + upsertKey(keyValue))))
+	return &upsertLocks[h.Sum32()%uint32(len(upsertLocks))]
+}
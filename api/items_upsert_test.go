@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestUpsert(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	for mode := range mockModes {
+		t.Run(mode, func(t *testing.T) {
+			listTitle := "Employees " + mode
+			if _, err := srv.AddList(listTitle, 100); err != nil {
+				t.Fatal(err)
+			}
+			if err := srv.AddField(listTitle, "EmployeeId", "Text"); err != nil {
+				t.Fatal(err)
+			}
+			if err := srv.AddField(listTitle, "Salary", "Number"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := srv.AddItem(listTitle, map[string]interface{}{"Title": "John", "EmployeeId": "E1", "Salary": 100}); err != nil {
+				t.Fatal(err)
+			}
+			items := newMockSP(t, srv, mode).Web().Lists().GetByTitle(listTitle).Items()
+
+			res, err := items.Upsert("EmployeeId", "E2", []byte(`{"Title":"Jane","Salary":200}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != UpsertCreated || res.ID != 2 {
+				t.Errorf("unexpected result: %+v", res)
+			}
+			if res, err := items.Upsert("EmployeeId", "E2", []byte(`{"Title":"Jane","Salary":200}`)); err != nil || res.Status != UpsertUnchanged {
+				t.Errorf("unexpected result: %+v, %v", res, err)
+			}
+
+			rows := []*UpsertRow{
+				{Key: "E1", Body: []byte(`{"Title":"John","Salary":100}`)},
+				{Key: "E2", Body: []byte(`{"Title":"Jane","Salary":250}`)},
+				{Key: "E3", Body: []byte(`{"Title":"Bob","Salary":300}`)},
+				{Key: "E3", Body: []byte(`{"Title":"Bob","Salary":350}`)},
+				{Key: "E4", Body: []byte(`not a json`)},
+			}
+			results, err := items.UpsertMany("EmployeeId", rows, &UpsertOptions{LookupSize: 2})
+			if err == nil {
+				t.Error("failed row should be reported")
+			}
+			var statuses []string
+			for _, r := range results {
+				statuses = append(statuses, fmt.Sprintf("%s:%d:%s", r.Key, r.ID, r.Status))
+			}
+			expected := "[E1:1:unchanged E2:2:updated E3:3:created E3:3:updated E4:0:failed]"
+			if fmt.Sprintf("%v", statuses) != expected {
+				t.Errorf("expected %s, got %v", expected, statuses)
+			}
+
+			item, err := items.GetByID(3).Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if salary := item.ToMap()["Salary"]; salary != float64(350) {
+				t.Errorf("unexpected salary: %v", salary)
+			}
+
+			// Text keys are compared case insensitively the same way as the server's `eq` filter does
+			if res, err := items.Upsert("EmployeeId", "e3", []byte(`{"Title":"Bob","Salary":350}`)); err != nil || res.Status != UpsertUnchanged || res.ID != 3 {
+				t.Errorf("unexpected result: %+v, %v", res, err)
+			}
+			results, err = items.UpsertMany("EmployeeId", []*UpsertRow{
+				{Key: "e5", Body: []byte(`{"Title":"Ann","Salary":400}`)},
+				{Key: "E5", Body: []byte(`{"Title":"Ann","Salary":450}`)},
+			}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if results[0].Status != UpsertCreated || results[1].Status != UpsertUpdated || results[0].ID != results[1].ID {
+				t.Errorf("rows with keys differing in case should target the same item: %+v, %+v", results[0], results[1])
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/koltyakov/gosip/odata"
+)
+
+// WindowOptions threshold-safe items querying options
+type WindowOptions struct {
+	Size        int // IDs range size of a window, defaults to 5000 (list view threshold)
+	Concurrency int // number of windows requested in parallel, defaults to 1
+	StartID     int // ID to start from, e.g. WindowIterator.LastID()+1 of a failed run
+}
+
+// WindowIterator iterates list items walking the list in ID ranges (windows).
+// Each window is filtered by the indexed ID column first, so the user's $filter is applied
+// to a range which never exceeds the list view threshold. Items are returned in ID order.
+type WindowIterator struct {
+	items   *Items
+	options *WindowOptions
+
+	started  bool
+	maxID    int
+	nextFrom int                  // start ID of the next window to request
+	inflight []chan *windowResult // requested windows in ID order
+	page     [][]byte
+	pos      int
+	lastID   int
+	err      error
+}
+
+// windowResult window items or error
+type windowResult struct {
+	from  int
+	items [][]byte
+	err   error
+}
+
+// Windowed gets threshold-safe items iterator for lists exceeding the list view threshold.
+// Select, Expand and Filter modifiers are applied within each ID window, OrderBy and Top are ignored
+// as the items are ordered by ID and paged by window size.
+func (items *Items) Windowed(options *WindowOptions) *WindowIterator {
+	opts := &WindowOptions{}
+	if options != nil {
+		*opts = *options
+	}
+	if opts.Size <= 0 {
+		opts.Size = 5000
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	return &WindowIterator{items: items, options: opts}
+}
+
+// Next advances to the next item, returns false when there are no more items or an error occurred
+func (it *WindowIterator) Next() bool {
+	for it.pos+1 >= len(it.page) {
+		if !it.nextWindow() {
+			return false
+		}
+		it.pos = -1
+	}
+	it.pos++
+	if id := itemID(it.page[it.pos]); id > it.lastID {
+		it.lastID = id
+	}
+	return true
+}
+
+// Item gets current item normalized payload
+func (it *WindowIterator) Item() []byte {
+	if it.pos < 0 || it.pos >= len(it.page) {
+		return nil
+	}
+	return it.page[it.pos]
+}
+
+// Err gets iteration error
+func (it *WindowIterator) Err() error {
+	return it.err
+}
+
+// LastID gets ID of the last received item, iteration can be resumed with StartID option set to LastID()+1
+func (it *WindowIterator) LastID() int {
+	return it.lastID
+}
+
+// ForEach calls the callback for each item, iteration stops on the first callback error
+func (it *WindowIterator) ForEach(callback func(item []byte) error) error {
+	for it.Next() {
+		if err := callback(it.Item()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// GetAll gets all items, on an error the items received so far are returned along with the error
+func (it *WindowIterator) GetAll() ([]ItemResp, error) {
+	var res []ItemResp
+	for it.Next() {
+		res = append(res, ItemResp(it.Item()))
+	}
+	return res, it.Err()
+}
+
+// nextWindow waits for the next window results keeping up to Concurrency windows requested
+func (it *WindowIterator) nextWindow() bool {
+	if it.err != nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		if err := it.bounds(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+	for {
+		for len(it.inflight) < it.options.Concurrency && it.nextFrom <= it.maxID {
+			res := make(chan *windowResult, 1)
+			go func(from int) {
+				res <- it.fetch(from)
+			}(it.nextFrom)
+			it.inflight = append(it.inflight, res)
+			it.nextFrom += it.options.Size
+		}
+		if len(it.inflight) == 0 {
+			it.page = nil
+			return false
+		}
+		w := <-it.inflight[0]
+		it.inflight = it.inflight[1:]
+		if w.err != nil {
+			it.err = w.err
+			it.inflight = nil
+			it.page = nil
+			return false
+		}
+		if len(w.items) > 0 {
+			it.page = w.items
+			return true
+		}
+		// empty windows are skipped, the last received ID moves forward for resuming
+		if end := w.from + it.options.Size - 1; end > it.lastID {
+			it.lastID = end
+		}
+	}
+}
+
+// bounds gets IDs range of the list
+func (it *WindowIterator) bounds() error {
+	minID, err := it.boundaryID(true)
+	if err != nil {
+		return err
+	}
+	maxID, err := it.boundaryID(false)
+	if err != nil {
+		return err
+	}
+	it.maxID = maxID
+	it.nextFrom = minID
+	if it.options.StartID > it.nextFrom {
+		it.nextFrom = it.options.StartID
+	}
+	if minID > 0 {
+		it.lastID = it.nextFrom - 1
+	}
+	return nil
+}
+
+// boundaryID gets the lowest or the highest item ID, 0 for an empty list
+func (it *WindowIterator) boundaryID(lowest bool) (int, error) {
+	items := NewItems(it.items.client, it.items.endpoint, it.items.config)
+	items.modifiers.AddSelect("Id").AddOrderBy("Id", lowest).AddTop(1)
+	data, err := items.Get()
+	if err != nil {
+		return 0, err
+	}
+	collection, _ := normalizeODataCollection(data)
+	if len(collection) == 0 {
+		return 0, nil
+	}
+	id := itemID(collection[0])
+	if id == 0 {
+		return 0, fmt.Errorf("unable to get items ID range: unexpected response %s", collection[0])
+	}
+	return id, nil
+}
+
+// fetch gets all items of the window starting from the ID
+func (it *WindowIterator) fetch(from int) *windowResult {
+	res := &windowResult{from: from}
+	items := NewItems(it.items.client, it.items.endpoint, it.items.config)
+	mods := it.items.modifiers.Get()
+	if sel, ok := mods["$select"]; ok {
+		// ID is required to track the iteration position
+		items.modifiers.AddSelect(sel + ",Id")
+	}
+	if exp, ok := mods["$expand"]; ok {
+		items.modifiers.AddExpand(exp)
+	}
+	filter := odata.And(
+		odata.Field("Id").Ge(from),
+		odata.Field("Id").Lt(from+it.options.Size),
+		odata.Raw(mods["$filter"]),
+	)
+	items.modifiers.AddFilter(filter.String()).AddOrderBy("Id", true).AddTop(it.options.Size)
+
+	pages := items.Iterator()
+	for pages.NextPage() {
+		res.items = append(res.items, pages.Page()...)
+	}
+	res.err = pages.Err()
+	return res
+}
+
+// itemID gets item ID from normalized payload
+func itemID(item []byte) int {
+	resp := ItemResp(item)
+	return resp.Data().ID
+}
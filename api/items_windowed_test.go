@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestWindowedItems(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	if _, err := srv.AddList("Tasks", 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.AddField("Tasks", "Priority", "Number"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 20; i++ {
+		if _, err := srv.AddItem("Tasks", map[string]interface{}{"Title": fmt.Sprintf("Task %d", i), "Priority": i % 4}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for mode := range mockModes {
+		t.Run(mode, func(t *testing.T) {
+			items := newMockSP(t, srv, mode).Web().Lists().GetByTitle("Tasks").Items().
+				Select("Title").
+				Filter("Priority eq 0 or Priority eq 1")
+
+			it := items.Windowed(&WindowOptions{Size: 3, Concurrency: 3})
+			var ids []int
+			for it.Next() {
+				item := ItemResp(it.Item())
+				ids = append(ids, item.Data().ID)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+			expected := "[1 4 5 8 9 12 13 16 17 20]"
+			if fmt.Sprintf("%v", ids) != expected {
+				t.Errorf("expected %s, got %v", expected, ids)
+			}
+			if it.LastID() != 20 {
+				t.Errorf("unexpected last ID: %d", it.LastID())
+			}
+
+			resumed, err := items.Windowed(&WindowOptions{Size: 5, StartID: 10}).GetAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resumed) != 5 {
+				t.Errorf("expected 5 items, got %d", len(resumed))
+			}
+
+			empty, err := items.Filter("Priority eq 10").Windowed(nil).GetAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(empty) != 0 {
+				t.Errorf("expected no items, got %d", len(empty))
+			}
+		})
+	}
+}
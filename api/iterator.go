@@ -0,0 +1,230 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/koltyakov/gosip"
+)
+
+// Iterator streams OData collection pages following next page links (`__next`, `odata.nextLink`).
+// SharePoint returns next page links only for list items, other collections (lists, webs, users, etc.)
+// are paged by the iterator itself with `$skip` when `$top` is set, see NewSkipIterator.
+// Only the current page and, with prefetch enabled, the next one are kept in memory.
+//
+//	it := sp.Web().GetList("Lists/MyList").Items().Top(1000).Iterator()
+//	for it.Next() {
+//		item := api.ItemResp(it.Item())
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// it.PageURL() can be persisted and passed to Resume to continue from the failed page
+//	}
+//
+// Use either Next/Item or NextPage/Page pair on the same iterator, not both.
+type Iterator struct {
+	client   *gosip.SPClient
+	config   *RequestConfig
+	startURL string
+	prefetch bool
+	skipPage bool // next page URL is built with $skip when the response has no next page link
+
+	started bool
+	pageURL string   // URL of the current page
+	nextURL string   // URL of the next page, empty for the last page
+	page    [][]byte // current page items
+	pos     int      // current item position in the page
+	err     error
+	pending chan *iteratorPage // prefetched next page
+}
+
+// iteratorPage fetched collection page
+type iteratorPage struct {
+	url     string
+	items   [][]byte
+	nextURL string
+	err     error
+}
+
+// NewIterator - Iterator constructor function, pageURL is the first page URL with OData modifiers
+func NewIterator(client *gosip.SPClient, pageURL string, config *RequestConfig) *Iterator {
+	return &Iterator{
+		client:   client,
+		config:   config,
+		startURL: pageURL,
+	}
+}
+
+// NewSkipIterator - Iterator constructor function for collections which responses have no next page links,
+// pages are requested with `$skip` incremented by the page size, without `$top` the collection is a single page
+func NewSkipIterator(client *gosip.SPClient, pageURL string, config *RequestConfig) *Iterator {
+	it := NewIterator(client, pageURL, config)
+	it.skipPage = true
+	return it
+}
+
+// Prefetch enables fetching the next page concurrently while the current page is processed
+func (it *Iterator) Prefetch(enabled bool) *Iterator {
+	it.prefetch = enabled
+	return it
+}
+
+// Resume starts iteration from a page URL previously received from PageURL or NextPageURL
+func (it *Iterator) Resume(pageURL string) *Iterator {
+	if !it.started && pageURL != "" {
+		it.startURL = pageURL
+	}
+	return it
+}
+
+// Next advances to the next item, returns false when there are no more items or an error occurred
+func (it *Iterator) Next() bool {
+	for it.pos+1 >= len(it.page) {
+		if !it.NextPage() {
+			return false
+		}
+		if len(it.page) > 0 {
+			it.pos = 0
+			return true
+		}
+	}
+	it.pos++
+	return true
+}
+
+// Item gets current item normalized payload
+func (it *Iterator) Item() []byte {
+	if it.pos < 0 || it.pos >= len(it.page) {
+		return nil
+	}
+	return it.page[it.pos]
+}
+
+// NextPage advances to the next page, returns false when there are no more pages or an error occurred
+func (it *Iterator) NextPage() bool {
+	if it.err != nil {
+		return false
+	}
+	var p *iteratorPage
+	switch {
+	case !it.started:
+		it.started = true
+		p = it.fetch(it.startURL)
+	case it.nextURL == "":
+		it.page = nil
+		it.pos = -1
+		return false
+	case it.pending != nil:
+		p = <-it.pending
+		it.pending = nil
+	default:
+		p = it.fetch(it.nextURL)
+	}
+	if p.err != nil {
+		it.err = p.err
+		it.page = nil
+		it.pos = -1
+		// keep pointing to the failed page so it can be resumed
+		it.pageURL = p.url
+		it.nextURL = p.url
+		return false
+	}
+	it.pageURL = p.url
+	it.nextURL = p.nextURL
+	it.page = p.items
+	it.pos = -1
+	if it.prefetch && it.nextURL != "" {
+		it.pending = make(chan *iteratorPage, 1)
+		go func(pending chan *iteratorPage, nextURL string) {
+			pending <- it.fetch(nextURL)
+		}(it.pending, it.nextURL)
+	}
+	return true
+}
+
+// Page gets current page items normalized payloads
+func (it *Iterator) Page() [][]byte {
+	return it.page
+}
+
+// Err gets iteration error
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// PageURL gets current page URL, after an error it points to the page which failed to load
+func (it *Iterator) PageURL() string {
+	if !it.started {
+		return it.startURL
+	}
+	return it.pageURL
+}
+
+// NextPageURL gets next page URL, empty when the current page is the last one
+func (it *Iterator) NextPageURL() string {
+	if !it.started {
+		return it.startURL
+	}
+	return it.nextURL
+}
+
+// SkipToken gets next page `$skiptoken` value, empty for collections paged without skip tokens
+func (it *Iterator) SkipToken() string {
+	nextURL, err := url.Parse(it.NextPageURL())
+	if err != nil {
+		return ""
+	}
+	return nextURL.Query().Get("$skiptoken")
+}
+
+// ForEach calls the callback for each item, iteration stops on the first callback error
+func (it *Iterator) ForEach(callback func(item []byte) error) error {
+	for it.Next() {
+		if err := callback(it.Item()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// fetch gets and parses collection page
+func (it *Iterator) fetch(pageURL string) *iteratorPage {
+	p := &iteratorPage{url: pageURL}
+	if pageURL == "" {
+		p.err = fmt.Errorf("unable to get page: empty URL")
+		return p
+	}
+	client := NewHTTPClient(it.client)
+	data, err := client.Get(pageURL, it.config)
+	if err != nil {
+		p.err = err
+		return p
+	}
+	p.items, p.nextURL = normalizeODataCollection(data)
+	if p.nextURL == "" && it.skipPage {
+		p.nextURL = skipPageURL(pageURL, len(p.items))
+	}
+	if p.nextURL == pageURL {
+		p.err = fmt.Errorf("unable to get next page: next page URL points to the same page")
+	}
+	return p
+}
+
+// skipPageURL gets the next page URL by incrementing `$skip` with the page size,
+// empty when the page is not full or `$top` is not set, so the page is the last one
+func skipPageURL(pageURL string, pageSize int) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	query := u.Query()
+	top, _ := strconv.Atoi(query.Get("$top"))
+	if top <= 0 || pageSize < top {
+		return ""
+	}
+	skip, _ := strconv.Atoi(query.Get("$skip"))
+	query.Set("$skip", strconv.Itoa(skip+pageSize))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
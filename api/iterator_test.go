@@ -0,0 +1,102 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestIterator(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	if _, err := srv.AddList("Tasks", 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.AddField("Tasks", "Priority", "Number"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 7; i++ {
+		if _, err := srv.AddItem("Tasks", map[string]interface{}{"Title": fmt.Sprintf("Task %d", i), "Priority": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for mode := range mockModes {
+		t.Run(mode, func(t *testing.T) {
+			items := newMockSP(t, srv, mode).Web().Lists().GetByTitle("Tasks").Items().
+				Select("Id,Title").
+				Filter("Priority ge 2").
+				OrderBy("Priority", false).
+				Top(2)
+
+			it := items.Iterator().Prefetch(true)
+			var titles []string
+			for it.Next() {
+				item := ItemResp(it.Item())
+				titles = append(titles, item.Data().Title)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if len(titles) != 6 || titles[0] != "Task 7" || titles[5] != "Task 2" {
+				t.Errorf("unexpected items: %v", titles)
+			}
+
+			it = items.Iterator()
+			if !it.NextPage() || len(it.Page()) != 2 || it.SkipToken() == "" {
+				t.Fatalf("unexpected first page: %d items, skip token %q", len(it.Page()), it.SkipToken())
+			}
+			resumed := items.Iterator().Resume(it.NextPageURL())
+			count := 0
+			if err := resumed.ForEach(func(item []byte) error {
+				count++
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if count != 4 {
+				t.Errorf("expected 4 resumed items, got %d", count)
+			}
+
+			all, err := items.GetAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != 6 {
+				t.Errorf("expected 6 items, got %d", len(all))
+			}
+
+			lists := 0
+			listsIt := newMockSP(t, srv, mode).Web().Lists().Top(1).Iterator()
+			for listsIt.Next() {
+				lists++
+			}
+			if err := listsIt.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if lists != 2 {
+				t.Errorf("expected 2 lists, got %d", lists)
+			}
+		})
+	}
+}
+
+func TestSkipPageURL(t *testing.T) {
+	cases := []struct {
+		pageURL  string
+		size     int
+		expected string
+	}{
+		{"https://contoso/_api/web/lists?$top=2", 2, "https://contoso/_api/web/lists?%24skip=2&%24top=2"},
+		{"https://contoso/_api/web/lists?$skip=2&$top=2", 2, "https://contoso/_api/web/lists?%24skip=4&%24top=2"},
+		{"https://contoso/_api/web/lists?$top=2", 1, ""},
+		{"https://contoso/_api/web/lists", 100, ""},
+	}
+	for _, c := range cases {
+		if actual := skipPageURL(c.pageURL, c.size); actual != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.pageURL, c.expected, actual)
+		}
+	}
+}
@@ -271,9 +271,6 @@ func (list *List) RenderListData(viewXML string) (RenderListDataResp, error) {
 	return data, nil
 }
 
-// ToDo:
-// RenderListDataAsStream ...
-
 // Roles gets list's Roles API instance queryable collection
 func (list *List) Roles() *Roles {
 	return NewRoles(list.client, list.endpoint, list.config)
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RenderListData options flags (SP.RenderListDataOptions)
+const (
+	RenderOptionDefault          = 0
+	RenderOptionContextInfo      = 1
+	RenderOptionListData         = 2
+	RenderOptionListSchema       = 4
+	RenderOptionMenuView         = 8
+	RenderOptionListContentType  = 16
+	RenderOptionFileSystemItemID = 32
+	RenderOptionClientFormSchema = 64
+	RenderOptionQuickLaunch      = 128
+	RenderOptionSpotlight        = 256
+	RenderOptionVisualization    = 512
+	RenderOptionViewMetadata     = 1024
+)
+
+// RenderListDataParams RenderListDataAsStream method parameters (SP.RenderListDataParameters)
+type RenderListDataParams struct {
+	ViewXML                 string // CAML view, overrides the default view's query, fields and row limit
+	FolderServerRelativeURL string // folder to render, the root folder by default
+	RenderOptions           int    // RenderOption flags, RenderOptionListData by default
+	DatesInUtc              bool   // render dates in UTC instead of the web's time zone
+	AddRequiredFields       bool
+	ExpandGroups            bool
+	Paging                  string // page continuation, NextHref value of the previous page
+
+	// OverrideParameters optional SP.RenderListDataOverrideParameters, e.g. {"ViewId": "..."}
+	OverrideParameters map[string]interface{}
+}
+
+// RenderListDataStreamInfo RenderListDataAsStream method response page
+type RenderListDataStreamInfo struct {
+	Row                    []map[string]interface{} `json:"Row"`
+	FirstRow               int                      `json:"FirstRow"`
+	LastRow                int                      `json:"LastRow"`
+	RowLimit               int                      `json:"RowLimit"`
+	NextHref               string                   `json:"NextHref"`
+	PrevHref               string                   `json:"PrevHref"`
+	FolderPermissions      string                   `json:"FolderPermissions"`
+	FilterLink             string                   `json:"FilterLink"`
+	ForceNoHierarchy       string                   `json:"ForceNoHierarchy"`
+	HierarchyHasIndention  string                   `json:"HierarchyHasIndention"`
+	CurrentFolderSpItemURL string                   `json:"CurrentFolderSpItemUrl"`
+}
+
+// RenderListDataStreamResp - renderListDataAsStream method response type with helper processor methods
+type RenderListDataStreamResp []byte
+
+// streamDateFormats dates formats of list data stream, localized dates are parsed in en-US format
+var streamDateFormats = append(append([]string{}, itemDateFormats...), "1/2/2006 3:04 PM", "1/2/2006 15:04", "1/2/2006")
+
+// RenderListDataAsStream renders lists content with RenderListDataAsStream method,
+// use Paging parameter with the previous page's NextHref to get the next page
+func (list *List) RenderListDataAsStream(params *RenderListDataParams) (RenderListDataStreamResp, error) {
+	if params == nil {
+		params = &RenderListDataParams{}
+	}
+	apiURL, _ := url.Parse(fmt.Sprintf("%s/RenderListDataAsStream", list.endpoint))
+	if params.Paging != "" {
+		// NextHref is a query string, e.g. `?Paged=TRUE&p_ID=30&PageFirstRow=31&View=...`
+		paging, err := url.ParseQuery(strings.TrimPrefix(params.Paging, "?"))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse paging: %w", err)
+		}
+		query := apiURL.Query()
+		for key, values := range paging {
+			for _, v := range values {
+				query.Add(key, v)
+			}
+		}
+		apiURL.RawQuery = query.Encode()
+	}
+
+	renderOptions := params.RenderOptions
+	if renderOptions == RenderOptionDefault {
+		renderOptions = RenderOptionListData
+	}
+	parameters := map[string]interface{}{
+		"__metadata":        map[string]string{"type": "SP.RenderListDataParameters"},
+		"RenderOptions":     renderOptions,
+		"DatesInUtc":        params.DatesInUtc,
+		"AddRequiredFields": params.AddRequiredFields,
+		"ExpandGroups":      params.ExpandGroups,
+	}
+	if params.ViewXML != "" {
+		parameters["ViewXml"] = TrimMultiline(params.ViewXML)
+	}
+	if params.FolderServerRelativeURL != "" {
+		parameters["FolderServerRelativeUrl"] = params.FolderServerRelativeURL
+	}
+	payload := map[string]interface{}{"parameters": parameters}
+	if params.OverrideParameters != nil {
+		overrides := map[string]interface{}{
+			"__metadata": map[string]string{"type": "SP.RenderListDataOverrideParameters"},
+		}
+		for key, val := range params.OverrideParameters {
+			overrides[key] = val
+		}
+		payload["overrideParameters"] = overrides
+	}
+	body, _ := json.Marshal(payload)
+
+	client := NewHTTPClient(list.client)
+	return client.Post(apiURL.String(), bytes.NewBuffer(body), list.config)
+}
+
+/* Response helpers */
+
+// Data response helper
+func (streamResp *RenderListDataStreamResp) Data() *RenderListDataStreamInfo {
+	res := &RenderListDataStreamInfo{}
+	data := []byte(*streamResp)
+	// With context info render options list data is nested
+	wrapper := &struct {
+		ListData json.RawMessage `json:"ListData"`
+	}{}
+	if err := json.Unmarshal(data, &wrapper); err == nil && len(wrapper.ListData) > 0 {
+		data = wrapper.ListData
+	}
+	_ = json.Unmarshal(data, &res)
+	return res
+}
+
+// HasNextPage returns is true if next page exists
+func (streamResp *RenderListDataStreamResp) HasNextPage() bool {
+	return streamResp.Data().NextHref != ""
+}
+
+// Unmarshal decodes stream rows to a pointer to slice of structs with `sp:"InternalName"` tags,
+// rows rendering of lookups, users, taxonomy, hyperlinks, numbers, booleans and dates is converted to the struct types.
+// Dates without time zone are treated as UTC, use UnmarshalIn for rows rendered without DatesInUtc
+func (streamResp *RenderListDataStreamResp) Unmarshal(v interface{}) error {
+	return streamResp.UnmarshalIn(v, time.UTC)
+}
+
+// UnmarshalIn decodes stream rows the same way as Unmarshal, dates without time zone
+// are parsed in the provided location, which should be the web's time zone when DatesInUtc is false
+func (streamResp *RenderListDataStreamResp) UnmarshalIn(v interface{}, loc *time.Location) error {
+	if loc == nil {
+		loc = time.UTC
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("can't unmarshal rows to %T, a pointer to slice is expected", v)
+	}
+	elemType := rv.Elem().Type().Elem()
+	if derefType(elemType).Kind() != reflect.Struct {
+		return fmt.Errorf("can't unmarshal rows to %T, a slice of structs is expected", v)
+	}
+	fields, err := getItemFields(derefType(elemType))
+	if err != nil {
+		return err
+	}
+	rows := streamResp.Data().Row
+	slice := reflect.MakeSlice(rv.Elem().Type(), 0, len(rows))
+	for _, row := range rows {
+		props, err := normalizeStreamRow(row, fields, loc)
+		if err != nil {
+			return err
+		}
+		elem := reflect.New(derefType(elemType)).Elem()
+		if err := decodeItem(props, elem); err != nil {
+			return err
+		}
+		if elemType.Kind() == reflect.Ptr {
+			elem = elem.Addr()
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	rv.Elem().Set(slice)
+	return nil
+}
+
+// normalizeStreamRow converts stream row values to REST API item properties shape,
+// dates without time zone are parsed in loc
+func normalizeStreamRow(row map[string]interface{}, fields []*itemField, loc *time.Location) (map[string]interface{}, error) {
+	props := map[string]interface{}{}
+	for _, f := range fields {
+		raw, ok := row[f.name]
+		if !ok {
+			continue
+		}
+		if s, ok := raw.(string); ok && s == "" {
+			raw = nil
+		}
+		elemType := derefType(sliceElem(f.typ))
+		isSlice := f.typ.Kind() == reflect.Slice && f.typ.Elem().Kind() != reflect.Uint8
+		var value interface{}
+		switch {
+		case raw == nil:
+		case elemType == lookupType || elemType == userType || elemType == taxonomyType:
+			values, ok := raw.([]interface{})
+			if !ok {
+				values = []interface{}{raw}
+			}
+			var results []interface{}
+			for _, val := range values {
+				m, _ := val.(map[string]interface{})
+				switch elemType {
+				case lookupType:
+					results = append(results, map[string]interface{}{"Id": m["lookupId"], f.show: m["lookupValue"]})
+				case userType:
+					results = append(results, map[string]interface{}{
+						"Id": m["id"], "Title": m["title"], "EMail": m["email"], "Name": firstOf(m, "sip", "loginName"),
+					})
+				case taxonomyType:
+					results = append(results, map[string]interface{}{
+						"Label": m["Label"], "TermGuid": m["TermID"], "WssId": m["WssId"],
+					})
+				}
+			}
+			if isSlice {
+				value = results
+			} else if len(results) > 0 {
+				value = results[0]
+			}
+		case elemType == urlValueType:
+			value = map[string]interface{}{"Url": raw, "Description": row[f.name+".desc"]}
+		case elemType == timeType:
+			// `Field.` holds not localized value
+			if invariant, ok := row[f.name+"."]; ok && invariant != "" {
+				raw = invariant
+			}
+			d, err := parseStreamDate(toString(raw), loc)
+			if err != nil {
+				return nil, fmt.Errorf("can't decode %s: %w", f.name, err)
+			}
+			value = d.Format(time.RFC3339)
+		default:
+			switch derefType(elemType).Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+				reflect.Float32, reflect.Float64:
+				// `Field.` holds not localized value
+				if invariant, ok := row[f.name+"."]; ok {
+					raw = invariant
+				} else if s, ok := raw.(string); ok {
+					raw = parseStreamNumber(s)
+				}
+			case reflect.Bool:
+				if val, ok := row[f.name+".value"]; ok {
+					raw = val
+				}
+			case reflect.String:
+				if values, ok := raw.([]interface{}); ok && !isSlice {
+					strs := make([]string, len(values))
+					for i, val := range values {
+						strs[i] = toString(val)
+					}
+					raw = strings.Join(strs, ", ")
+				}
+			}
+			if s, ok := raw.(string); ok && isSlice && strings.HasPrefix(s, ";#") {
+				// Multi choice values are rendered as `;#One;#Two;#`
+				var values []interface{}
+				for _, val := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(s, ";#"), ";#"), ";#") {
+					values = append(values, val)
+				}
+				raw = values
+			}
+			value = raw
+		}
+		props[f.name] = value
+	}
+	return props, nil
+}
+
+// parseStreamDate parses stream date, values without time zone are parsed in loc
+func parseStreamDate(value string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range streamDateFormats {
+		if d, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("can't parse date %s", value)
+}
+
+// parseStreamNumber converts localized number to invariant format,
+// the last of `,` and `.` separators is the decimal one when both are present,
+// a single comma is a decimal separator unless it's followed by three digits group
+func parseStreamNumber(value string) string {
+	value = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", "'", "").Replace(value)
+	comma, dot := strings.LastIndex(value, ","), strings.LastIndex(value, ".")
+	switch {
+	case comma == -1:
+		return value
+	case dot > comma:
+		return strings.Replace(value, ",", "", -1)
+	case dot != -1:
+		return strings.Replace(strings.Replace(value, ".", "", -1), ",", ".", 1)
+	case strings.Count(value, ",") == 1 && len(value)-comma-1 != 3:
+		return strings.Replace(value, ",", ".", 1)
+	}
+	return strings.Replace(value, ",", "", -1)
+}
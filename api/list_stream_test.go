@@ -0,0 +1,149 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/koltyakov/gosip/caml"
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestRenderListDataStreamUnmarshal(t *testing.T) {
+	type row struct {
+		ID       int                  `sp:"ID"`
+		Title    string               `sp:"Title"`
+		Amount   float64              `sp:"Amount"`
+		Done     bool                 `sp:"Done"`
+		Due      time.Time            `sp:"DueDate"`
+		Closed   *time.Time           `sp:"ClosedDate"`
+		Category FieldLookupValue     `sp:"Category"`
+		Tags     []FieldLookupValue   `sp:"Tags"`
+		Manager  FieldUserValue       `sp:"Manager"`
+		Choices  []string             `sp:"Choices"`
+		Link     FieldURLValue        `sp:"Link"`
+		Term     FieldTaxonomyValue   `sp:"Term"`
+		Terms    []FieldTaxonomyValue `sp:"Terms"`
+		Calc     string               `sp:"Calc"`
+		Modified time.Time            `sp:"Modified"`
+	}
+
+	resp := RenderListDataStreamResp(`{
+		"Row": [{
+			"ID": "1", "Title": "Task",
+			"Amount": "1,234.50", "Amount.": "1234.5",
+			"Done": "Yes", "Done.value": "1",
+			"DueDate": "1/2/2020 3:04 AM", "DueDate.": "2020-01-02T03:04:05Z",
+			"ClosedDate": "",
+			"Category": [{"lookupId": 2, "lookupValue": "Cat", "isSecretFieldValue": false}],
+			"Tags": [{"lookupId": 3, "lookupValue": "A"}, {"lookupId": 4, "lookupValue": "B"}],
+			"Manager": [{"id": "5", "title": "John", "email": "john@contoso.com", "sip": "john@contoso.com"}],
+			"Choices": ";#One;#Two;#",
+			"Link": "https://contoso.com", "Link.desc": "Contoso",
+			"Term": {"__type": "TaxonomyFieldValue:#Microsoft.SharePoint.Taxonomy", "Label": "Term", "TermID": "a-b"},
+			"Terms": [{"Label": "T1", "TermID": "c-d"}, {"Label": "T2", "TermID": "e-f"}],
+			"Calc": "float;#42",
+			"Modified": "2/3/2020 4:05 PM"
+		}],
+		"FirstRow": 1, "LastRow": 1, "RowLimit": 30,
+		"NextHref": "?Paged=TRUE&p_ID=1&PageFirstRow=2"
+	}`)
+
+	if !resp.HasNextPage() {
+		t.Error("next page is expected")
+	}
+	var rows []*row
+	if err := resp.Unmarshal(&rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(rows))
+	}
+	r := rows[0]
+	if r.ID != 1 || r.Title != "Task" || r.Amount != 1234.5 || !r.Done || r.Calc != "float;#42" {
+		t.Errorf("unexpected plain fields: %+v", r)
+	}
+	if !r.Due.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) || r.Closed != nil {
+		t.Errorf("unexpected dates: %v, %v", r.Due, r.Closed)
+	}
+	if !r.Modified.Equal(time.Date(2020, 2, 3, 16, 5, 0, 0, time.UTC)) {
+		t.Errorf("unexpected localized date: %v", r.Modified)
+	}
+	if r.Category != (FieldLookupValue{ID: 2, Value: "Cat"}) || len(r.Tags) != 2 || r.Tags[1].Value != "B" {
+		t.Errorf("unexpected lookups: %+v, %+v", r.Category, r.Tags)
+	}
+	if r.Manager.ID != 5 || r.Manager.Title != "John" || r.Manager.Email != "john@contoso.com" {
+		t.Errorf("unexpected user: %+v", r.Manager)
+	}
+	if len(r.Choices) != 2 || r.Choices[1] != "Two" {
+		t.Errorf("unexpected choices: %v", r.Choices)
+	}
+	if r.Link != (FieldURLValue{URL: "https://contoso.com", Description: "Contoso"}) {
+		t.Errorf("unexpected URL: %+v", r.Link)
+	}
+	if r.Term.Label != "Term" || r.Term.TermGUID != "a-b" || len(r.Terms) != 2 || r.Terms[1].TermGUID != "e-f" {
+		t.Errorf("unexpected taxonomy: %+v, %+v", r.Term, r.Terms)
+	}
+
+	wrapped := RenderListDataStreamResp(`{"ListData": {"Row": [{"ID": "7"}], "LastRow": 1}, "ListSchema": {}}`)
+	if data := wrapped.Data(); len(data.Row) != 1 || data.LastRow != 1 {
+		t.Errorf("unexpected wrapped list data: %+v", data)
+	}
+}
+
+func TestRenderListDataAsStream(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	if _, err := srv.AddList("Tasks", 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.AddField("Tasks", "Priority", "Number"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 5; i++ {
+		if _, err := srv.AddItem("Tasks", map[string]interface{}{"Title": fmt.Sprintf("Task %d", i), "Priority": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type row struct {
+		ID       int            `sp:"ID"`
+		Title    string         `sp:"Title"`
+		Priority float64        `sp:"Priority"`
+		Author   FieldUserValue `sp:"Author"`
+		Created  time.Time      `sp:"Created"`
+	}
+
+	list := newMockSP(t, srv, "Verbose").Web().Lists().GetByTitle("Tasks")
+	view := caml.NewView().Fields("Title", "Priority", "Author", "Created").RowLimit(2, true)
+	params := &RenderListDataParams{ViewXML: view.String(), DatesInUtc: true}
+
+	var rows []row
+	for page := 0; ; page++ {
+		if page > 5 {
+			t.Fatal("paging doesn't stop")
+		}
+		resp, err := list.RenderListDataAsStream(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pageRows []row
+		if err := resp.Unmarshal(&pageRows); err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, pageRows...)
+		if !resp.HasNextPage() {
+			break
+		}
+		params.Paging = resp.Data().NextHref
+	}
+
+	if len(rows) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(rows))
+	}
+	last := rows[4]
+	if last.ID != 5 || last.Title != "Task 5" || last.Priority != 5 || last.Author.Title != "Mock Admin" || last.Created.IsZero() {
+		t.Errorf("unexpected row: %+v", last)
+	}
+}
@@ -43,6 +43,11 @@ func (lists *Lists) Get() (ListsResp, error) {
 	return client.Get(lists.ToURL(), lists.config)
 }
 
+// Iterator gets lists collection pages iterator, page size is controlled with Top modifier
+func (lists *Lists) Iterator() *Iterator {
+	return NewSkipIterator(lists.client, lists.ToURL(), lists.config)
+}
+
 // GetByTitle gets a list by its Display Name (Title)
 func (lists *Lists) GetByTitle(listTitle string) *List {
 	list := NewList(
@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (lists *Lists) Conf(config *RequestConfig) *Lists {
 	lists.config = config
@@ -26,6 +30,12 @@ func (lists *Lists) Filter(oDataFilter string) *Lists {
 	return lists
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (lists *Lists) FilterExpr(oDataFilter odata.Expr) *Lists {
+	lists.modifiers.AddFilter(oDataFilter.String())
+	return lists
+}
+
 // Top adds $top OData modifier
 func (lists *Lists) Top(oDataTop int) *Lists {
 	lists.modifiers.AddTop(oDataTop)
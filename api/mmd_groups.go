@@ -42,10 +42,11 @@ func (termGroups *TermGroups) GetByID(groupGUID string) *TermGroup {
 // Add creates new group
 func (termGroups *TermGroups) Add(name string, guid string) (map[string]interface{}, error) {
 	b := termGroups.csomEntry.Clone()
-	b.AddObject(csom.NewObjectMethod("CreateGroup", []string{
-		fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, name),
-		fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, guid),
-	}), nil)
+	b.AddObject(csom.NewObjectMethodWithParams(
+		"CreateGroup",
+		csom.NewParamString(name),
+		csom.NewParamString(guid),
+	), nil)
 	b.AddAction(csom.NewQueryWithProps([]string{}), nil)
 	return csomResponse(termGroups.client, termGroups.endpoint, termGroups.config, b)
 }
@@ -68,9 +69,7 @@ type TermGroup struct {
 // csomBuilderEntry gets CSOM builder entry
 func (termGroup *TermGroup) csomBuilderEntry() csom.Builder {
 	b := termGroup.csomEntry.Clone()
-	b.AddObject(csom.NewObjectMethod("GetGroup", []string{
-		fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, termGroup.id),
-	}), nil)
+	b.AddObject(csom.NewObjectMethodWithParams("GetGroup", csom.NewParamString(termGroup.id)), nil)
 	return b
 }
 
@@ -101,7 +100,7 @@ func (termGroup *TermGroup) Get() (map[string]interface{}, error) {
 func (termGroup *TermGroup) Delete() error {
 	b := termGroup.csomBuilderEntry().Clone()
 	b.AddAction(csom.NewActionMethod("DeleteObject", []string{}), nil)
-	_, err := csomResponse(termGroup.client, termGroup.endpoint, termGroup.config, b)
+	_, err := csomProcess(termGroup.client, termGroup.endpoint, termGroup.config, b)
 	return err
 }
 
@@ -57,10 +57,11 @@ func (termSets *TermSets) GetByName(termSetName string, lcid int) ([]map[string]
 		b.AddObject(csom.NewObject(objs[3].Template()), nil) // GetById or GetByName
 	}
 
-	b.AddObject(csom.NewObjectMethod("GetTermSetsByName", []string{
-		fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, termSetName),
-		fmt.Sprintf(`<Parameter Type="Number">%d</Parameter>`, lcid),
-	}), nil)
+	b.AddObject(csom.NewObjectMethodWithParams(
+		"GetTermSetsByName",
+		csom.NewParamString(termSetName),
+		csom.NewParamInt32(int32(lcid)),
+	), nil)
 	b.AddAction(csom.NewQueryWithChildProps([]string{}), nil)
 	return csomRespChildItems(termSets.client, termSets.endpoint, termSets.config, b)
 }
@@ -68,11 +69,12 @@ func (termSets *TermSets) GetByName(termSetName string, lcid int) ([]map[string]
 // Add creates new term set
 func (termSets *TermSets) Add(name string, guid string, lcid int) (map[string]interface{}, error) {
 	b := termSets.csomBuilderEntry().Clone()
-	b.AddObject(csom.NewObjectMethod("CreateTermSet", []string{
-		fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, name),
-		fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, guid),
-		fmt.Sprintf(`<Parameter Type="Number">%d</Parameter>`, lcid),
-	}), nil)
+	b.AddObject(csom.NewObjectMethodWithParams(
+		"CreateTermSet",
+		csom.NewParamString(name),
+		csom.NewParamString(guid),
+		csom.NewParamInt32(int32(lcid)),
+	), nil)
 	b.AddAction(csom.NewQueryWithProps([]string{}), nil)
 	return csomResponse(termSets.client, termSets.endpoint, termSets.config, b)
 }
@@ -94,9 +96,7 @@ type TermSet struct {
 // csomBuilderEntry gets CSOM builder entry
 func (termSet *TermSet) csomBuilderEntry() csom.Builder {
 	b := termSet.csomEntry.Clone()
-	b.AddObject(csom.NewObjectMethod("GetTermSet", []string{
-		fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, termSet.id),
-	}), nil)
+	b.AddObject(csom.NewObjectMethodWithParams("GetTermSet", csom.NewParamString(termSet.id)), nil)
 	return b
 }
 
@@ -127,7 +127,7 @@ func (termSet *TermSet) Get() (map[string]interface{}, error) {
 func (termSet *TermSet) Delete() error {
 	b := termSet.csomBuilderEntry().Clone()
 	b.AddAction(csom.NewActionMethod("DeleteObject", []string{}), nil)
-	_, err := csomResponse(termSet.client, termSet.endpoint, termSet.config, b)
+	_, err := csomProcess(termSet.client, termSet.endpoint, termSet.config, b)
 	return err
 }
 
@@ -77,15 +77,11 @@ func (termStore *TermStore) csomBuilderEntry() csom.Builder {
 	if len(termStore.id) > 0 {
 		// Term store by ID
 		b.AddObject(csom.NewObjectProperty("TermStores"), nil)
-		b.AddObject(csom.NewObjectMethod("GetById", []string{
-			fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, termStore.id),
-		}), nil)
+		b.AddObject(csom.NewObjectMethodWithParams("GetById", csom.NewParamString(termStore.id)), nil)
 	} else if len(termStore.name) > 0 {
 		// Term store by Name
 		b.AddObject(csom.NewObjectProperty("TermStores"), nil)
-		b.AddObject(csom.NewObjectMethod("GetByName", []string{
-			fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, termStore.name),
-		}), nil)
+		b.AddObject(csom.NewObjectMethodWithParams("GetByName", csom.NewParamString(termStore.name)), nil)
 	} else {
 		// Default term store
 		b.AddObject(csom.NewObjectMethod("GetDefaultSiteCollectionTermStore", []string{}), nil)
@@ -128,7 +124,7 @@ func (termStore *TermStore) Get() (map[string]interface{}, error) {
 func (termStore *TermStore) UpdateCache() error {
 	b := termStore.csomBuilderEntry().Clone()
 	b.AddAction(csom.NewActionMethod("UpdateCache", []string{}), nil)
-	_, err := csomResponse(termStore.client, termStore.endpoint, termStore.config, b)
+	_, err := csomProcess(termStore.client, termStore.endpoint, termStore.config, b)
 	return err
 }
 
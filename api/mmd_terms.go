@@ -54,11 +54,12 @@ func (terms *Terms) GetByID(termGUID string) *Term {
 // Add creates new term
 func (terms *Terms) Add(name string, guid string, lcid int) (map[string]interface{}, error) {
 	b := terms.csomBuilderEntry().Clone()
-	b.AddObject(csom.NewObjectMethod("CreateTerm", []string{
-		fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, name),
-		fmt.Sprintf(`<Parameter Type="Number">%d</Parameter>`, lcid),
-		fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, guid),
-	}), nil)
+	b.AddObject(csom.NewObjectMethodWithParams(
+		"CreateTerm",
+		csom.NewParamString(name),
+		csom.NewParamInt32(int32(lcid)),
+		csom.NewParamString(guid),
+	), nil)
 	b.AddAction(csom.NewQueryWithProps([]string{}), nil)
 	return csomResponse(terms.client, terms.endpoint, terms.config, b)
 }
@@ -81,9 +82,7 @@ type Term struct {
 // csomBuilderEntry gets CSOM builder entry
 func (term *Term) csomBuilderEntry() csom.Builder {
 	b := term.csomEntry.Clone()
-	b.AddObject(csom.NewObjectMethod("GetTerm", []string{
-		fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, term.id),
-	}), nil)
+	b.AddObject(csom.NewObjectMethodWithParams("GetTerm", csom.NewParamString(term.id)), nil)
 	return b
 }
 
@@ -117,11 +116,11 @@ func (term *Term) Update(properties map[string]interface{}) (map[string]interfac
 	termObject := objects[len(objects)-1]
 	// var scalarProperties []string
 	for prop, value := range properties {
-		valueXML := fmt.Sprintf("%s", value)
 		if strings.Index(prop, "<") == -1 {
-			valueXML = fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, value)
+			b.AddAction(csom.NewSetPropertyWithParam(prop, csom.NewParamString(fmt.Sprintf("%s", value))), termObject)
+			continue
 		}
-		b.AddAction(csom.NewSetProperty(prop, valueXML), termObject)
+		b.AddAction(csom.NewSetProperty(prop, fmt.Sprintf("%s", value)), termObject)
 		// scalarProperties = append(scalarProperties, fmt.Sprintf(`<Property Name="%s" ScalarProperty="true" />`, prop))
 	}
 	b.AddAction(csom.NewQueryWithProps([]string{}), termObject) // scalarProperties
@@ -132,17 +131,15 @@ func (term *Term) Update(properties map[string]interface{}) (map[string]interfac
 func (term *Term) Delete() error {
 	b := term.csomBuilderEntry().Clone()
 	b.AddAction(csom.NewActionMethod("DeleteObject", []string{}), nil)
-	_, err := csomResponse(term.client, term.endpoint, term.config, b)
+	_, err := csomProcess(term.client, term.endpoint, term.config, b)
 	return err
 }
 
 // Deprecate deprecates/activates a term
 func (term *Term) Deprecate(deprecate bool) error {
 	b := term.csomBuilderEntry().Clone()
-	b.AddAction(csom.NewActionMethod("Deprecate", []string{
-		fmt.Sprintf(`<Parameter Type="Boolean">%t</Parameter>`, deprecate),
-	}), nil)
-	_, err := csomResponse(term.client, term.endpoint, term.config, b)
+	b.AddAction(csom.NewActionMethodWithParams("Deprecate", csom.NewParamBool(deprecate)), nil)
+	_, err := csomProcess(term.client, term.endpoint, term.config, b)
 	return err
 }
 
@@ -158,23 +155,15 @@ func (term *Term) Move(termSetGUID string, termGUID string) error {
 	storeObj := objs[2] // 3rd object is always term store
 	childTermObj := objs[len(objs)-1]
 
-	parentObj, _ := b.AddObject(csom.NewObjectMethod("GetTermSet", []string{
-		fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, termSetGUID),
-	}), storeObj)
+	parentObj, _ := b.AddObject(csom.NewObjectMethodWithParams("GetTermSet", csom.NewParamString(termSetGUID)), storeObj)
 
 	if len(termGUID) > 0 {
-		parentObj, _ = b.AddObject(csom.NewObjectMethod("GetTerm", []string{
-			fmt.Sprintf(`<Parameter Type="String">%s</Parameter>`, termGUID),
-		}), storeObj)
+		parentObj, _ = b.AddObject(csom.NewObjectMethodWithParams("GetTerm", csom.NewParamString(termGUID)), storeObj)
 	}
 
-	_, _ = b.Compile() // generate ID numbers
-
-	b.AddAction(csom.NewActionMethod("Move", []string{
-		fmt.Sprintf(`<Parameter ObjectPathId="%d" />`, parentObj.GetID()),
-	}), childTermObj)
+	b.AddAction(csom.NewActionMethodWithParams("Move", csom.NewParamObjectPath(parentObj)), childTermObj)
 
-	_, err := csomResponse(term.client, term.endpoint, term.config, b)
+	_, err := csomProcess(term.client, term.endpoint, term.config, b)
 	return err
 }
 
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/koltyakov/gosip/test/spmock"
 )
 
 func TestTaxonomyStores(t *testing.T) {
@@ -579,3 +581,36 @@ func getTermID(taxonomy *Taxonomy) (string, error) {
 
 	return termGUID, nil
 }
+
+func TestTaxonomyVoidMethods(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	// Void methods get no result entry for the last action ID
+	srv.ProcessQuery = func(body []byte) ([]byte, error) {
+		return json.Marshal([]interface{}{
+			map[string]interface{}{"SchemaVersion": "15.0.0.0", "LibraryVersion": "16.0.0.0", "ErrorInfo": nil},
+		})
+	}
+
+	store := NewSP(srv.Client()).Taxonomy().Stores().Default()
+	termGUID := uuid.New().String()
+	term := store.Terms().GetByID(termGUID)
+	checks := map[string]func() error{
+		"Term.Delete":           term.Delete,
+		"Term.Deprecate":        func() error { return term.Deprecate(true) },
+		"Term.Move":             func() error { return term.Move(uuid.New().String(), "") },
+		"TermGroup.Delete":      store.Groups().GetByID(uuid.New().String()).Delete,
+		"TermSet.Delete":        store.Sets().GetByID(uuid.New().String()).Delete,
+		"TermStore.UpdateCache": store.UpdateCache,
+	}
+	for name, check := range checks {
+		if err := check(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	if _, err := term.Get(); err == nil {
+		t.Error("missing result should fail for methods with return value")
+	}
+}
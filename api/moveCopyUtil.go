@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/koltyakov/gosip"
+)
+
+// MoveCopyUtil represents SharePoint MoveCopyUtil API object struct, its methods copy and move files and folders
+// across site collections and return when the operation is completed
+// Always use NewMoveCopyUtil constructor instead of &MoveCopyUtil{}
+type MoveCopyUtil struct {
+	client   *gosip.SPClient
+	config   *RequestConfig
+	endpoint string
+}
+
+// MoveCopyOptions provides optional settings for MoveCopyUtil methods
+type MoveCopyOptions struct {
+	KeepBoth                      bool // copy or move with a new name when the destination exists
+	ResetAuthorAndCreatedOnCopy   bool // the copy gets the current user as the author
+	ShouldBypassSharedLocks       bool // copy or move files locked for co-authoring
+	RetainEditorAndModifiedOnMove bool // keep the editor and modification date of the moved items
+}
+
+// NewMoveCopyUtil - MoveCopyUtil struct constructor function
+func NewMoveCopyUtil(client *gosip.SPClient, endpoint string, config *RequestConfig) *MoveCopyUtil {
+	return &MoveCopyUtil{
+		client:   client,
+		endpoint: endpoint,
+		config:   config,
+	}
+}
+
+// CopyFile copies a file, the source and destination URLs are absolute, server relative or web relative file URLs
+func (util *MoveCopyUtil) CopyFile(srcURL string, destURL string, overwrite bool, options *MoveCopyOptions) ([]byte, error) {
+	return util.call("CopyFileByPath", srcURL, destURL, &overwrite, options)
+}
+
+// MoveFile moves a file, the source and destination URLs are absolute, server relative or web relative file URLs
+func (util *MoveCopyUtil) MoveFile(srcURL string, destURL string, overwrite bool, options *MoveCopyOptions) ([]byte, error) {
+	return util.call("MoveFileByPath", srcURL, destURL, &overwrite, options)
+}
+
+// CopyFolder copies a folder with its content, the destination URL is the new folder URL
+func (util *MoveCopyUtil) CopyFolder(srcURL string, destURL string, options *MoveCopyOptions) ([]byte, error) {
+	return util.call("CopyFolderByPath", srcURL, destURL, nil, options)
+}
+
+// MoveFolder moves a folder with its content, the destination URL is the new folder URL
+func (util *MoveCopyUtil) MoveFolder(srcURL string, destURL string, options *MoveCopyOptions) ([]byte, error) {
+	return util.call("MoveFolderByPath", srcURL, destURL, nil, options)
+}
+
+// call calls MoveCopyUtil method
+func (util *MoveCopyUtil) call(method string, srcURL string, destURL string, overwrite *bool, options *MoveCopyOptions) ([]byte, error) {
+	if options == nil {
+		options = &MoveCopyOptions{}
+	}
+	endpoint := fmt.Sprintf("%s/_api/SP.MoveCopyUtil.%s()", util.endpoint, method)
+	ctxURL := util.endpoint + "/_api"
+	payload := map[string]interface{}{
+		"srcPath": map[string]interface{}{
+			"__metadata": map[string]string{"type": "SP.ResourcePath"},
+			"DecodedUrl": absoluteURL(srcURL, ctxURL),
+		},
+		"destPath": map[string]interface{}{
+			"__metadata": map[string]string{"type": "SP.ResourcePath"},
+			"DecodedUrl": absoluteURL(destURL, ctxURL),
+		},
+		"options": map[string]interface{}{
+			"__metadata":                    map[string]string{"type": "SP.MoveCopyOptions"},
+			"KeepBoth":                      options.KeepBoth,
+			"ResetAuthorAndCreatedOnCopy":   options.ResetAuthorAndCreatedOnCopy,
+			"ShouldBypassSharedLocks":       options.ShouldBypassSharedLocks,
+			"RetainEditorAndModifiedOnMove": options.RetainEditorAndModifiedOnMove,
+		},
+	}
+	if overwrite != nil {
+		payload["overwrite"] = *overwrite
+	}
+	body, _ := json.Marshal(payload)
+
+	client := NewHTTPClient(util.client)
+	return client.Post(endpoint, bytes.NewBuffer(body), util.config)
+}
@@ -3,6 +3,10 @@ package api
 import (
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/koltyakov/gosip/odata"
+	"github.com/koltyakov/gosip/test/spmock"
 )
 
 func TestOData(t *testing.T) {
@@ -94,3 +98,32 @@ func TestOData(t *testing.T) {
 	})
 
 }
+
+func TestFilterExpressions(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	for _, title := range []string{"O'Reilly", "Other"} {
+		if _, err := srv.AddItem("Documents", map[string]interface{}{"Title": title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for mode := range mockModes {
+		t.Run(mode, func(t *testing.T) {
+			list := newMockSP(t, srv, mode).Web().Lists().GetByTitle("Documents")
+			filter := odata.And(
+				odata.Field("Title").Eq("O'Reilly"),
+				odata.Field("Created").Lt(time.Now().Add(time.Hour)),
+				odata.Field("Author").Nav("Title").StartsWith("Mock"),
+			)
+			items, err := list.Items().Select("Id,Title").FilterExpr(filter).Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(items.Data()) != 1 || items.Data()[0].Data().Title != "O'Reilly" {
+				t.Errorf("unexpected items: %s", items.Normalized())
+			}
+		})
+	}
+}
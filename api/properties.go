@@ -127,10 +127,11 @@ func (properties *Properties) setWebProps(props map[string]string) error {
 	b.AddObject(csom.NewObjectIdentity(identity), nil)
 	propsObj, _ := b.AddObject(csom.NewObjectProperty("AllProperties"), nil)
 	for key, val := range props {
-		b.AddAction(csom.NewActionMethod("SetFieldValue", []string{
-			`<Parameter Type="String">` + key + `</Parameter>`,
-			`<Parameter Type="String">` + val + `</Parameter>`,
-		}), propsObj)
+		b.AddAction(csom.NewActionMethodWithParams(
+			"SetFieldValue",
+			csom.NewParamString(key),
+			csom.NewParamString(val),
+		), propsObj)
 	}
 
 	csomPkg, err := b.Compile()
@@ -176,10 +177,11 @@ func (properties *Properties) setFolderProps(props map[string]string) error {
 	b.AddObject(csom.NewObjectIdentity(identity), nil)
 	propsObj, _ := b.AddObject(csom.NewObjectProperty("Properties"), nil)
 	for key, val := range props {
-		b.AddAction(csom.NewActionMethod("SetFieldValue", []string{
-			`<Parameter Type="String">` + key + `</Parameter>`,
-			`<Parameter Type="String">` + val + `</Parameter>`,
-		}), propsObj)
+		b.AddAction(csom.NewActionMethodWithParams(
+			"SetFieldValue",
+			csom.NewParamString(key),
+			csom.NewParamString(val),
+		), propsObj)
 	}
 
 	csomPkg, err := b.Compile()
@@ -205,13 +207,14 @@ func (properties *Properties) setFileProps(props map[string]string) error {
 
 	b := csom.NewBuilder()
 	b.AddObject(csom.NewObjectProperty("Web"), nil)
-	b.AddObject(csom.NewObjectMethod("GetFileById", []string{`<Parameter Type="String">` + fileR.Data().UniqueID + `</Parameter>`}), nil)
+	b.AddObject(csom.NewObjectMethodWithParams("GetFileById", csom.NewParamString(fileR.Data().UniqueID)), nil)
 	propsObj, _ := b.AddObject(csom.NewObjectProperty("Properties"), nil)
 	for key, val := range props {
-		b.AddAction(csom.NewActionMethod("SetFieldValue", []string{
-			`<Parameter Type="String">` + key + `</Parameter>`,
-			`<Parameter Type="String">` + val + `</Parameter>`,
-		}), propsObj)
+		b.AddAction(csom.NewActionMethodWithParams(
+			"SetFieldValue",
+			csom.NewParamString(key),
+			csom.NewParamString(val),
+		), propsObj)
 	}
 
 	csomPkg, err := b.Compile()
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -98,25 +97,22 @@ func csomItemRecordMethod(item *Item, csomStaticMethod string, date *time.Time,
 	if err != nil {
 		return nil, err
 	}
-	timeParameter := ""
-	if date != nil && csomStaticMethod == "DeclareItemAsRecordWithDeclarationDate" {
-		timeParameter = fmt.Sprintf(`<Parameter Type="DateTime">%s</Parameter>`, date.Format(time.RFC3339))
-	}
-
 	b := csom.NewBuilder()
 
 	b.AddObject(csom.NewObjectProperty("Web"), nil)
 	b.AddObject(csom.NewObjectProperty("Lists"), nil)
-	b.AddObject(csom.NewObjectMethod("GetById", []string{`<Parameter Type="String">` + listR.Data().ID + `</Parameter>`}), nil)
-	b.AddObject(csom.NewObjectMethod("GetItemById", []string{`<Parameter Type="Number">` + strconv.Itoa(itemR.Data().ID) + `</Parameter>`}), nil)
-	b.AddAction(csom.NewAction(`
-		<StaticMethod TypeId="{ea8e1356-5910-4e69-bc05-d0c30ed657fc}" Name="`+csomStaticMethod+`" Id="{{.ID}}">
-			<Parameters>
-				<Parameter ObjectPathId="{{.ObjectID}}" />
-				`+timeParameter+`
-			</Parameters>
-		</StaticMethod>
-	`), nil)
+	b.AddObject(csom.NewObjectMethodWithParams("GetById", csom.NewParamString(listR.Data().ID)), nil)
+	itemObj, _ := b.AddObject(csom.NewObjectMethodWithParams("GetItemById", csom.NewParamInt32(int32(itemR.Data().ID))), nil)
+
+	params := []csom.Parameter{csom.NewParamObjectPath(itemObj)}
+	if date != nil && csomStaticMethod == "DeclareItemAsRecordWithDeclarationDate" {
+		params = append(params, csom.NewParamDateTime(*date))
+	}
+	b.AddAction(csom.NewActionStaticMethodWithParams(
+		"ea8e1356-5910-4e69-bc05-d0c30ed657fc", // Microsoft.SharePoint.Client.RecordsRepository.Records
+		csomStaticMethod,
+		params...,
+	), nil)
 
 	csomPkg, err := b.Compile()
 	if err != nil {
@@ -62,6 +62,11 @@ func (recycleBin *RecycleBin) Get() (RecycleBinResp, error) {
 	return client.Get(recycleBin.ToURL(), recycleBin.config)
 }
 
+// Iterator gets recycled items collection pages iterator, page size is controlled with Top modifier
+func (recycleBin *RecycleBin) Iterator() *Iterator {
+	return NewSkipIterator(recycleBin.client, recycleBin.ToURL(), recycleBin.config)
+}
+
 // GetByID gets a recycled item by its ID
 func (recycleBin *RecycleBin) GetByID(itemID string) *RecycleBinItem {
 	return NewRecycleBinItem(
@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (recycleBin *RecycleBin) Conf(config *RequestConfig) *RecycleBin {
 	recycleBin.config = config
@@ -26,6 +30,12 @@ func (recycleBin *RecycleBin) Filter(oDataFilter string) *RecycleBin {
 	return recycleBin
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (recycleBin *RecycleBin) FilterExpr(oDataFilter odata.Expr) *RecycleBin {
+	recycleBin.modifiers.AddFilter(oDataFilter.String())
+	return recycleBin
+}
+
 // Top adds $top OData modifier
 func (recycleBin *RecycleBin) Top(oDataTop int) *RecycleBin {
 	recycleBin.modifiers.AddTop(oDataTop)
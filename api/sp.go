@@ -53,6 +53,11 @@ func (sp *SP) Utility() *Utility {
 	return NewUtility(sp.client, sp.ToURL(), sp.config)
 }
 
+// MoveCopyUtil getter
+func (sp *SP) MoveCopyUtil() *MoveCopyUtil {
+	return NewMoveCopyUtil(sp.client, sp.ToURL(), sp.config)
+}
+
 // Search getter
 func (sp *SP) Search() *Search {
 	return NewSearch(
@@ -76,6 +81,11 @@ func (sp *SP) Taxonomy() *Taxonomy {
 	return NewTaxonomy(sp.client, sp.ToURL(), sp.config)
 }
 
+// Tenant getter, tenant admin site URL is resolved from the current site URL
+func (sp *SP) Tenant() *Tenant {
+	return NewTenant(sp.client, getAdminSiteURL(sp.ToURL()), sp.config)
+}
+
 // ContextInfo gets current Context Info object data
 func (sp *SP) ContextInfo() (*ContextInfo, error) {
 	return NewContext(sp.client, sp.ToURL(), sp.config).Get()
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+// mockModes headers presets the offline tests run with
+var mockModes = map[string]*RequestConfig{
+	"Verbose":         HeadersPresets.Verbose,
+	"Minimalmetadata": HeadersPresets.Minimalmetadata,
+	"Nometadata":      HeadersPresets.Nometadata,
+}
+
+// newMockSP creates SP object bound to the mock server with the mode headers preset
+func newMockSP(t *testing.T, srv *spmock.Server, mode string) *SP {
+	t.Helper()
+	return NewSP(srv.Client()).Conf(mockModes[mode])
+}
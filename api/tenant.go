@@ -0,0 +1,486 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/koltyakov/gosip"
+	"github.com/koltyakov/gosip/csom"
+)
+
+// Tenant tenant administration struct, a wrapper for Microsoft.Online.SharePoint.TenantAdministration.Tenant
+// CSOM object, the requests are sent to the tenant admin site (https://contoso-admin.sharepoint.com).
+// Auth strategies with host bound tokens or cookies should be configured with the admin site URL.
+type Tenant struct {
+	client   *HTTPClient
+	config   *RequestConfig
+	endpoint string
+}
+
+// TenantSiteInfo - site collection properties
+type TenantSiteInfo struct {
+	URL                     string    `json:"Url"`
+	Title                   string    `json:"Title"`
+	Template                string    `json:"Template"`
+	Status                  string    `json:"Status"`
+	LockState               string    `json:"LockState"`
+	Owner                   string    `json:"Owner"`
+	SiteID                  string    `json:"SiteId"`
+	GroupID                 string    `json:"GroupId"`
+	Lcid                    int       `json:"Lcid"`
+	TimeZoneID              int       `json:"TimeZoneId"`
+	StorageMaximumLevel     int64     `json:"StorageMaximumLevel"`
+	StorageWarningLevel     int64     `json:"StorageWarningLevel"`
+	StorageUsage            int64     `json:"StorageUsage"`
+	WebsCount               int       `json:"WebsCount"`
+	SharingCapability       int       `json:"SharingCapability"`
+	LastContentModifiedDate time.Time `json:"LastContentModifiedDate"`
+}
+
+// TenantSiteCreationInfo - site collection creation properties
+type TenantSiteCreationInfo struct {
+	URL                 string // Absolute site collection URL
+	Owner               string // Owner's login name, e.g. user@contoso.onmicrosoft.com
+	Title               string
+	Template            string // Web template, e.g. STS#3
+	Lcid                uint32 // Locale ID, 1033 is used when not provided
+	TimeZoneID          int    // Time zone ID, server default is used when not provided
+	StorageMaximumLevel int64  // Storage quota in MB
+	StorageWarningLevel int64  // Storage warning level in MB
+	CompatibilityLevel  int
+}
+
+// TenantSiteAdminInfo - site collection administrator info
+type TenantSiteAdminInfo struct {
+	LoginName string `json:"LoginName"`
+	Name      string `json:"Name"`
+	Email     string `json:"Email"`
+}
+
+// SpoOperation - tenant long running operation state
+type SpoOperation struct {
+	IsComplete      bool   `json:"IsComplete"`
+	HasTimedout     bool   `json:"HasTimedout"`
+	PollingInterval int    `json:"PollingInterval"` // milliseconds
+	ObjectIdentity  string `json:"_ObjectIdentity_"`
+}
+
+// SiteLockState - site collection lock state
+type SiteLockState string
+
+// Site collection lock states
+const (
+	SiteLockUnlock      SiteLockState = "Unlock"
+	SiteLockReadOnly    SiteLockState = "ReadOnly"
+	SiteLockNoAdditions SiteLockState = "NoAdditions"
+	SiteLockNoAccess    SiteLockState = "NoAccess"
+)
+
+// NewTenant - tenant struct constructor function
+func NewTenant(client *gosip.SPClient, adminSiteURL string, config *RequestConfig) *Tenant {
+	return &Tenant{
+		client:   NewHTTPClient(client),
+		endpoint: adminSiteURL,
+		config:   config,
+	}
+}
+
+// csomBuilderEntry gets CSOM builder entry
+func (tenant *Tenant) csomBuilderEntry() csom.Builder {
+	b := csom.NewBuilder()
+	b.AddObject(csom.NewObject(`<Constructor Id="{{.ID}}" TypeId="{268004ae-ef6b-4e9b-8425-127220d84719}" />`), nil)
+	return b
+}
+
+// Sites gets tenant site collections query object
+func (tenant *Tenant) Sites() *TenantSites {
+	return &TenantSites{tenant: tenant}
+}
+
+// Site gets tenant site collection object by its absolute URL
+func (tenant *Tenant) Site(siteURL string) *TenantSite {
+	return &TenantSite{
+		tenant: tenant,
+		url:    strings.TrimRight(siteURL, "/"),
+	}
+}
+
+// CreateSite starts site collection creation, use WaitForOperation to wait for the site to be provisioned
+func (tenant *Tenant) CreateSite(info *TenantSiteCreationInfo) (*SpoOperation, error) {
+	if info == nil || info.URL == "" || info.Owner == "" {
+		return nil, fmt.Errorf("site URL and owner are required")
+	}
+	lcid := info.Lcid
+	if lcid == 0 {
+		lcid = 1033
+	}
+	props := []*csom.Property{
+		csom.NewProperty("Url", csom.NewParamString(info.URL)),
+		csom.NewProperty("Owner", csom.NewParamString(info.Owner)),
+		csom.NewProperty("Title", csom.NewParamString(info.Title)),
+		csom.NewProperty("Template", csom.NewParamString(info.Template)),
+		csom.NewProperty("Lcid", csom.NewParamUInt32(lcid)),
+	}
+	if info.TimeZoneID != 0 {
+		props = append(props, csom.NewProperty("TimeZoneId", csom.NewParamInt32(int32(info.TimeZoneID))))
+	}
+	if info.StorageMaximumLevel != 0 {
+		props = append(props, csom.NewProperty("StorageMaximumLevel", csom.NewParamInt64(info.StorageMaximumLevel)))
+	}
+	if info.StorageWarningLevel != 0 {
+		props = append(props, csom.NewProperty("StorageWarningLevel", csom.NewParamInt64(info.StorageWarningLevel)))
+	}
+	if info.CompatibilityLevel != 0 {
+		props = append(props, csom.NewProperty("CompatibilityLevel", csom.NewParamInt32(int32(info.CompatibilityLevel))))
+	}
+
+	b := tenant.csomBuilderEntry()
+	b.AddObject(csom.NewObjectMethodWithParams("CreateSite", csom.NewParamObject(
+		"11f84fff-b8cf-47b6-8b50-34e692656606", // SiteCreationProperties
+		props...,
+	)), nil)
+	return tenant.queryOperation(b)
+}
+
+// GetOperation gets actual long running operation state by its object identity
+func (tenant *Tenant) GetOperation(identity string) (*SpoOperation, error) {
+	if identity == "" {
+		return nil, fmt.Errorf("operation identity is empty")
+	}
+	b := csom.NewBuilder()
+	b.AddObject(csom.NewObjectIdentity(identity), nil)
+	return tenant.queryOperation(b)
+}
+
+// WaitForOperation polls long running operation state until it is complete,
+// request config's context can be used to cancel the waiting
+func (tenant *Tenant) WaitForOperation(op *SpoOperation) (*SpoOperation, error) {
+	ctx := context.Background()
+	if tenant.config != nil && tenant.config.Context != nil {
+		ctx = tenant.config.Context
+	}
+	for op != nil && !op.IsComplete {
+		if op.HasTimedout {
+			return op, fmt.Errorf("operation timed out")
+		}
+		interval := time.Duration(op.PollingInterval) * time.Millisecond
+		if interval <= 0 {
+			interval = 5 * time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return op, ctx.Err()
+		case <-time.After(interval):
+		}
+		identity := op.ObjectIdentity
+		next, err := tenant.GetOperation(identity)
+		if err != nil {
+			return op, err
+		}
+		if next.ObjectIdentity == "" {
+			next.ObjectIdentity = identity
+		}
+		op = next
+	}
+	return op, nil
+}
+
+// queryOperation adds a query to the builder's last object and parses the result as SpoOperation
+func (tenant *Tenant) queryOperation(b csom.Builder) (*SpoOperation, error) {
+	b.AddAction(csom.NewQueryWithProps([]string{}), nil)
+	res, err := csomLastResult(tenant.client, tenant.endpoint, tenant.config, b)
+	if err != nil {
+		return nil, err
+	}
+	op := &SpoOperation{}
+	if err := res.Decode(op); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+/* Site collections */
+
+// TenantSites tenant site collections query struct
+type TenantSites struct {
+	tenant        *Tenant
+	filter        string
+	template      string
+	includeDetail bool
+	personalSites int // 0 - server default, 1 - include, 2 - exclude
+	startIndex    string
+}
+
+// TenantSitesPage - tenant site collections page
+type TenantSitesPage struct {
+	Items       []*TenantSiteInfo
+	HasNextPage func() bool
+	GetNextPage func() (*TenantSitesPage, error)
+}
+
+// Filter adds filter expression in SharePoint Online Management Shell notation, e.g. "Url -like 'projects'"
+func (sites *TenantSites) Filter(filter string) *TenantSites {
+	sites.filter = filter
+	return sites
+}
+
+// Template filters site collections by web template, e.g. "GROUP#0"
+func (sites *TenantSites) Template(template string) *TenantSites {
+	sites.template = template
+	return sites
+}
+
+// IncludeDetail requests detailed site properties (storage usage, webs count, etc.), it is slower
+func (sites *TenantSites) IncludeDetail() *TenantSites {
+	sites.includeDetail = true
+	return sites
+}
+
+// IncludePersonalSites includes or excludes OneDrive personal sites
+func (sites *TenantSites) IncludePersonalSites(include bool) *TenantSites {
+	sites.personalSites = 2
+	if include {
+		sites.personalSites = 1
+	}
+	return sites
+}
+
+// csomBuilder gets CSOM builder for a sites page starting from the provided index
+func (sites *TenantSites) csomBuilder(startIndex string) csom.Builder {
+	startIndexParam := csom.NewParamNull()
+	if startIndex != "" {
+		startIndexParam = csom.NewParamString(startIndex)
+	}
+	templateParam := csom.NewParamNull()
+	if sites.template != "" {
+		templateParam = csom.NewParamString(sites.template)
+	}
+
+	b := sites.tenant.csomBuilderEntry()
+	b.AddObject(csom.NewObjectMethodWithParams("GetSitePropertiesFromSharePointByFilters", csom.NewParamObject(
+		"b92aeee2-c92c-4b67-abcc-024e471bc140", // SPOSitePropertiesEnumerableFilter
+		csom.NewProperty("Filter", csom.NewParamString(sites.filter)),
+		csom.NewProperty("IncludeDetail", csom.NewParamBool(sites.includeDetail)),
+		csom.NewProperty("IncludePersonalSite", csom.NewParamEnum(sites.personalSites)),
+		csom.NewProperty("StartIndex", startIndexParam),
+		csom.NewProperty("Template", templateParam),
+	)), nil)
+	b.AddAction(csom.NewQueryWithChildProps([]string{}), nil)
+	return b
+}
+
+// Get gets site collections page
+func (sites *TenantSites) Get() (*TenantSitesPage, error) {
+	return sites.getPage(sites.startIndex)
+}
+
+// GetAll gets all site collections matching the query
+func (sites *TenantSites) GetAll() ([]*TenantSiteInfo, error) {
+	var res []*TenantSiteInfo
+	page, err := sites.Get()
+	for err == nil {
+		res = append(res, page.Items...)
+		if !page.HasNextPage() {
+			break
+		}
+		page, err = page.GetNextPage()
+	}
+	return res, err
+}
+
+func (sites *TenantSites) getPage(startIndex string) (*TenantSitesPage, error) {
+	tenant := sites.tenant
+	res, err := csomLastResult(tenant.client, tenant.endpoint, tenant.config, sites.csomBuilder(startIndex))
+	if err != nil {
+		return nil, err
+	}
+
+	data := &struct {
+		NextStartIndex string            `json:"NextStartIndexFromSharePoint"`
+		Items          []*TenantSiteInfo `json:"_Child_Items_"`
+	}{}
+	if err := res.Decode(data); err != nil {
+		return nil, err
+	}
+
+	return &TenantSitesPage{
+		Items: data.Items,
+		HasNextPage: func() bool {
+			return data.NextStartIndex != ""
+		},
+		GetNextPage: func() (*TenantSitesPage, error) {
+			if data.NextStartIndex == "" {
+				return nil, fmt.Errorf("unable to get next page")
+			}
+			return sites.getPage(data.NextStartIndex)
+		},
+	}, nil
+}
+
+/* Site collection */
+
+// TenantSite tenant site collection struct
+type TenantSite struct {
+	tenant *Tenant
+	url    string
+}
+
+// csomBuilderEntry gets CSOM builder entry
+func (site *TenantSite) csomBuilderEntry() csom.Builder {
+	b := site.tenant.csomBuilderEntry()
+	b.AddObject(csom.NewObjectMethodWithParams(
+		"GetSitePropertiesByUrl",
+		csom.NewParamString(site.url),
+		csom.NewParamBool(true),
+	), nil)
+	return b
+}
+
+// Get gets site collection properties
+func (site *TenantSite) Get() (*TenantSiteInfo, error) {
+	b := site.csomBuilderEntry()
+	b.AddAction(csom.NewQueryWithProps([]string{}), nil)
+	res, err := csomLastResult(site.tenant.client, site.tenant.endpoint, site.tenant.config, b)
+	if err != nil {
+		return nil, err
+	}
+	info := &TenantSiteInfo{}
+	if err := res.Decode(info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Update updates site collection properties, e.g. csom.NewProperty("Title", csom.NewParamString("New title"))
+func (site *TenantSite) Update(props ...*csom.Property) (*SpoOperation, error) {
+	b := site.csomBuilderEntry()
+	propsObj := b.GetObjects()[len(b.GetObjects())-1]
+	for _, prop := range props {
+		b.AddAction(csom.NewSetPropertyWithParam(prop.Name, prop.Value), propsObj)
+	}
+	b.AddObject(csom.NewObjectMethodWithParams("Update"), propsObj)
+	return site.tenant.queryOperation(b)
+}
+
+// SetLockState sets site collection lock state
+func (site *TenantSite) SetLockState(state SiteLockState) (*SpoOperation, error) {
+	return site.Update(csom.NewProperty("LockState", csom.NewParamString(string(state))))
+}
+
+// SetQuota sets site collection storage quota and warning level in MB
+func (site *TenantSite) SetQuota(maxLevel int64, warningLevel int64) (*SpoOperation, error) {
+	return site.Update(
+		csom.NewProperty("StorageMaximumLevel", csom.NewParamInt64(maxLevel)),
+		csom.NewProperty("StorageWarningLevel", csom.NewParamInt64(warningLevel)),
+	)
+}
+
+// Delete moves site collection to the tenant recycle bin
+func (site *TenantSite) Delete() (*SpoOperation, error) {
+	return site.tenantMethodOperation("RemoveSite")
+}
+
+// Restore restores deleted site collection from the tenant recycle bin
+func (site *TenantSite) Restore() (*SpoOperation, error) {
+	return site.tenantMethodOperation("RestoreDeletedSite")
+}
+
+// Purge permanently deletes site collection from the tenant recycle bin
+func (site *TenantSite) Purge() (*SpoOperation, error) {
+	return site.tenantMethodOperation("RemoveDeletedSite")
+}
+
+// Admins gets site collection administrators object
+func (site *TenantSite) Admins() *TenantSiteAdmins {
+	return &TenantSiteAdmins{site: site}
+}
+
+func (site *TenantSite) tenantMethodOperation(methodName string) (*SpoOperation, error) {
+	b := site.tenant.csomBuilderEntry()
+	b.AddObject(csom.NewObjectMethodWithParams(methodName, csom.NewParamString(site.url)), nil)
+	return site.tenant.queryOperation(b)
+}
+
+/* Site collection administrators */
+
+// TenantSiteAdmins site collection administrators struct
+type TenantSiteAdmins struct {
+	site *TenantSite
+}
+
+// Get gets site collection administrators
+func (admins *TenantSiteAdmins) Get() ([]*TenantSiteAdminInfo, error) {
+	info, err := admins.site.Get()
+	if err != nil {
+		return nil, err
+	}
+	if info.SiteID == "" {
+		return nil, fmt.Errorf("can't get site ID")
+	}
+
+	tenant := admins.site.tenant
+	b := tenant.csomBuilderEntry()
+	b.AddObject(csom.NewObjectMethodWithParams("GetSiteAdministrators", csom.NewParamGUID(info.SiteID)), nil)
+	b.AddAction(csom.NewQueryWithChildProps([]string{}), nil)
+	res, err := csomLastResult(tenant.client, tenant.endpoint, tenant.config, b)
+	if err != nil {
+		return nil, err
+	}
+	items, err := res.ChildItems()
+	if err != nil {
+		return nil, err
+	}
+	var siteAdmins []*TenantSiteAdminInfo
+	for _, item := range items {
+		admin := &TenantSiteAdminInfo{}
+		if err := item.Decode(admin); err != nil {
+			return nil, err
+		}
+		siteAdmins = append(siteAdmins, admin)
+	}
+	return siteAdmins, nil
+}
+
+// Add adds a user to site collection administrators
+func (admins *TenantSiteAdmins) Add(loginName string) error {
+	return admins.setSiteAdmin(loginName, true)
+}
+
+// Remove removes a user from site collection administrators
+func (admins *TenantSiteAdmins) Remove(loginName string) error {
+	return admins.setSiteAdmin(loginName, false)
+}
+
+func (admins *TenantSiteAdmins) setSiteAdmin(loginName string, isSiteAdmin bool) error {
+	tenant := admins.site.tenant
+	b := tenant.csomBuilderEntry()
+	b.AddAction(csom.NewActionMethodWithParams(
+		"SetSiteAdmin",
+		csom.NewParamString(admins.site.url),
+		csom.NewParamString(loginName),
+		csom.NewParamBool(isSiteAdmin),
+	), nil)
+	_, err := csomProcess(tenant.client, tenant.endpoint, tenant.config, b)
+	return err
+}
+
+/* Utility methods */
+
+// getAdminSiteURL resolves SharePoint Online tenant admin site URL from any tenant's site URL,
+// e.g. https://contoso.sharepoint.com/sites/site -> https://contoso-admin.sharepoint.com
+func getAdminSiteURL(siteURL string) string {
+	u, err := url.Parse(siteURL)
+	if err != nil || u.Host == "" {
+		return siteURL
+	}
+	host := u.Host
+	parts := strings.SplitN(host, ".", 2)
+	if len(parts) == 2 && strings.HasPrefix(parts[1], "sharepoint.") && !strings.HasSuffix(parts[0], "-admin") {
+		tenantName := strings.TrimSuffix(parts[0], "-my") // OneDrive host
+		host = tenantName + "-admin." + parts[1]
+	}
+	return u.Scheme + "://" + host
+}
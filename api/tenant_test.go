@@ -0,0 +1,52 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTenant(t *testing.T) {
+	checkClient(t)
+
+	if envCode != "spo" {
+		t.Skip("is only supported with SharePoint Online")
+	}
+
+	tenant := NewSP(spClient).Tenant()
+
+	t.Run("Sites/Get", func(t *testing.T) {
+		page, err := tenant.Sites().IncludePersonalSites(false).Get()
+		if err != nil {
+			t.Skipf("tenant admin permissions might be missing: %s", err)
+		}
+		if len(page.Items) == 0 {
+			t.Error("can't get site collections")
+		}
+	})
+
+	t.Run("Site/Get", func(t *testing.T) {
+		siteURL := strings.TrimRight(spClient.AuthCnfg.GetSiteURL(), "/")
+		info, err := tenant.Site(siteURL).Get()
+		if err != nil {
+			t.Skipf("tenant admin permissions might be missing: %s", err)
+		}
+		if !strings.EqualFold(info.URL, siteURL) {
+			t.Errorf("wrong site URL: %s", info.URL)
+		}
+	})
+}
+
+func TestTenantAdminSiteURL(t *testing.T) {
+	cases := map[string]string{
+		"https://contoso.sharepoint.com/sites/site":     "https://contoso-admin.sharepoint.com",
+		"https://contoso-my.sharepoint.com/personal/me": "https://contoso-admin.sharepoint.com",
+		"https://contoso-admin.sharepoint.com":          "https://contoso-admin.sharepoint.com",
+		"https://contoso.sharepoint.us/sites/site":      "https://contoso-admin.sharepoint.us",
+		"http://sharepoint/sites/site":                  "http://sharepoint",
+	}
+	for siteURL, expected := range cases {
+		if adminURL := getAdminSiteURL(siteURL); adminURL != expected {
+			t.Errorf("wrong admin URL for %s: %s", siteURL, adminURL)
+		}
+	}
+}
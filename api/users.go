@@ -42,6 +42,11 @@ func (users *Users) Get() (UsersResp, error) {
 	return client.Get(users.ToURL(), users.config)
 }
 
+// Iterator gets users collection pages iterator, page size is controlled with Top modifier
+func (users *Users) Iterator() *Iterator {
+	return NewSkipIterator(users.client, users.ToURL(), users.config)
+}
+
 // GetByID gets a user by his/her ID (numeric ID from User Information List)
 func (users *Users) GetByID(userID int) *User {
 	return NewUser(
@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (users *Users) Conf(config *RequestConfig) *Users {
 	users.config = config
@@ -26,6 +30,12 @@ func (users *Users) Filter(oDataFilter string) *Users {
 	return users
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (users *Users) FilterExpr(oDataFilter odata.Expr) *Users {
+	users.modifiers.AddFilter(oDataFilter.String())
+	return users
+}
+
 // Top adds $top OData modifier
 func (users *Users) Top(oDataTop int) *Users {
 	users.modifiers.AddTop(oDataTop)
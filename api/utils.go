@@ -112,6 +112,16 @@ func checkGetRelativeURL(relativeURI string, ctxURL string) string {
 	return relativeURI
 }
 
+// absoluteURL resolves server relative and web relative URLs to absolute URLs, absolute URLs are returned as is
+func absoluteURL(relativeURI string, ctxURL string) string {
+	lower := strings.ToLower(relativeURI)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return relativeURI
+	}
+	u, _ := url.Parse(getPriorEndpoint(ctxURL, "/_api"))
+	return fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, checkGetRelativeURL(relativeURI, ctxURL))
+}
+
 // getPriorEndpoint gets endpoint before the provided part ignoring case
 func getPriorEndpoint(endpoint string, part string) string {
 	strLen := len(strings.Split(strings.ToLower(endpoint), strings.ToLower(part))[0])
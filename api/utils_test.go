@@ -293,6 +293,20 @@ func TestUtils(t *testing.T) {
 		}
 	})
 
+	t.Run("absoluteURL", func(t *testing.T) {
+		ctxURL := "https://contoso.sharepoint.com/sites/site/_api/Site"
+		cases := map[string]string{
+			"Shared Documents/Folder":                              "https://contoso.sharepoint.com/sites/site/Shared Documents/Folder",
+			"/sites/other/Shared Documents":                        "https://contoso.sharepoint.com/sites/other/Shared Documents",
+			"https://contoso.sharepoint.com/sites/other/Documents": "https://contoso.sharepoint.com/sites/other/Documents",
+		}
+		for relativeURI, resultURL := range cases {
+			if res := absoluteURL(relativeURI, ctxURL); res != resultURL {
+				t.Errorf(`wrong URL transformation, expected "%s", received "%s"`, resultURL, res)
+			}
+		}
+	})
+
 	t.Run("extractEntityURI", func(t *testing.T) {
 		ep1 := []byte(`{
 			"__metadata": {
@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (views *Views) Conf(config *RequestConfig) *Views {
 	views.config = config
@@ -26,6 +30,12 @@ func (views *Views) Filter(oDataFilter string) *Views {
 	return views
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (views *Views) FilterExpr(oDataFilter odata.Expr) *Views {
+	views.modifiers.AddFilter(oDataFilter.String())
+	return views
+}
+
 // Top adds $top OData modifier
 func (views *Views) Top(oDataTop int) *Views {
 	views.modifiers.AddTop(oDataTop)
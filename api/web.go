@@ -205,9 +205,9 @@ func (web *Web) EnsureUser(loginName string) (*UserInfo, error) {
 	headers := getConfHeaders(web.config)
 	headers["Accept"] = "application/json;odata=verbose"
 
-	body := fmt.Sprintf(`{"logonName": "%s"}`, loginName)
+	body, _ := json.Marshal(map[string]string{"logonName": loginName})
 
-	data, err := client.Post(endpoint, bytes.NewBuffer([]byte(body)), patchConfigHeaders(web.config, headers))
+	data, err := client.Post(endpoint, bytes.NewBuffer(body), patchConfigHeaders(web.config, headers))
 	if err != nil {
 		return nil, err
 	}
@@ -43,6 +43,11 @@ func (webs *Webs) Get() (WebsResp, error) {
 	return client.Get(webs.ToURL(), webs.config)
 }
 
+// Iterator gets sub webs collection pages iterator, page size is controlled with Top modifier
+func (webs *Webs) Iterator() *Iterator {
+	return NewSkipIterator(webs.client, webs.ToURL(), webs.config)
+}
+
 // Add creates a sub web for a parent web with provided `title` and `url`.
 // `url` stands for a system friendly URI (e.g. `finances`) while `title` is a human friendly name (e.g. `Financial Department`).
 // Along with title and url additional metadata can be provided in optional `metadata` string map object.
@@ -2,6 +2,10 @@
 
 package api
 
+import (
+	"github.com/koltyakov/gosip/odata"
+)
+
 // Conf receives custom request config definition, e.g. custom headers, custom OData mod
 func (webs *Webs) Conf(config *RequestConfig) *Webs {
 	webs.config = config
@@ -26,6 +30,12 @@ func (webs *Webs) Filter(oDataFilter string) *Webs {
 	return webs
 }
 
+// FilterExpr adds $filter OData modifier from odata package expression
+func (webs *Webs) FilterExpr(oDataFilter odata.Expr) *Webs {
+	webs.modifiers.AddFilter(oDataFilter.String())
+	return webs
+}
+
 // Top adds $top OData modifier
 func (webs *Webs) Top(oDataTop int) *Webs {
 	webs.modifiers.AddTop(oDataTop)
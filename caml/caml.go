@@ -0,0 +1,218 @@
+// Package caml provides CAML query builder and parser.
+//
+// Views are built programmatically and rendered to XML accepted by
+// Items.GetByCAML, List.RenderListData and View.SetViewXML:
+//
+//	view := caml.NewView().
+//		Scope(caml.ScopeRecursiveAll).
+//		Fields("ID", "Title").
+//		Where(caml.And(
+//			caml.Field("Status").Eq(caml.Choice("Active")),
+//			caml.Field("Category").Eq(caml.Lookup(5)),
+//			caml.Field("AssignedTo").Eq(caml.CurrentUser()),
+//		)).
+//		OrderBy("Modified", false).
+//		RowLimit(100, true)
+//	items, err := list.Items().GetByCAML(view.String())
+//
+// Existing view XML can be parsed, modified and rendered back with Parse.
+package caml
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// View scopes
+const (
+	ScopeDefault      = ""
+	ScopeRecursive    = "Recursive"
+	ScopeRecursiveAll = "RecursiveAll"
+	ScopeFilesOnly    = "FilesOnly"
+)
+
+// View CAML view document
+type View struct {
+	Attrs      []xml.Attr // View element attributes, Scope included
+	Query      *Query
+	ViewFields []*FieldRef
+	Limit      int    // RowLimit value, 0 for no limit
+	Paged      bool   // RowLimit Paged attribute
+	Extra      []*Raw // other View child elements kept as is, e.g. Joins, ProjectedFields
+}
+
+// Query CAML query
+type Query struct {
+	Where        Expr
+	OrderBy      []*FieldRef
+	OrderByAttrs []xml.Attr // OrderBy element attributes, e.g. Override="TRUE"
+	Extra        []*Raw     // other Query child elements kept as is, e.g. GroupBy
+}
+
+// NewView creates empty view
+func NewView() *View {
+	return &View{}
+}
+
+// Scope sets view scope, e.g. ScopeRecursiveAll
+func (v *View) Scope(scope string) *View {
+	v.Attrs = setAttr(v.Attrs, "Scope", scope)
+	return v
+}
+
+// GetScope gets view scope
+func (v *View) GetScope() string {
+	return getAttr(v.Attrs, "Scope")
+}
+
+// Fields sets view fields
+func (v *View) Fields(names ...string) *View {
+	v.ViewFields = nil
+	for _, name := range names {
+		v.ViewFields = append(v.ViewFields, &FieldRef{Name: name})
+	}
+	return v
+}
+
+// Where sets query condition
+func (v *View) Where(expr Expr) *View {
+	v.query().Where = expr
+	return v
+}
+
+// OrderBy adds ordering field
+func (v *View) OrderBy(name string, ascending bool) *View {
+	q := v.query()
+	asc := ascending
+	q.OrderBy = append(q.OrderBy, &FieldRef{Name: name, Ascending: &asc})
+	return v
+}
+
+// RowLimit sets rows limit, paged enables paging with ListItemCollectionPosition
+func (v *View) RowLimit(limit int, paged bool) *View {
+	v.Limit = limit
+	v.Paged = paged
+	return v
+}
+
+func (v *View) query() *Query {
+	if v.Query == nil {
+		v.Query = &Query{}
+	}
+	return v.Query
+}
+
+// String renders view XML
+func (v *View) String() string {
+	b := &strings.Builder{}
+	b.WriteString("<View")
+	writeAttrs(b, v.Attrs)
+	b.WriteString(">")
+	if v.Query != nil {
+		v.Query.render(b)
+	}
+	if len(v.ViewFields) > 0 {
+		b.WriteString("<ViewFields>")
+		for _, f := range v.ViewFields {
+			f.render(b)
+		}
+		b.WriteString("</ViewFields>")
+	}
+	if v.Limit > 0 {
+		b.WriteString("<RowLimit")
+		if v.Paged {
+			b.WriteString(` Paged="TRUE"`)
+		}
+		b.WriteString(">" + strconv.Itoa(v.Limit) + "</RowLimit>")
+	}
+	for _, raw := range v.Extra {
+		raw.render(b)
+	}
+	b.WriteString("</View>")
+	return b.String()
+}
+
+// String renders query XML, e.g. for CAML query parameters which expect Query element only
+func (q *Query) String() string {
+	b := &strings.Builder{}
+	q.render(b)
+	return b.String()
+}
+
+func (q *Query) render(b *strings.Builder) {
+	b.WriteString("<Query>")
+	if q.Where != nil {
+		b.WriteString("<Where>")
+		q.Where.render(b)
+		b.WriteString("</Where>")
+	}
+	if len(q.OrderBy) > 0 {
+		b.WriteString("<OrderBy")
+		writeAttrs(b, q.OrderByAttrs)
+		b.WriteString(">")
+		for _, f := range q.OrderBy {
+			f.render(b)
+		}
+		b.WriteString("</OrderBy>")
+	}
+	for _, raw := range q.Extra {
+		raw.render(b)
+	}
+	b.WriteString("</Query>")
+}
+
+// Raw XML element kept as is
+type Raw struct {
+	Name  string
+	Attrs []xml.Attr
+	Inner string // inner XML
+}
+
+func (r *Raw) render(b *strings.Builder) {
+	b.WriteString("<" + r.Name)
+	writeAttrs(b, r.Attrs)
+	if r.Inner == "" {
+		b.WriteString(" />")
+		return
+	}
+	b.WriteString(">" + r.Inner + "</" + r.Name + ">")
+}
+
+func writeAttrs(b *strings.Builder, attrs []xml.Attr) {
+	for _, a := range attrs {
+		b.WriteString(" " + a.Name.Local + `="` + escape(a.Value) + `"`)
+	}
+}
+
+func setAttr(attrs []xml.Attr, name string, value string) []xml.Attr {
+	for i, a := range attrs {
+		if a.Name.Local == name {
+			if value == "" {
+				return append(attrs[:i], attrs[i+1:]...)
+			}
+			attrs[i].Value = value
+			return attrs
+		}
+	}
+	if value == "" {
+		return attrs
+	}
+	return append(attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+func getAttr(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// escape escapes XML text and attribute values
+func escape(s string) string {
+	b := &strings.Builder{}
+	_ = xml.EscapeText(b, []byte(s))
+	return b.String()
+}
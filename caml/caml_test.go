@@ -0,0 +1,153 @@
+package caml
+
+import (
+	"testing"
+	"time"
+)
+
+func TestView(t *testing.T) {
+	view := NewView().
+		Scope(ScopeRecursiveAll).
+		Fields("ID", "Title").
+		Where(And(
+			Field("Status").Eq(Choice("Active")),
+			Field("Category").Eq(Lookup(5)),
+			Field("AssignedTo").Eq(CurrentUser()),
+		)).
+		OrderBy("Modified", false).
+		RowLimit(100, true)
+
+	expected := `<View Scope="RecursiveAll"><Query><Where>` +
+		`<And>` +
+		`<Eq><FieldRef Name="Status" /><Value Type="Choice">Active</Value></Eq>` +
+		`<And>` +
+		`<Eq><FieldRef Name="Category" LookupId="TRUE" /><Value Type="Lookup">5</Value></Eq>` +
+		`<Eq><FieldRef Name="AssignedTo" LookupId="TRUE" /><Value Type="Integer"><UserID /></Value></Eq>` +
+		`</And>` +
+		`</And>` +
+		`</Where><OrderBy><FieldRef Name="Modified" Ascending="FALSE" /></OrderBy></Query>` +
+		`<ViewFields><FieldRef Name="ID" /><FieldRef Name="Title" /></ViewFields>` +
+		`<RowLimit Paged="TRUE">100</RowLimit></View>`
+	if actual := view.String(); actual != expected {
+		t.Errorf("unexpected view:\n%s\nexpected:\n%s", actual, expected)
+	}
+
+	if NewView().String() != "<View></View>" {
+		t.Errorf("unexpected empty view: %s", NewView().String())
+	}
+}
+
+func TestConditions(t *testing.T) {
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("+3", 3*60*60))
+	cases := []struct {
+		expr     Expr
+		expected string
+	}{
+		{
+			Field("Title").Eq(Text(`<Tom & "Jerry">`)),
+			`<Eq><FieldRef Name="Title" /><Value Type="Text">&lt;Tom &amp; &#34;Jerry&#34;&gt;</Value></Eq>`,
+		},
+		{
+			Field("Amount").Geq(Number(10.5)),
+			`<Geq><FieldRef Name="Amount" /><Value Type="Number">10.5</Value></Geq>`,
+		},
+		{
+			Field("Due").Lt(DateTime(date, true)),
+			`<Lt><FieldRef Name="Due" /><Value Type="DateTime" IncludeTimeValue="TRUE" StorageTZ="TRUE">2020-01-02T00:04:05Z</Value></Lt>`,
+		},
+		{
+			Field("Due").Leq(Today(-7)),
+			`<Leq><FieldRef Name="Due" /><Value Type="DateTime"><Today OffsetDays="-7" /></Value></Leq>`,
+		},
+		{
+			Field("Manager").IsNull(),
+			`<IsNull><FieldRef Name="Manager" /></IsNull>`,
+		},
+		{
+			Field("ID").In(Counter(1), Counter(2)),
+			`<In><FieldRef Name="ID" /><Values><Value Type="Counter">1</Value><Value Type="Counter">2</Value></Values></In>`,
+		},
+		{
+			MembershipOf("CurrentUserGroups", "AssignedTo"),
+			`<Membership Type="CurrentUserGroups"><FieldRef Name="AssignedTo" /></Membership>`,
+		},
+		{
+			Or(Field("A").Eq(Integer(1)), nil, Field("B").Neq(Boolean(true)), Field("C").BeginsWith(Text("x"))),
+			`<Or><Eq><FieldRef Name="A" /><Value Type="Integer">1</Value></Eq>` +
+				`<Or><Neq><FieldRef Name="B" /><Value Type="Boolean">1</Value></Neq>` +
+				`<BeginsWith><FieldRef Name="C" /><Value Type="Text">x</Value></BeginsWith></Or></Or>`,
+		},
+		{
+			And(Field("A").IsNotNull()),
+			`<IsNotNull><FieldRef Name="A" /></IsNotNull>`,
+		},
+	}
+	for _, c := range cases {
+		q := &Query{Where: c.expr}
+		expected := "<Query><Where>" + c.expected + "</Where></Query>"
+		if actual := q.String(); actual != expected {
+			t.Errorf("unexpected condition:\n%s\nexpected:\n%s", actual, expected)
+		}
+	}
+	if And() != nil {
+		t.Error("empty And should be nil")
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := []string{
+		`<View Scope="RecursiveAll"><Query><Where>` +
+			`<And><Eq><FieldRef Name="Status" /><Value Type="Choice">A &amp; B</Value></Eq>` +
+			`<Or><Geq><FieldRef Name="Due" /><Value Type="DateTime"><Today OffsetDays="-7" /></Value></Geq>` +
+			`<Membership Type="SPGroup" ID="5"><FieldRef Name="AssignedTo" /></Membership></Or></And>` +
+			`</Where><OrderBy Override="TRUE"><FieldRef Name="ID" Ascending="TRUE" /></OrderBy>` +
+			`<GroupBy Collapse="TRUE"><FieldRef Name="Status" /></GroupBy></Query>` +
+			`<ViewFields><FieldRef Name="Title" Nullable="TRUE" /></ViewFields>` +
+			`<RowLimit Paged="TRUE">30</RowLimit><Aggregations Value="On"><FieldRef Name="ID" Type="COUNT" /></Aggregations></View>`,
+		`<View><Query><Where><DateRangesOverlap><FieldRef Name="EventDate" /><FieldRef Name="EndDate" />` +
+			`<Value Type="DateTime"><Month /></Value></DateRangesOverlap></Where></Query></View>`,
+		`<View><Query><Where><In><FieldRef Name="Owner" LookupId="TRUE" /><Values>` +
+			`<Value Type="Integer"><UserID /></Value><Value Type="Lookup">3</Value></Values></In></Where></Query></View>`,
+		`<View><Query><Where><Geq><FieldRef Name="Modified" />` +
+			`<Value Type="DateTime" IncludeTimeValue="TRUE" StorageTZ="TRUE">2020-01-02T00:04:05Z</Value></Geq></Where></Query></View>`,
+	}
+	for _, c := range cases {
+		view, err := Parse(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := view.String(); actual != c {
+			t.Errorf("round-trip mismatch:\n%s\nexpected:\n%s", actual, c)
+		}
+	}
+
+	view, err := Parse(`
+		<Query>
+			<Where>
+				<Eq>
+					<FieldRef Name="Title" />
+					<Value Type="Text">Test</Value>
+				</Eq>
+			</Where>
+		</Query>
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view.Scope(ScopeRecursive).RowLimit(10, false)
+	expected := `<View Scope="Recursive"><Query><Where><Eq><FieldRef Name="Title" /><Value Type="Text">Test</Value></Eq></Where></Query>` +
+		`<RowLimit>10</RowLimit></View>`
+	if actual := view.String(); actual != expected {
+		t.Errorf("unexpected view:\n%s\nexpected:\n%s", actual, expected)
+	}
+	if view.GetScope() != ScopeRecursive {
+		t.Errorf("unexpected scope: %s", view.GetScope())
+	}
+
+	if _, err := Parse(`<Where></Where>`); err == nil {
+		t.Error("should fail on unexpected root")
+	}
+	if _, err := Parse(`<View><RowLimit>many</RowLimit></View>`); err == nil {
+		t.Error("should fail on wrong row limit")
+	}
+}
@@ -0,0 +1,233 @@
+package caml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// node generic XML element
+type node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Text    string     `xml:",chardata"`
+	Inner   string     `xml:",innerxml"`
+	Nodes   []*node    `xml:",any"`
+}
+
+// Parse parses view XML, a standalone Query element is accepted as well.
+// Elements which are not modeled by the package are kept as is and rendered back unchanged.
+func Parse(viewXML string) (*View, error) {
+	root := &node{}
+	if err := xml.Unmarshal([]byte(viewXML), root); err != nil {
+		return nil, fmt.Errorf("unable to parse CAML: %w", err)
+	}
+	switch root.XMLName.Local {
+	case "View":
+		return parseView(root)
+	case "Query":
+		q, err := parseQuery(root)
+		if err != nil {
+			return nil, err
+		}
+		return &View{Query: q}, nil
+	}
+	return nil, fmt.Errorf("unable to parse CAML: unexpected root element <%s>", root.XMLName.Local)
+}
+
+func parseView(n *node) (*View, error) {
+	v := &View{Attrs: n.Attrs}
+	for _, c := range n.Nodes {
+		switch c.XMLName.Local {
+		case "Query":
+			q, err := parseQuery(c)
+			if err != nil {
+				return nil, err
+			}
+			v.Query = q
+		case "ViewFields":
+			for _, f := range c.Nodes {
+				v.ViewFields = append(v.ViewFields, parseFieldRef(f))
+			}
+		case "RowLimit":
+			limit, err := strconv.Atoi(strings.TrimSpace(c.Text))
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse CAML: wrong RowLimit value: %w", err)
+			}
+			v.Limit = limit
+			v.Paged = strings.EqualFold(getAttr(c.Attrs, "Paged"), "TRUE")
+		default:
+			v.Extra = append(v.Extra, raw(c))
+		}
+	}
+	return v, nil
+}
+
+func parseQuery(n *node) (*Query, error) {
+	q := &Query{}
+	for _, c := range n.Nodes {
+		switch c.XMLName.Local {
+		case "Where":
+			if len(c.Nodes) != 1 {
+				return nil, fmt.Errorf("unable to parse CAML: Where must have a single condition, got %d", len(c.Nodes))
+			}
+			q.Where = parseExpr(c.Nodes[0])
+		case "OrderBy":
+			q.OrderByAttrs = c.Attrs
+			for _, f := range c.Nodes {
+				q.OrderBy = append(q.OrderBy, parseFieldRef(f))
+			}
+		default:
+			q.Extra = append(q.Extra, raw(c))
+		}
+	}
+	return q, nil
+}
+
+// parseExpr parses condition, unsupported conditions are kept raw
+func parseExpr(n *node) Expr {
+	var expr Expr
+	ok := false
+	switch n.XMLName.Local {
+	case "And", "Or":
+		expr, ok = parseLogical(n)
+	case "In":
+		expr, ok = parseIn(n)
+	case "Membership":
+		expr, ok = parseMembership(n)
+	case "Eq", "Neq", "Gt", "Geq", "Lt", "Leq", "Contains", "BeginsWith",
+		"IsNull", "IsNotNull", "Includes", "NotIncludes":
+		expr, ok = parseComparison(n)
+	}
+	if !ok {
+		return raw(n)
+	}
+	return expr
+}
+
+func parseLogical(n *node) (Expr, bool) {
+	if len(n.Attrs) > 0 || len(n.Nodes) != 2 {
+		return nil, false
+	}
+	return &Logical{
+		Op:       n.XMLName.Local,
+		Operands: []Expr{parseExpr(n.Nodes[0]), parseExpr(n.Nodes[1])},
+	}, true
+}
+
+func parseComparison(n *node) (Expr, bool) {
+	if len(n.Attrs) > 0 || len(n.Nodes) == 0 || len(n.Nodes) > 2 || n.Nodes[0].XMLName.Local != "FieldRef" {
+		return nil, false
+	}
+	c := &Comparison{Op: n.XMLName.Local, Field: parseFieldRef(n.Nodes[0])}
+	if len(n.Nodes) == 2 {
+		v, ok := parseValue(n.Nodes[1])
+		if !ok {
+			return nil, false
+		}
+		c.Value = v
+	}
+	return c, true
+}
+
+func parseIn(n *node) (Expr, bool) {
+	if len(n.Attrs) > 0 || len(n.Nodes) != 2 ||
+		n.Nodes[0].XMLName.Local != "FieldRef" || n.Nodes[1].XMLName.Local != "Values" {
+		return nil, false
+	}
+	in := &In{Field: parseFieldRef(n.Nodes[0])}
+	for _, c := range n.Nodes[1].Nodes {
+		v, ok := parseValue(c)
+		if !ok {
+			return nil, false
+		}
+		in.Values = append(in.Values, v)
+	}
+	return in, true
+}
+
+func parseMembership(n *node) (Expr, bool) {
+	if len(n.Nodes) != 1 || n.Nodes[0].XMLName.Local != "FieldRef" {
+		return nil, false
+	}
+	m := &Membership{Field: parseFieldRef(n.Nodes[0])}
+	for _, a := range n.Attrs {
+		switch a.Name.Local {
+		case "Type":
+			m.Type = a.Value
+		case "ID":
+			m.ID = a.Value
+		default:
+			return nil, false
+		}
+	}
+	return m, true
+}
+
+func parseFieldRef(n *node) *FieldRef {
+	f := &FieldRef{}
+	for _, a := range n.Attrs {
+		switch a.Name.Local {
+		case "Name":
+			f.Name = a.Value
+		case "LookupId":
+			f.LookupID = strings.EqualFold(a.Value, "TRUE")
+		case "Ascending":
+			asc := strings.EqualFold(a.Value, "TRUE")
+			f.Ascending = &asc
+		default:
+			f.Attrs = append(f.Attrs, a)
+		}
+	}
+	return f
+}
+
+func parseValue(n *node) (*Value, bool) {
+	if n.XMLName.Local != "Value" || len(n.Nodes) > 1 {
+		return nil, false
+	}
+	v := &Value{}
+	for _, a := range n.Attrs {
+		switch a.Name.Local {
+		case "Type":
+			v.Type = a.Value
+		case "IncludeTimeValue":
+			v.IncludeTimeValue = strings.EqualFold(a.Value, "TRUE")
+		case "StorageTZ":
+			v.StorageTZ = strings.EqualFold(a.Value, "TRUE")
+		default:
+			return nil, false
+		}
+	}
+	if len(n.Nodes) == 0 {
+		v.Text = n.Text
+		return v, true
+	}
+	el := n.Nodes[0]
+	switch el.XMLName.Local {
+	case "Today":
+		for _, a := range el.Attrs {
+			if a.Name.Local != "OffsetDays" && a.Name.Local != "Offset" {
+				return nil, false
+			}
+			offset, err := strconv.Atoi(a.Value)
+			if err != nil {
+				return nil, false
+			}
+			v.OffsetDays = offset
+		}
+	case "Now", "UserID":
+		if len(el.Attrs) > 0 {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	v.Element = el.XMLName.Local
+	return v, true
+}
+
+func raw(n *node) *Raw {
+	return &Raw{Name: n.XMLName.Local, Attrs: n.Attrs, Inner: strings.TrimSpace(n.Inner)}
+}
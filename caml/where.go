@@ -0,0 +1,277 @@
+package caml
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expr CAML Where condition
+type Expr interface {
+	render(b *strings.Builder)
+}
+
+// Logical And/Or condition, more than two operands are nested as CAML requires
+type Logical struct {
+	Op       string // And, Or
+	Operands []Expr
+}
+
+// Comparison field comparison, e.g. Eq, Neq, Gt, Geq, Lt, Leq, Contains, BeginsWith, IsNull, IsNotNull, Includes, NotIncludes
+type Comparison struct {
+	Op    string
+	Field *FieldRef
+	Value *Value // nil for IsNull, IsNotNull
+}
+
+// In field value is one of the values
+type In struct {
+	Field  *FieldRef
+	Values []*Value
+}
+
+// Membership field membership condition, e.g. CurrentUserGroups, SPWeb.AllUsers
+type Membership struct {
+	Type  string
+	ID    string // group ID for SPGroup type
+	Field *FieldRef
+}
+
+// FieldRef field reference
+type FieldRef struct {
+	Name      string
+	LookupID  bool       // compare lookup by ID instead of the display value
+	Ascending *bool      // OrderBy direction, nil when not set
+	Attrs     []xml.Attr // other attributes
+}
+
+// Value typed comparison value
+type Value struct {
+	Type             string // Text, Number, Integer, Counter, Boolean, DateTime, Lookup, Choice, User, ...
+	Text             string
+	IncludeTimeValue bool
+	StorageTZ        bool   // DateTime value is in UTC, otherwise it's read in the web's time zone
+	Element          string // special value element: Today, Now, UserID
+	OffsetDays       int    // Today offset
+}
+
+// And combines conditions with And
+func And(exprs ...Expr) Expr { return logical("And", exprs) }
+
+// Or combines conditions with Or
+func Or(exprs ...Expr) Expr { return logical("Or", exprs) }
+
+func logical(op string, exprs []Expr) Expr {
+	var operands []Expr
+	for _, e := range exprs {
+		if e != nil {
+			operands = append(operands, e)
+		}
+	}
+	switch len(operands) {
+	case 0:
+		return nil
+	case 1:
+		return operands[0]
+	}
+	return &Logical{Op: op, Operands: operands}
+}
+
+// MembershipOf creates membership condition, e.g. MembershipOf("CurrentUserGroups", "AssignedTo")
+func MembershipOf(membershipType string, field string) Expr {
+	return &Membership{Type: membershipType, Field: &FieldRef{Name: field}}
+}
+
+// FieldExpr field reference comparisons builder
+type FieldExpr struct {
+	ref *FieldRef
+}
+
+// Field creates field comparisons builder
+func Field(name string) *FieldExpr {
+	return &FieldExpr{ref: &FieldRef{Name: name}}
+}
+
+// Eq creates Eq comparison
+func (f *FieldExpr) Eq(v *Value) Expr { return f.compare("Eq", v) }
+
+// Neq creates Neq comparison
+func (f *FieldExpr) Neq(v *Value) Expr { return f.compare("Neq", v) }
+
+// Gt creates Gt comparison
+func (f *FieldExpr) Gt(v *Value) Expr { return f.compare("Gt", v) }
+
+// Geq creates Geq comparison
+func (f *FieldExpr) Geq(v *Value) Expr { return f.compare("Geq", v) }
+
+// Lt creates Lt comparison
+func (f *FieldExpr) Lt(v *Value) Expr { return f.compare("Lt", v) }
+
+// Leq creates Leq comparison
+func (f *FieldExpr) Leq(v *Value) Expr { return f.compare("Leq", v) }
+
+// Contains creates Contains comparison
+func (f *FieldExpr) Contains(v *Value) Expr { return f.compare("Contains", v) }
+
+// BeginsWith creates BeginsWith comparison
+func (f *FieldExpr) BeginsWith(v *Value) Expr { return f.compare("BeginsWith", v) }
+
+// Includes creates Includes comparison for multi-value fields
+func (f *FieldExpr) Includes(v *Value) Expr { return f.compare("Includes", v) }
+
+// NotIncludes creates NotIncludes comparison for multi-value fields
+func (f *FieldExpr) NotIncludes(v *Value) Expr { return f.compare("NotIncludes", v) }
+
+// IsNull creates IsNull condition
+func (f *FieldExpr) IsNull() Expr { return &Comparison{Op: "IsNull", Field: f.field(nil)} }
+
+// IsNotNull creates IsNotNull condition
+func (f *FieldExpr) IsNotNull() Expr { return &Comparison{Op: "IsNotNull", Field: f.field(nil)} }
+
+// In creates In condition
+func (f *FieldExpr) In(values ...*Value) Expr {
+	var first *Value
+	if len(values) > 0 {
+		first = values[0]
+	}
+	return &In{Field: f.field(first), Values: values}
+}
+
+func (f *FieldExpr) compare(op string, v *Value) Expr {
+	return &Comparison{Op: op, Field: f.field(v), Value: v}
+}
+
+// field copies field reference, lookups and users by ID are compared with LookupId="TRUE"
+func (f *FieldExpr) field(v *Value) *FieldRef {
+	ref := *f.ref
+	if v != nil && (v.Type == "Lookup" || (v.Type == "Integer" && v.Element == "UserID")) {
+		ref.LookupID = true
+	}
+	return &ref
+}
+
+// Text creates Text value
+func Text(s string) *Value { return &Value{Type: "Text", Text: s} }
+
+// Choice creates Choice value
+func Choice(s string) *Value { return &Value{Type: "Choice", Text: s} }
+
+// Number creates Number value
+func Number(n float64) *Value {
+	return &Value{Type: "Number", Text: strconv.FormatFloat(n, 'f', -1, 64)}
+}
+
+// Integer creates Integer value
+func Integer(n int) *Value { return &Value{Type: "Integer", Text: strconv.Itoa(n)} }
+
+// Counter creates Counter value, e.g. for ID field
+func Counter(id int) *Value { return &Value{Type: "Counter", Text: strconv.Itoa(id)} }
+
+// Boolean creates Boolean value
+func Boolean(b bool) *Value {
+	if b {
+		return &Value{Type: "Boolean", Text: "1"}
+	}
+	return &Value{Type: "Boolean", Text: "0"}
+}
+
+// DateTime creates DateTime value in UTC, includeTime compares the time part too
+func DateTime(t time.Time, includeTime bool) *Value {
+	return &Value{Type: "DateTime", Text: t.UTC().Format("2006-01-02T15:04:05Z"), IncludeTimeValue: includeTime, StorageTZ: true}
+}
+
+// Today creates DateTime value relative to the current date
+func Today(offsetDays int) *Value {
+	return &Value{Type: "DateTime", Element: "Today", OffsetDays: offsetDays}
+}
+
+// Now creates DateTime value of the current time
+func Now() *Value {
+	return &Value{Type: "DateTime", Element: "Now", IncludeTimeValue: true}
+}
+
+// Lookup creates Lookup value compared by lookup item ID
+func Lookup(id int) *Value { return &Value{Type: "Lookup", Text: strconv.Itoa(id)} }
+
+// User creates user value compared by user ID
+func User(id int) *Value { return &Value{Type: "Lookup", Text: strconv.Itoa(id)} }
+
+// CurrentUser creates current user value
+func CurrentUser() *Value { return &Value{Type: "Integer", Element: "UserID"} }
+
+func (l *Logical) render(b *strings.Builder) {
+	if len(l.Operands) == 1 {
+		l.Operands[0].render(b)
+		return
+	}
+	b.WriteString("<" + l.Op + ">")
+	l.Operands[0].render(b)
+	if len(l.Operands) == 2 {
+		l.Operands[1].render(b)
+	} else {
+		(&Logical{Op: l.Op, Operands: l.Operands[1:]}).render(b)
+	}
+	b.WriteString("</" + l.Op + ">")
+}
+
+func (c *Comparison) render(b *strings.Builder) {
+	b.WriteString("<" + c.Op + ">")
+	c.Field.render(b)
+	if c.Value != nil {
+		c.Value.render(b)
+	}
+	b.WriteString("</" + c.Op + ">")
+}
+
+func (in *In) render(b *strings.Builder) {
+	b.WriteString("<In>")
+	in.Field.render(b)
+	b.WriteString("<Values>")
+	for _, v := range in.Values {
+		v.render(b)
+	}
+	b.WriteString("</Values></In>")
+}
+
+func (m *Membership) render(b *strings.Builder) {
+	b.WriteString(`<Membership Type="` + escape(m.Type) + `"`)
+	if m.ID != "" {
+		b.WriteString(` ID="` + escape(m.ID) + `"`)
+	}
+	b.WriteString(">")
+	m.Field.render(b)
+	b.WriteString("</Membership>")
+}
+
+func (f *FieldRef) render(b *strings.Builder) {
+	b.WriteString(`<FieldRef Name="` + escape(f.Name) + `"`)
+	if f.LookupID {
+		b.WriteString(` LookupId="TRUE"`)
+	}
+	if f.Ascending != nil {
+		b.WriteString(` Ascending="` + strings.ToUpper(strconv.FormatBool(*f.Ascending)) + `"`)
+	}
+	writeAttrs(b, f.Attrs)
+	b.WriteString(" />")
+}
+
+func (v *Value) render(b *strings.Builder) {
+	b.WriteString(`<Value Type="` + escape(v.Type) + `"`)
+	if v.IncludeTimeValue {
+		b.WriteString(` IncludeTimeValue="TRUE"`)
+	}
+	if v.StorageTZ {
+		b.WriteString(` StorageTZ="TRUE"`)
+	}
+	b.WriteString(">")
+	switch {
+	case v.Element == "Today" && v.OffsetDays != 0:
+		b.WriteString(`<Today OffsetDays="` + strconv.Itoa(v.OffsetDays) + `" />`)
+	case v.Element != "":
+		b.WriteString("<" + v.Element + " />")
+	default:
+		b.WriteString(escape(v.Text))
+	}
+	b.WriteString("</Value>")
+}
@@ -0,0 +1,108 @@
+// Package cassette provides HTTP record/replay transport for SPClient.
+//
+// In record mode requests are sent to SharePoint and request/response pairs
+// are written to a cassette file with credentials and secrets redacted.
+// In replay mode the recorded responses are served back without network access,
+// while authentication strategies become no-ops:
+//
+//	rec, _ := cassette.New("./fixtures/lists.json", cassette.ModeReplay)
+//	rec.Attach(client)
+//	sp := api.NewSP(client)
+//
+// Recorded interactions are written to the cassette file with Stop:
+//
+//	rec, _ := cassette.New("./fixtures/lists.json", cassette.ModeRecord)
+//	rec.Attach(client)
+//	defer func() { _ = rec.Stop() }()
+package cassette
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+// Cassette recorded HTTP interactions
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction request/response pair
+type Interaction struct {
+	Request  *Request  `json:"request"`
+	Response *Response `json:"response"`
+}
+
+// Request recorded request
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    *Body       `json:"body,omitempty"`
+}
+
+// Response recorded response
+type Response struct {
+	Status     string      `json:"status"`
+	StatusCode int         `json:"statusCode"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       *Body       `json:"body,omitempty"`
+}
+
+// Body recorded payload, binary content is stored base64 encoded
+type Body struct {
+	Text   string `json:"text,omitempty"`
+	Base64 string `json:"base64,omitempty"`
+}
+
+// newBody creates payload keeping text content readable
+func newBody(data []byte) *Body {
+	if len(data) == 0 {
+		return nil
+	}
+	if utf8.Valid(data) {
+		return &Body{Text: string(data)}
+	}
+	return &Body{Base64: base64.StdEncoding.EncodeToString(data)}
+}
+
+// Bytes gets payload content
+func (b *Body) Bytes() []byte {
+	if b == nil {
+		return nil
+	}
+	if b.Base64 != "" {
+		data, _ := base64.StdEncoding.DecodeString(b.Base64)
+		return data
+	}
+	return []byte(b.Text)
+}
+
+// Load reads a cassette from a file
+func Load(cassettePath string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(cassettePath)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save writes the cassette to a file, parent folders are created when missing
+func (c *Cassette) Save(cassettePath string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cassettePath), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cassettePath, data, 0644)
+}
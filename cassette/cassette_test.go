@@ -0,0 +1,181 @@
+package cassette
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/koltyakov/gosip"
+	"github.com/koltyakov/gosip/api"
+	"github.com/koltyakov/gosip/auth/anon"
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	cassettePath := filepath.Join(dir, "session.json")
+
+	srv := spmock.NewServer()
+	siteURL := srv.SiteURL()
+	session := func(client *gosip.SPClient) (string, error) {
+		sp := api.NewSP(client)
+		list := sp.Web().Lists().GetByTitle("Documents")
+		if _, err := list.Items().Add([]byte(`{"Title":"Recorded"}`)); err != nil {
+			return "", err
+		}
+		items, err := list.Items().Select("Id,Title").Get()
+		if err != nil {
+			return "", err
+		}
+		return string(items.Normalized()), nil
+	}
+
+	rec, err := New(cassettePath, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := srv.Client()
+	rec.Attach(client)
+	recorded, err := session(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	if _, err := os.Stat(cassettePath); !os.IsNotExist(err) {
+		t.Error("cassette should be written on stop")
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(cassettePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if regexp.MustCompile(`0x[0-9A-F]{12},`).Match(data) || !strings.Contains(string(data), `"FormDigestValue\":\"[REDACTED]`) {
+		t.Error("request digest should be redacted")
+	}
+
+	rep, err := New(cassettePath, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = &gosip.SPClient{AuthCnfg: &failingAuth{AuthCnfg: anon.AuthCnfg{SiteURL: siteURL}}}
+	rep.Attach(client)
+	replayed, err := session(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed != recorded {
+		t.Errorf("replayed response differs: %s != %s", replayed, recorded)
+	}
+
+	if _, err := api.NewSP(client).Web().Lists().GetByTitle("Other").Get(); err == nil {
+		t.Error("not recorded request should fail")
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r := NewRedactor("p@ssw0rd")
+
+	headers := r.headers(http.Header{
+		"Authorization":   {"Bearer token"},
+		"Cookie":          {"FedAuth=secret"},
+		"X-Requestdigest": {"0x01"},
+		"Accept":          {"application/json"},
+	})
+	for _, name := range []string{"Authorization", "Cookie", "X-RequestDigest"} {
+		if headers.Get(name) != Redacted {
+			t.Errorf("%s header should be redacted, got %s", name, headers.Get(name))
+		}
+	}
+	if headers.Get("Accept") != "application/json" {
+		t.Error("not sensitive header should be kept")
+	}
+
+	body := string(r.body([]byte(`{"d":{"FormDigestValue":"0x01,02","Title":"p@ssw0rd is here"}}`)))
+	if body != `{"d":{"FormDigestValue":"[REDACTED]","Title":"[REDACTED] is here"}}` {
+		t.Errorf("unexpected body: %s", body)
+	}
+
+	form := string(r.body([]byte("grant_type=client_credentials&client_secret=abc&resource=x")))
+	if form != "grant_type=client_credentials&client_secret=[REDACTED]&resource=x" {
+		t.Errorf("unexpected form: %s", form)
+	}
+
+	u, _ := url.Parse("https://contoso.sharepoint.com/_api/web?access_token=abc&$select=Title")
+	if redacted := r.url(u); redacted != "https://contoso.sharepoint.com/_api/web?%24select=Title&access_token=%5BREDACTED%5D" {
+		t.Errorf("unexpected URL: %s", redacted)
+	}
+
+	r.Keys = append(r.Keys, "Token")
+	if body := string(r.body([]byte(`{"Token":"abc"}`))); body != `{"Token":"[REDACTED]"}` {
+		t.Errorf("keys added after the first use should be redacted: %s", body)
+	}
+}
+
+func TestReplayMatcher(t *testing.T) {
+	rec := &Recorder{
+		Mode:    ModeReplay,
+		Matcher: DefaultMatcher,
+		used:    map[*Interaction]bool{},
+		cassette: &Cassette{Interactions: []*Interaction{
+			{
+				Request:  &Request{Method: "GET", URL: "https://contoso/_api/web?b=2&a=1"},
+				Response: &Response{Status: "200 OK", StatusCode: 200, Body: &Body{Text: "first"}},
+			},
+			{
+				Request:  &Request{Method: "GET", URL: "https://contoso/_api/web?a=1&b=2"},
+				Response: &Response{Status: "200 OK", StatusCode: 200, Body: &Body{Text: "second"}},
+			},
+			{
+				Request:  &Request{Method: "POST", URL: "https://contoso/_api/web", Body: &Body{Text: "payload"}},
+				Response: &Response{Status: "204 No Content", StatusCode: 204},
+			},
+		}},
+	}
+
+	get := func() string {
+		req, _ := http.NewRequest("GET", "https://other/_API/Web?a=1&b=2", nil)
+		resp, err := rec.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := ioutil.ReadAll(resp.Body)
+		return string(data)
+	}
+	for _, expected := range []string{"first", "second", "second"} {
+		if body := get(); body != expected {
+			t.Errorf("expected %s, got %s", expected, body)
+		}
+	}
+
+	req, _ := http.NewRequest("POST", "https://contoso/_api/web", bytes.NewBufferString("other"))
+	if _, err := rec.RoundTrip(req); err == nil {
+		t.Error("body mismatch is expected")
+	}
+	rec.Matcher.Body = false
+	req, _ = http.NewRequest("POST", "https://contoso/_api/web", bytes.NewBufferString("other"))
+	if resp, err := rec.RoundTrip(req); err != nil || resp.StatusCode != 204 {
+		t.Errorf("body should not be matched: %v", err)
+	}
+}
+
+// failingAuth auth which must not be called in replay mode
+type failingAuth struct {
+	anon.AuthCnfg
+}
+
+func (c *failingAuth) SetAuth(req *http.Request, client *gosip.SPClient) error {
+	panic("auth should not be applied in replay mode")
+}
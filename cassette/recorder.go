@@ -0,0 +1,251 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/koltyakov/gosip"
+)
+
+// Mode recorder mode
+type Mode int
+
+const (
+	// ModeRecord sends requests to the network and writes interactions to the cassette
+	ModeRecord Mode = iota
+	// ModeReplay serves recorded interactions without network access
+	ModeReplay
+)
+
+// Matcher replay request matching options
+type Matcher struct {
+	Method bool // match request method
+	Path   bool // match URL path, case insensitive
+	Query  bool // match URL query parameters ignoring the order
+	Body   bool // match request body after redaction
+}
+
+// DefaultMatcher matches requests by method, path, query and body
+var DefaultMatcher = Matcher{Method: true, Path: true, Query: true, Body: true}
+
+// Recorder record/replay HTTP transport
+type Recorder struct {
+	Mode      Mode
+	Path      string            // cassette file path
+	Transport http.RoundTripper // network transport used in record mode, http.DefaultTransport when not set
+	Matcher   Matcher           // replay matching options
+	Redactor  *Redactor         // recorded interactions redactor
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     map[*Interaction]bool
+}
+
+// New creates recorder, in replay mode the cassette is loaded from the path
+func New(cassettePath string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		Mode:     mode,
+		Path:     cassettePath,
+		Matcher:  DefaultMatcher,
+		Redactor: NewRedactor(),
+		cassette: &Cassette{},
+		used:     map[*Interaction]bool{},
+	}
+	if mode == ModeReplay {
+		c, err := Load(cassettePath)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+	}
+	return r, nil
+}
+
+// Attach plugs the recorder into SPClient.
+// The client's transport is used for recording, in replay mode authentication becomes a no-op
+func (r *Recorder) Attach(client *gosip.SPClient) {
+	if client.Transport != nil && client.Transport != r {
+		r.Transport = client.Transport
+	}
+	client.Transport = r
+	if r.Mode == ModeReplay {
+		if _, ok := client.AuthCnfg.(*replayAuth); !ok && client.AuthCnfg != nil {
+			client.AuthCnfg = &replayAuth{client.AuthCnfg}
+		}
+	}
+}
+
+// Cassette gets recorded interactions
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette
+}
+
+// Stop writes recorded interactions to the cassette file, it's a no-op in replay mode.
+// Interactions are kept in memory while recording, call Stop when the session is over
+func (r *Recorder) Stop() error {
+	if r.Mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.cassette.Save(r.Path); err != nil {
+		return fmt.Errorf("can't save cassette: %w", err)
+	}
+	return nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		data, err := ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = data
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	if r.Mode == ModeReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+// record sends the request and stores redacted interaction
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	redactor := r.redactor()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: &Request{
+			Method:  req.Method,
+			URL:     redactor.url(req.URL),
+			Headers: redactor.headers(req.Header),
+			Body:    newBody(redactor.body(body)),
+		},
+		Response: &Response{
+			Status:     resp.Status,
+			StatusCode: resp.StatusCode,
+			Headers:    redactor.headers(resp.Header),
+			Body:       newBody(redactor.body(respBody)),
+		},
+	})
+	return resp, nil
+}
+
+// replay serves the first not yet used matching interaction,
+// the last matching one is reused when all of them were served, e.g. for retries
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	redactor := r.redactor()
+	body = redactor.body(body)
+
+	var match *Interaction
+	for _, i := range r.cassette.Interactions {
+		if !r.matches(req, body, i.Request) {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("cassette: no recorded interaction for %s %s", req.Method, req.URL)
+	}
+	r.used[match] = true
+
+	resp := &http.Response{
+		Status:        match.Response.Status,
+		StatusCode:    match.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(bytes.NewReader(match.Response.Body.Bytes())),
+		ContentLength: int64(len(match.Response.Body.Bytes())),
+		Request:       req,
+	}
+	for key, values := range match.Response.Headers {
+		resp.Header[key] = append([]string{}, values...)
+	}
+	return resp, nil
+}
+
+// matches checks the request against recorded one using matcher options
+func (r *Recorder) matches(req *http.Request, body []byte, recorded *Request) bool {
+	if r.Matcher.Method && !strings.EqualFold(req.Method, recorded.Method) {
+		return false
+	}
+	recURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	if r.Matcher.Path && !strings.EqualFold(req.URL.Path, recURL.Path) {
+		return false
+	}
+	if r.Matcher.Query && !equalValues(r.redactor().values(req.URL.Query()), recURL.Query()) {
+		return false
+	}
+	if r.Matcher.Body && !bytes.Equal(body, recorded.Body.Bytes()) {
+		return false
+	}
+	return true
+}
+
+func (r *Recorder) redactor() *Redactor {
+	if r.Redactor == nil {
+		r.Redactor = NewRedactor()
+	}
+	return r.Redactor
+}
+
+// equalValues compares query parameters ignoring the order
+func equalValues(a url.Values, b url.Values) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, av := range a {
+		if bv, ok := b[key]; !ok || !equalStrings(av, bv) {
+			return false
+		}
+	}
+	return true
+}
+
+// replayAuth auth config wrapper which skips authentication in replay mode
+type replayAuth struct {
+	gosip.AuthCnfg
+}
+
+// GetAuth skips authentication
+func (a *replayAuth) GetAuth() (string, int64, error) { return "", 0, nil }
+
+// SetAuth skips authentication
+func (a *replayAuth) SetAuth(req *http.Request, client *gosip.SPClient) error { return nil }
@@ -0,0 +1,156 @@
+package cassette
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Redacted replacement for sensitive values
+const Redacted = "[REDACTED]"
+
+// defaultRedactHeaders headers which are never written to a cassette as is
+var defaultRedactHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-RequestDigest",
+	"X-Forms_Based_Auth_Accepted",
+}
+
+// defaultRedactKeys payload properties (JSON or form-encoded) which values are redacted
+var defaultRedactKeys = []string{
+	"FormDigestValue",
+	"password",
+	"client_secret",
+	"clientSecret",
+	"access_token",
+	"refresh_token",
+	"id_token",
+}
+
+// Redactor removes credentials and secrets from recorded interactions
+type Redactor struct {
+	Headers []string // header names to redact
+	Keys    []string // JSON or form-encoded property names to redact
+	Secrets []string // literal values to redact anywhere, e.g. a password from a private config
+
+	rgx     []*regexp.Regexp
+	rgxKeys []string // keys the expressions are compiled for
+}
+
+// NewRedactor creates redactor with default headers and keys
+func NewRedactor(secrets ...string) *Redactor {
+	return &Redactor{
+		Headers: append([]string{}, defaultRedactHeaders...),
+		Keys:    append([]string{}, defaultRedactKeys...),
+		Secrets: secrets,
+	}
+}
+
+// headers redacts headers copy
+func (r *Redactor) headers(headers http.Header) http.Header {
+	res := http.Header{}
+	for key, values := range headers {
+		res[key] = append([]string{}, values...)
+	}
+	for _, name := range r.Headers {
+		if _, ok := res[http.CanonicalHeaderKey(name)]; ok {
+			res.Set(name, Redacted)
+		}
+	}
+	for key, values := range res {
+		for i, v := range values {
+			values[i] = r.secrets(v)
+		}
+		res[key] = values
+	}
+	return res
+}
+
+// url redacts query parameters and secrets in URL
+func (r *Redactor) url(u *url.URL) string {
+	res := *u
+	res.User = nil
+	if res.RawQuery != "" {
+		res.RawQuery = r.values(u.Query()).Encode()
+	}
+	return r.secrets(res.String())
+}
+
+// values redacts query parameters copy
+func (r *Redactor) values(values url.Values) url.Values {
+	res := url.Values{}
+	for key, vals := range values {
+		redact := false
+		for _, k := range r.Keys {
+			if strings.EqualFold(k, key) {
+				redact = true
+			}
+		}
+		for _, v := range vals {
+			if redact {
+				v = Redacted
+			}
+			res.Add(key, r.secrets(v))
+		}
+	}
+	return res
+}
+
+// body redacts textual payload, binary content is kept as is
+func (r *Redactor) body(data []byte) []byte {
+	if len(data) == 0 || !utf8.Valid(data) {
+		return data
+	}
+	for _, rgx := range r.keysRgx() {
+		data = rgx.ReplaceAll(data, []byte("${1}"+Redacted))
+	}
+	return []byte(r.secrets(string(data)))
+}
+
+// secrets replaces literal secrets
+func (r *Redactor) secrets(value string) string {
+	for _, secret := range r.Secrets {
+		if secret != "" {
+			value = strings.Replace(value, secret, Redacted, -1)
+		}
+	}
+	return value
+}
+
+// keysRgx matches `"key": "value"` and `key=value` pairs, the first group is kept,
+// the expressions are recompiled when Keys are changed
+func (r *Redactor) keysRgx() []*regexp.Regexp {
+	if len(r.Keys) == 0 {
+		return nil
+	}
+	if r.rgx != nil && equalStrings(r.rgxKeys, r.Keys) {
+		return r.rgx
+	}
+	keys := make([]string, len(r.Keys))
+	for i, key := range r.Keys {
+		keys[i] = regexp.QuoteMeta(key)
+	}
+	names := strings.Join(keys, "|")
+	r.rgx = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)("(?:` + names + `)"\s*:\s*")(?:[^"\\]|\\.)*`),
+		regexp.MustCompile(`(?i)((?:^|[?&])(?:` + names + `)=)[^&"\s]*`),
+	}
+	r.rgxKeys = append([]string{}, r.Keys...)
+	return r.rgx
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
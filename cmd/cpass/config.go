@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/koltyakov/gosip/cpass"
+)
+
+// strategySecrets secret fields per auth strategy config
+var strategySecrets = map[string][]string{
+	"adfs":  {"password"},
+	"addin": {"clientSecret"},
+	"fba":   {"password"},
+	"ntlm":  {"password"},
+	"saml":  {"password"},
+	"tmg":   {"password"},
+}
+
+// secretFields resolves secret fields for a strategy, all known secrets when no strategy is provided
+func secretFields(strategy string) ([]string, error) {
+	if strategy != "" {
+		fields, ok := strategySecrets[strategy]
+		if !ok {
+			return nil, fmt.Errorf("unknown strategy: %s", strategy)
+		}
+		return fields, nil
+	}
+	uniq := map[string]bool{}
+	var fields []string
+	for _, ff := range strategySecrets {
+		for _, f := range ff {
+			if !uniq[f] {
+				uniq[f] = true
+				fields = append(fields, f)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// configProp config's top level property, order is kept when writing back
+type configProp struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// processConfigFile encrypts or decrypts secret fields of a config file in place,
+// returns a list of changed fields
+func processConfigFile(configPath string, fields []string, encrypt bool, crypt *cpass.Crypter) ([]string, error) {
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	props, err := parseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse %s: %w", configPath, err)
+	}
+	changed, err := processSecrets(props, fields, encrypt, crypt)
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return changed, nil
+	}
+	return changed, writeFileAtomic(configPath, stringifyConfig(props), 0600)
+}
+
+// processSecrets encrypts or decrypts secret props values
+func processSecrets(props []*configProp, fields []string, encrypt bool, crypt *cpass.Crypter) ([]string, error) {
+	var changed []string
+	for _, prop := range props {
+		if !containsField(fields, prop.Key) {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(prop.Value, &value); err != nil || value == "" {
+			continue // not a string or empty, nothing to process
+		}
+		// Decode returns the value as is when it's not encrypted with the master key
+		decoded, err := crypt.Decode(value)
+		isEncrypted := err == nil && decoded != value
+		if !isEncrypted && looksEncrypted(value) {
+			return nil, fmt.Errorf("can't decrypt %s, it's encrypted with another master key", prop.Key)
+		}
+		if encrypt == isEncrypted {
+			continue // already in the target state
+		}
+		res := decoded
+		if encrypt {
+			if res, err = crypt.Encode(value); err != nil {
+				return nil, fmt.Errorf("can't encrypt %s: %w", prop.Key, err)
+			}
+		}
+		prop.Value, _ = json.Marshal(res)
+		changed = append(changed, prop.Key)
+	}
+	return changed, nil
+}
+
+// looksEncrypted checks the value has cpass hash shape: URL base64 of IV block, anchor and the secret,
+// such a value which can't be decoded is encrypted with another master key
+func looksEncrypted(value string) bool {
+	data, err := base64.URLEncoding.DecodeString(value)
+	return err == nil && len(data) > aes.BlockSize+len("cpass|")
+}
+
+// parseConfig parses JSON object keeping top level props order
+func parseConfig(data []byte) ([]*configProp, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, fmt.Errorf("config is not a JSON object")
+	}
+	var props []*configProp
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		prop := &configProp{Key: fmt.Sprintf("%s", t)}
+		if err := dec.Decode(&prop.Value); err != nil {
+			return nil, err
+		}
+		props = append(props, prop)
+	}
+	return props, nil
+}
+
+// stringifyConfig serializes props to indented JSON object
+func stringifyConfig(props []*configProp) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, prop := range props {
+		key, _ := json.Marshal(prop.Key)
+		var value bytes.Buffer
+		if err := json.Indent(&value, prop.Value, "  ", "  "); err != nil {
+			value.Reset()
+			value.Write(prop.Value)
+		}
+		buf.WriteString("  " + string(key) + ": " + value.String())
+		if i < len(props)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// writeFileAtomic writes data to a temp file in the same folder and renames it over the target
+func writeFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	dir, name := filepath.Split(filePath)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := ioutil.TempFile(dir, "."+name+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }() // no-op after successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filePath)
+}
+
+func containsField(fields []string, key string) bool {
+	for _, f := range fields {
+		if strings.EqualFold(f, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/koltyakov/gosip/cpass"
+)
+
+func TestConfigEncryption(t *testing.T) {
+	crypt := cpass.Cpass("MY_MASTER_KEY")
+	raw := `{
+  "siteUrl": "https://contoso.sharepoint.com/sites/test",
+  "username": "john.doe@contoso.onmicrosoft.com",
+  "password": "secret"
+}`
+
+	dir, err := ioutil.TempDir("", "cpass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	configPath := filepath.Join(dir, "private.json")
+	if err := ioutil.WriteFile(configPath, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Encrypt", func(t *testing.T) {
+		changed, err := processConfigFile(configPath, []string{"password"}, true, crypt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changed) != 1 || changed[0] != "password" {
+			t.Errorf("unexpected changed fields: %v", changed)
+		}
+		data, _ := ioutil.ReadFile(configPath)
+		conf := map[string]string{}
+		if err := json.Unmarshal(data, &conf); err != nil {
+			t.Fatal(err)
+		}
+		if conf["password"] == "secret" || conf["username"] != "john.doe@contoso.onmicrosoft.com" {
+			t.Error("wrong fields are encrypted")
+		}
+		if strings.Index(string(data), `"siteUrl"`) > strings.Index(string(data), `"password"`) {
+			t.Error("props order is not kept")
+		}
+		if runtime.GOOS != "windows" {
+			info, _ := os.Stat(configPath)
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("expected 0600 permissions, got %o", info.Mode().Perm())
+			}
+		}
+	})
+
+	t.Run("EncryptTwice", func(t *testing.T) {
+		changed, err := processConfigFile(configPath, []string{"password"}, true, crypt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changed) != 0 {
+			t.Error("already encrypted fields should be skipped")
+		}
+	})
+
+	t.Run("Decrypt", func(t *testing.T) {
+		if _, err := processConfigFile(configPath, []string{"password"}, false, crypt); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := ioutil.ReadFile(configPath)
+		conf := map[string]string{}
+		_ = json.Unmarshal(data, &conf)
+		if conf["password"] != "secret" {
+			t.Error("can't decrypt config")
+		}
+	})
+
+	t.Run("WrongMasterKey", func(t *testing.T) {
+		encoded, _ := cpass.Cpass("ANOTHER_KEY").Encode("secret")
+		props := []*configProp{{Key: "password", Value: json.RawMessage(`"` + encoded + `"`)}}
+		if _, err := processSecrets(props, []string{"password"}, false, crypt); err == nil {
+			t.Error("decrypting with a wrong master key should fail")
+		}
+		if _, err := processSecrets(props, []string{"password"}, true, crypt); err == nil {
+			t.Error("a value encrypted with another master key should not be encrypted twice")
+		}
+	})
+
+	t.Run("NotAnObject", func(t *testing.T) {
+		if _, err := parseConfig([]byte(`["a"]`)); err == nil {
+			t.Error("should throw an error")
+		}
+	})
+}
+
+func TestSecretFields(t *testing.T) {
+	fields, err := secretFields("addin")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(fields) != 1 || fields[0] != "clientSecret" {
+		t.Error("wrong addin secret fields")
+	}
+	if _, err := secretFields("unknown"); err == nil {
+		t.Error("unknown strategy should not pass")
+	}
+	all, _ := secretFields("")
+	if !containsField(all, "password") || !containsField(all, "clientSecret") {
+		t.Error("all secret fields should be resolved with no strategy")
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	if code := run([]string{"-mode", "unknown"}, strings.NewReader(""), &stdout, &stderr); code != exitUsage {
+		t.Errorf("expected usage exit code, got %d", code)
+	}
+
+	if code := run([]string{"-mode", "encrypt-config"}, strings.NewReader(""), &stdout, &stderr); code != exitUsage {
+		t.Errorf("expected usage exit code, got %d", code)
+	}
+
+	if code := run([]string{"-mode", "encrypt-config", "-config", "./not-found.json"}, strings.NewReader(""), &stdout, &stderr); code != exitError {
+		t.Errorf("expected error exit code, got %d", code)
+	}
+
+	dir, err := ioutil.TempDir("", "cpass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	configPath := filepath.Join(dir, "private.json")
+	encrypted, _ := cpass.Cpass("key").Encode("secret")
+	_ = ioutil.WriteFile(configPath, []byte(`{"password":"`+encrypted+`"}`), 0600)
+	if code := run([]string{"-mode", "decrypt-config", "-config", configPath, "-master", "wrong"}, strings.NewReader(""), &stdout, &stderr); code != exitError {
+		t.Errorf("expected error exit code with a wrong master key, got %d", code)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"-master", "key"}, strings.NewReader("secret\n"), &stdout, &stderr); code != exitOK {
+		t.Errorf("expected ok exit code, got %d", code)
+	}
+	encoded := strings.TrimSpace(stdout.String())
+	decoded, _ := cpass.Cpass("key").Decode(encoded)
+	if decoded != "secret" {
+		t.Error("secret from stdin is not encoded")
+	}
+}
@@ -1,33 +1,121 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
 
 	"github.com/koltyakov/gosip/cpass"
+	"golang.org/x/term"
+)
+
+// Exit codes
+const (
+	exitOK    = 0
+	exitError = 1 // runtime error, e.g. can't read or write a config
+	exitUsage = 2 // incorrect flags or arguments
 )
 
 func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
 
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
 	var rawSecret string
 	var masterKey string
 	var mode string
+	var configPath string
+	var strategy string
 
-	flag.StringVar(&rawSecret, "secret", "", "Raw secret string")
-	flag.StringVar(&masterKey, "master", "", "Master key string")
-	flag.StringVar(&mode, "mode", "encode", "Mode: encode/decode")
-	flag.Parse()
+	flags := flag.NewFlagSet("cpass", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&rawSecret, "secret", "", "Raw secret string (discouraged, leaks to shell history, use stdin instead)")
+	flags.StringVar(&masterKey, "master", "", "Master key string")
+	flags.StringVar(&mode, "mode", "encode", "Mode: encode/decode/encrypt-config/decrypt-config")
+	flags.StringVar(&configPath, "config", "", "Path to private.json config, used with encrypt-config/decrypt-config modes")
+	flags.StringVar(&strategy, "strategy", "", "Auth strategy of the config, limits fields to the strategy's secrets (optional)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
 
 	crypt := cpass.Cpass(masterKey)
 
-	if mode == "encode" {
-		secret, _ := crypt.Encode(rawSecret)
-		fmt.Println(secret)
+	switch mode {
+	case "encode", "decode":
+		secret := rawSecret
+		if secret == "" {
+			s, err := readSecret(stdin, stderr)
+			if err != nil {
+				_, _ = fmt.Fprintf(stderr, "can't read secret: %s\n", err)
+				return exitError
+			}
+			secret = s
+		} else {
+			_, _ = fmt.Fprintln(stderr, "warning: -secret flag exposes the value to shell history, prefer stdin")
+		}
+		if secret == "" {
+			_, _ = fmt.Fprintln(stderr, "no secret is provided")
+			return exitUsage
+		}
+		res, err := crypt.Encode(secret)
+		if mode == "decode" {
+			res, err = crypt.Decode(secret)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "can't %s secret: %s\n", mode, err)
+			return exitError
+		}
+		_, _ = fmt.Fprintln(stdout, res)
+	case "encrypt-config", "decrypt-config":
+		if configPath == "" {
+			_, _ = fmt.Fprintln(stderr, "-config flag is required in "+mode+" mode")
+			return exitUsage
+		}
+		fields, err := secretFields(strategy)
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		if mode == "decrypt-config" {
+			_, _ = fmt.Fprintln(stderr, "warning: secrets are stored in plain text after decryption, use for debugging only")
+		}
+		changed, err := processConfigFile(configPath, fields, mode == "encrypt-config", crypt)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "can't %s: %s\n", mode, err)
+			return exitError
+		}
+		_, _ = fmt.Fprintf(stdout, "%s: %d field(s) processed\n", configPath, len(changed))
+	default:
+		_, _ = fmt.Fprintf(stderr, "unknown mode: %s\n", mode)
+		flags.Usage()
+		return exitUsage
 	}
 
-	if mode == "decode" {
-		secret, _ := crypt.Decode(rawSecret)
-		fmt.Println(secret)
-	}
+	return exitOK
+}
 
+// readSecret reads a secret from a TTY prompt or from piped stdin
+func readSecret(stdin io.Reader, stderr io.Writer) (string, error) {
+	if f, ok := stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		_, _ = fmt.Fprint(stderr, "Secret: ")
+		secret, err := term.ReadPassword(int(f.Fd()))
+		_, _ = fmt.Fprintln(stderr)
+		if err != nil {
+			return "", err
+		}
+		return string(secret), nil
+	}
+	data, err := ioutil.ReadAll(bufio.NewReader(stdin))
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 {
+		return "", errors.New("stdin is empty")
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
 }
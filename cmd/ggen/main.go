@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -86,11 +87,27 @@ func generate(c *apiGenCnfg) error {
 			}
 		}
 	}
+	for _, mod := range c.Modificators {
+		if mod == "Filter" {
+			imports["github.com/koltyakov/gosip/odata"] = true
+		}
+	}
 	if len(imports) > 0 {
-		packages := ""
+		var std, ext []string
 		for k := range imports {
-			packages += fmt.Sprintf("\"%s\"\n", k)
+			if strings.Contains(strings.Split(k, "/")[0], ".") {
+				ext = append(ext, fmt.Sprintf("\"%s\"\n", k))
+			} else {
+				std = append(std, fmt.Sprintf("\"%s\"\n", k))
+			}
 		}
+		sort.Strings(std)
+		sort.Strings(ext)
+		packages := strings.Join(std, "")
+		if len(std) > 0 && len(ext) > 0 {
+			packages += "\n"
+		}
+		packages += strings.Join(ext, "")
 		code += `
 			import (
 				` + packages + `
@@ -151,6 +168,12 @@ func modificatorsGen(c *apiGenCnfg) string {
 					` + ent + `.modifiers.AddFilter(oDataFilter)
 					return ` + ent + `
 				}
+
+				// FilterExpr adds $filter OData modifier from odata package expression
+				func (` + ent + ` *` + Ent + `) FilterExpr(oDataFilter odata.Expr) *` + Ent + ` {
+					` + ent + `.modifiers.AddFilter(oDataFilter.String())
+					return ` + ent + `
+				}
 			`
 		case "Top":
 			code += `
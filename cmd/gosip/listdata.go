@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/koltyakov/gosip/api"
+	"github.com/koltyakov/gosip/listdata"
+)
+
+func runExport(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	var conn connection
+	var list, format, fields, filter, out string
+
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	conn.register(flags)
+	flags.StringVar(&list, "list", "", "List title or web relative URL, e.g. Lists/Tasks")
+	flags.StringVar(&format, "format", "", "Data format: csv or jsonl, resolved from -out extension by default")
+	flags.StringVar(&fields, "fields", "", "Comma separated fields internal names, visible editable fields by default")
+	flags.StringVar(&filter, "filter", "", "OData $filter expression")
+	flags.StringVar(&out, "out", "", "Output file path, stdout by default")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if list == "" {
+		_, _ = fmt.Fprintln(stderr, "-list flag is required")
+		return exitUsage
+	}
+
+	client, err := conn.client()
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	w := stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "can't create output file: %s\n", err)
+			return exitError
+		}
+		defer func() { _ = f.Close() }()
+		w = f
+	}
+
+	options := &listdata.ExportOptions{Format: dataFormat(format, out), Filter: filter}
+	if fields != "" {
+		options.Fields = strings.Split(fields, ",")
+	}
+	count, err := listdata.Export(getList(api.NewSP(client), list), w, options)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "can't export list: %s\n", err)
+		return exitError
+	}
+	_, _ = fmt.Fprintf(stderr, "%d item(s) exported\n", count)
+	return exitOK
+}
+
+func runImport(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	var conn connection
+	var list, format, mapping, in string
+	var batchSize int
+
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	conn.register(flags)
+	flags.StringVar(&list, "list", "", "List title or web relative URL, e.g. Lists/Tasks")
+	flags.StringVar(&format, "format", "", "Data format: csv or jsonl, resolved from -in extension by default")
+	flags.StringVar(&mapping, "map", "", "Comma separated column to field mappings, e.g. Name=Title,Due=DueDate")
+	flags.StringVar(&in, "in", "", "Input file path, stdin by default")
+	flags.IntVar(&batchSize, "batch", 10, "Items added with a single $batch request")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if list == "" {
+		_, _ = fmt.Fprintln(stderr, "-list flag is required")
+		return exitUsage
+	}
+	options := &listdata.ImportOptions{Format: dataFormat(format, in), BatchSize: batchSize, Mapping: map[string]string{}}
+	if mapping != "" {
+		for _, m := range strings.Split(mapping, ",") {
+			parts := strings.SplitN(m, "=", 2)
+			if len(parts) != 2 {
+				_, _ = fmt.Fprintf(stderr, "wrong mapping: %s\n", m)
+				return exitUsage
+			}
+			options.Mapping[parts[0]] = parts[1]
+		}
+	}
+
+	client, err := conn.client()
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	r := stdin
+	if in != "" {
+		f, err := os.Open(in)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "can't open input file: %s\n", err)
+			return exitError
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	res, err := listdata.Import(getList(api.NewSP(client), list), r, options)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "can't import list: %s\n", err)
+		return exitError
+	}
+	for _, rowErr := range res.Errors {
+		_, _ = fmt.Fprintln(stderr, rowErr)
+	}
+	_, _ = fmt.Fprintf(stderr, "%d item(s) imported, %d failed\n", res.Added, len(res.Errors))
+	if len(res.Errors) > 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+// dataFormat resolves data format from the flag or the file extension
+func dataFormat(format string, filePath string) listdata.Format {
+	if format != "" {
+		return listdata.Format(strings.ToLower(format))
+	}
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".jsonl", ".ndjson":
+		return listdata.JSONL
+	}
+	return listdata.CSV
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/koltyakov/gosip"
+	"github.com/koltyakov/gosip/api"
+	"github.com/koltyakov/gosip/auth/addin"
+	"github.com/koltyakov/gosip/auth/adfs"
+	"github.com/koltyakov/gosip/auth/anon"
+	"github.com/koltyakov/gosip/auth/fba"
+	"github.com/koltyakov/gosip/auth/ntlm"
+	"github.com/koltyakov/gosip/auth/saml"
+	"github.com/koltyakov/gosip/auth/tmg"
+)
+
+// Exit codes
+const (
+	exitOK    = 0
+	exitError = 1 // runtime error, e.g. a request failed
+	exitUsage = 2 // incorrect flags or arguments
+)
+
+// command CLI subcommand
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int
+}
+
+var commands = []*command{
+	{name: "export", usage: "exports list items to CSV or JSON Lines", run: runExport},
+	{name: "import", usage: "imports list items from CSV or JSON Lines", run: runImport},
+	{name: "sync", usage: "syncs a local directory with a library folder", run: runSync},
+	{name: "storage", usage: "reports library or folder storage usage", run: runStorage},
+}
+
+// strategies auth strategies configs by code
+var strategies = map[string]func() gosip.AuthCnfg{
+	"addin": func() gosip.AuthCnfg { return &addin.AuthCnfg{} },
+	"adfs":  func() gosip.AuthCnfg { return &adfs.AuthCnfg{} },
+	"anon":  func() gosip.AuthCnfg { return &anon.AuthCnfg{} },
+	"fba":   func() gosip.AuthCnfg { return &fba.AuthCnfg{} },
+	"ntlm":  func() gosip.AuthCnfg { return &ntlm.AuthCnfg{} },
+	"saml":  func() gosip.AuthCnfg { return &saml.AuthCnfg{} },
+	"tmg":   func() gosip.AuthCnfg { return &tmg.AuthCnfg{} },
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdin, stdout, stderr)
+		}
+	}
+	_, _ = fmt.Fprintf(stderr, "unknown command: %s\n", args[0])
+	usage(stderr)
+	return exitUsage
+}
+
+func usage(stderr io.Writer) {
+	_, _ = fmt.Fprintln(stderr, "Usage: gosip <command> [flags]")
+	_, _ = fmt.Fprintln(stderr, "Commands:")
+	for _, cmd := range commands {
+		_, _ = fmt.Fprintf(stderr, "  %-8s %s\n", cmd.name, cmd.usage)
+	}
+}
+
+// connection common connection flags
+type connection struct {
+	strategy   string
+	configPath string
+}
+
+func (c *connection) register(flags *flag.FlagSet) {
+	flags.StringVar(&c.strategy, "strategy", "saml", "Auth strategy code: addin, adfs, anon, fba, ntlm, saml, tmg")
+	flags.StringVar(&c.configPath, "config", "./config/private.json", "Path to private.json auth config")
+}
+
+// client creates SharePoint client from the auth config
+func (c *connection) client() (*gosip.SPClient, error) {
+	newAuth, ok := strategies[c.strategy]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy: %s", c.strategy)
+	}
+	auth := newAuth()
+	if err := auth.ReadConfig(c.configPath); err != nil {
+		return nil, fmt.Errorf("unable to read config: %w", err)
+	}
+	return &gosip.SPClient{AuthCnfg: auth}, nil
+}
+
+// getList gets list by web relative URL (contains a slash) or by title
+func getList(sp *api.SP, list string) *api.List {
+	if strings.Contains(list, "/") {
+		return sp.Web().GetList(list)
+	}
+	return sp.Web().Lists().GetByTitle(list)
+}
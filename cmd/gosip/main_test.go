@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestListDataCommands(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+	for _, title := range []string{"Source", "Target"} {
+		if _, err := srv.AddList(title, 100); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, title := range []string{"First", "Second"} {
+		if _, err := srv.AddItem("Source", map[string]interface{}{"Title": title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "gosip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	configPath := filepath.Join(dir, "private.json")
+	if err := ioutil.WriteFile(configPath, []byte(`{"siteUrl":"`+srv.SiteURL()+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conn := []string{"-strategy", "anon", "-config", configPath}
+	dataPath := filepath.Join(dir, "items.jsonl")
+
+	t.Run("Export", func(t *testing.T) {
+		stderr := &bytes.Buffer{}
+		args := append([]string{"export", "-list", "Source", "-fields", "Title", "-out", dataPath}, conn...)
+		if code := run(args, nil, ioutil.Discard, stderr); code != exitOK {
+			t.Fatalf("unexpected exit code %d: %s", code, stderr)
+		}
+		data, _ := ioutil.ReadFile(dataPath)
+		if string(data) != "{\"Title\":\"First\"}\n{\"Title\":\"Second\"}\n" {
+			t.Errorf("unexpected export: %s", data)
+		}
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		stderr := &bytes.Buffer{}
+		args := append([]string{"import", "-list", "Target", "-in", dataPath, "-batch", "1"}, conn...)
+		if code := run(args, nil, ioutil.Discard, stderr); code != exitOK {
+			t.Fatalf("unexpected exit code %d: %s", code, stderr)
+		}
+		if !strings.Contains(stderr.String(), "2 item(s) imported") {
+			t.Errorf("unexpected output: %s", stderr)
+		}
+	})
+
+	t.Run("Stdin", func(t *testing.T) {
+		stderr := &bytes.Buffer{}
+		args := append([]string{"import", "-list", "Target", "-map", "Name=Title"}, conn...)
+		if code := run(args, strings.NewReader("Name\nThird\n"), ioutil.Discard, stderr); code != exitOK {
+			t.Fatalf("unexpected exit code %d: %s", code, stderr)
+		}
+		stdout := &bytes.Buffer{}
+		args = append([]string{"export", "-list", "Target", "-fields", "Title"}, conn...)
+		if code := run(args, nil, stdout, stderr); code != exitOK {
+			t.Fatalf("unexpected exit code %d: %s", code, stderr)
+		}
+		if stdout.String() != "Title\nFirst\nSecond\nThird\n" {
+			t.Errorf("unexpected export: %s", stdout)
+		}
+	})
+
+	t.Run("Usage", func(t *testing.T) {
+		if code := run([]string{"unknown"}, nil, ioutil.Discard, ioutil.Discard); code != exitUsage {
+			t.Errorf("unexpected exit code %d", code)
+		}
+		if code := run(append([]string{"export"}, conn...), nil, ioutil.Discard, ioutil.Discard); code != exitUsage {
+			t.Errorf("unexpected exit code %d", code)
+		}
+	})
+}
+
+func TestSyncCommand(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+	if err := srv.AddFile(spmock.SitePath+"/Shared Documents/Reports/remote.txt", []byte("remote")); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "gosip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	configPath := filepath.Join(dir, "private.json")
+	if err := ioutil.WriteFile(configPath, []byte(`{"siteUrl":"`+srv.SiteURL()+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	syncDir := filepath.Join(dir, "reports")
+	if err := os.MkdirAll(syncDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(syncDir, "local.txt"), []byte("local"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"sync", "-folder", "Shared Documents/Reports", "-dir", syncDir, "-strategy", "anon", "-config", configPath}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if code := run(append(args, "-dry-run"), nil, stdout, stderr); code != exitOK {
+		t.Fatalf("unexpected exit code %d: %s", code, stderr)
+	}
+	if stdout.String() != "upload local.txt (new)\ndownload remote.txt (new)\n" || stderr.String() != "2 action(s) planned\n" {
+		t.Errorf("unexpected output: %s%s", stdout, stderr)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run(args, nil, stdout, stderr); code != exitOK {
+		t.Fatalf("unexpected exit code %d: %s", code, stderr)
+	}
+	if data, _ := ioutil.ReadFile(filepath.Join(syncDir, "remote.txt")); string(data) != "remote" {
+		t.Errorf("remote file is not downloaded: %s", data)
+	}
+	if stderr.String() != "2 action(s) applied, 0 failed\n" {
+		t.Errorf("unexpected output: %s", stderr)
+	}
+
+	if code := run(append(args, "-direction", "sideways"), nil, ioutil.Discard, ioutil.Discard); code != exitUsage {
+		t.Errorf("unexpected exit code %d", code)
+	}
+}
+
+func TestStorageCommand(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+	for fileURL, size := range map[string]int{"Big/a.bin": 2048, "Small/b.txt": 10} {
+		if err := srv.AddFile(spmock.SitePath+"/Shared Documents/"+fileURL, bytes.Repeat([]byte("x"), size)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "gosip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	configPath := filepath.Join(dir, "private.json")
+	if err := ioutil.WriteFile(configPath, []byte(`{"siteUrl":"`+srv.SiteURL()+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"storage", "-folder", "Shared Documents", "-top", "1", "-strategy", "anon", "-config", configPath}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if code := run(args, nil, stdout, stderr); code != exitOK {
+		t.Fatalf("unexpected exit code %d: %s", code, stderr)
+	}
+	for _, line := range []string{"Shared Documents: 2.0 KB, 2 file(s)", "2.0 KB       1 file(s)  Big\n", "2.0 KB  Big/a.bin\n"} {
+		if !strings.Contains(stdout.String(), line) {
+			t.Errorf("%q is missing in the output: %s", line, stdout)
+		}
+	}
+
+	stdout.Reset()
+	if code := run(append(args, "-json"), nil, stdout, stderr); code != exitOK {
+		t.Fatalf("unexpected exit code %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout.String(), `"totalSize": 2058`) {
+		t.Errorf("unexpected output: %s", stdout)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/koltyakov/gosip/api"
+)
+
+func runStorage(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	var conn connection
+	var folder string
+	var top int
+	var versions, asJSON bool
+
+	flags := flag.NewFlagSet("storage", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	conn.register(flags)
+	flags.StringVar(&folder, "folder", "", "Library or folder web relative or server relative URL, e.g. Shared Documents")
+	flags.IntVar(&top, "top", 10, "Number of the largest folders and files to report")
+	flags.BoolVar(&versions, "versions", false, "Include previous versions size, versions are requested for each file")
+	flags.BoolVar(&asJSON, "json", false, "Print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if folder == "" {
+		_, _ = fmt.Fprintln(stderr, "-folder flag is required")
+		return exitUsage
+	}
+
+	client, err := conn.client()
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	options := &api.StorageReportOptions{Top: top, Versions: versions}
+	report, err := api.NewSP(client).Web().GetFolder(folder).StorageReport(options)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "can't get storage report: %s\n", err)
+		return exitError
+	}
+
+	if asJSON {
+		res := map[string]interface{}{
+			"root":       storageEntryJSON(report.Root),
+			"topFolders": storageEntriesJSON(report.TopFolders),
+			"topFiles":   storageEntriesJSON(report.TopFiles),
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(res); err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	root := report.Root
+	_, _ = fmt.Fprintf(stdout, "%s: %s", root.ServerRelativeURL, formatSize(root.TotalSize()))
+	if versions {
+		_, _ = fmt.Fprintf(stdout, " (%s current, %s versions)", formatSize(root.Size), formatSize(root.VersionsSize))
+	}
+	_, _ = fmt.Fprintf(stdout, ", %d file(s), last modified %s\n", root.FileCount, root.LastModified.Format(time.RFC3339))
+	_, _ = fmt.Fprintln(stdout, "\nLargest folders:")
+	for _, e := range report.TopFolders {
+		_, _ = fmt.Fprintf(stdout, "%10s  %6d file(s)  %s\n", formatSize(e.TotalSize()), e.FileCount, e.Path)
+	}
+	_, _ = fmt.Fprintln(stdout, "\nLargest files:")
+	for _, e := range report.TopFiles {
+		_, _ = fmt.Fprintf(stdout, "%10s  %s\n", formatSize(e.TotalSize()), e.Path)
+	}
+	return exitOK
+}
+
+// storageEntryJSON gets storage entry JSON representation
+func storageEntryJSON(e *api.StorageEntry) map[string]interface{} {
+	return map[string]interface{}{
+		"path":              e.Path,
+		"serverRelativeUrl": e.ServerRelativeURL,
+		"size":              e.Size,
+		"versionsSize":      e.VersionsSize,
+		"totalSize":         e.TotalSize(),
+		"fileCount":         e.FileCount,
+		"lastModified":      e.LastModified,
+	}
+}
+
+func storageEntriesJSON(entries []*api.StorageEntry) []map[string]interface{} {
+	res := []map[string]interface{}{}
+	for _, e := range entries {
+		res = append(res, storageEntryJSON(e))
+	}
+	return res
+}
+
+// formatSize formats bytes size with binary units, e.g. "1.5 MB"
+func formatSize(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, unit := float64(size)/1024, 0
+	for value >= 1024 && unit < 4 {
+		value /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", value, []string{"KB", "MB", "GB", "TB", "PB"}[unit])
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/koltyakov/gosip/api"
+	"github.com/koltyakov/gosip/dirsync"
+)
+
+func runSync(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	var conn connection
+	var folder, dir, direction, conflict, exclude string
+	var deletions, hash, dryRun bool
+
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	conn.register(flags)
+	flags.StringVar(&folder, "folder", "", "Library folder web relative or server relative URL, e.g. Shared Documents/Reports")
+	flags.StringVar(&dir, "dir", ".", "Local directory path")
+	flags.StringVar(&direction, "direction", "both", "Sync direction: both, push (local to remote) or pull (remote to local)")
+	flags.StringVar(&conflict, "conflict", "both", "Two-way sync conflicts resolution: local, remote or both (keep both versions)")
+	flags.StringVar(&exclude, "exclude", "", "Comma separated exclusion globs, e.g. *.tmp,.git/**")
+	flags.BoolVar(&deletions, "delete", false, "Propagate deletions")
+	flags.BoolVar(&hash, "hash", false, "Compare files by content hashes")
+	flags.BoolVar(&dryRun, "dry-run", false, "Print planned actions without applying them")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if folder == "" {
+		_, _ = fmt.Fprintln(stderr, "-folder flag is required")
+		return exitUsage
+	}
+	options := &dirsync.Options{
+		Direction: dirsync.Direction(strings.ToLower(direction)),
+		Conflict:  dirsync.ConflictPolicy(strings.ToLower(conflict)),
+		Delete:    deletions,
+		Hash:      hash,
+		DryRun:    dryRun,
+		OnAction: func(action *dirsync.Action) {
+			_, _ = fmt.Fprintln(stdout, action)
+		},
+	}
+	switch options.Direction {
+	case dirsync.Both, dirsync.Push, dirsync.Pull:
+	default:
+		_, _ = fmt.Fprintf(stderr, "unknown direction: %s\n", direction)
+		return exitUsage
+	}
+	switch options.Conflict {
+	case dirsync.LocalWins, dirsync.RemoteWins, dirsync.KeepBoth:
+	default:
+		_, _ = fmt.Fprintf(stderr, "unknown conflict policy: %s\n", conflict)
+		return exitUsage
+	}
+	if exclude != "" {
+		options.Exclude = strings.Split(exclude, ",")
+	}
+
+	client, err := conn.client()
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	res, err := dirsync.Sync(api.NewSP(client).Web(), folder, dir, options)
+	if res == nil {
+		_, _ = fmt.Fprintf(stderr, "can't sync folder: %s\n", err)
+		return exitError
+	}
+	if dryRun {
+		_, _ = fmt.Fprintf(stderr, "%d action(s) planned\n", len(res.Actions))
+	} else {
+		_, _ = fmt.Fprintf(stderr, "%d action(s) applied, %d failed\n", len(res.Actions)-res.Failed, res.Failed)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "can't sync folder: %s\n", err)
+		return exitError
+	}
+	return exitOK
+}
@@ -3,6 +3,7 @@ package csom
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"text/template"
 )
 
@@ -124,3 +125,41 @@ func (a *action) GetObjectID() int { return a.objectID }
 
 // CheckErr checks if an action contains errors
 func (a *action) CheckErr() error { return a.err }
+
+type typedAction struct {
+	action
+	render func(a *typedAction) string
+}
+
+// NewActionMethodWithParams creates CSOM XML method action node builder instance with typed parameters
+func NewActionMethodWithParams(methodName string, params ...Parameter) Action {
+	return &typedAction{render: func(a *typedAction) string {
+		return fmt.Sprintf(
+			`<Method Id="%d" ObjectPathId="%d" Name="%s"><Parameters>%s</Parameters></Method>`,
+			a.GetID(), a.GetObjectID(), methodName, renderParams(params),
+		)
+	}}
+}
+
+// NewActionStaticMethodWithParams creates CSOM XML static method action node builder instance with typed parameters
+func NewActionStaticMethodWithParams(typeID string, methodName string, params ...Parameter) Action {
+	return &typedAction{render: func(a *typedAction) string {
+		return fmt.Sprintf(
+			`<StaticMethod TypeId="{%s}" Name="%s" Id="%d"><Parameters>%s</Parameters></StaticMethod>`,
+			strings.Trim(typeID, "{}"), methodName, a.GetID(), renderParams(params),
+		)
+	}}
+}
+
+// NewSetPropertyWithParam creates CSOM XML set property action node builder instance with typed parameter
+func NewSetPropertyWithParam(propertyName string, param Parameter) Action {
+	return &typedAction{render: func(a *typedAction) string {
+		return fmt.Sprintf(
+			`<SetProperty Id="%d" ObjectPathId="%d" Name="%s">%s</SetProperty>`,
+			a.GetID(), a.GetObjectID(), propertyName, param.String(),
+		)
+	}}
+}
+
+// String stringifies an action
+func (a *typedAction) String() string { return a.render(a) }
@@ -14,6 +14,7 @@ type Builder interface {
 	Compile() (string, error)                                // compiles CSOM XML package
 	Clone() Builder                                          // returns object clone
 	GetObjects() []Object                                    // get CSOM objects array
+	GetActions() []Action                                    // get CSOM actions array
 }
 
 type builder struct {
@@ -73,6 +74,15 @@ func (b *builder) GetObjects() []Object {
 	return objects
 }
 
+// GetActions gets CSOM builder actions array
+func (b *builder) GetActions() []Action {
+	var actions []Action
+	for _, edge := range b.actions {
+		actions = append(actions, edge.Action)
+	}
+	return actions
+}
+
 // GetObjectID gets provided object's ID, the object should be a pointer to already added ObjectPath node
 func (b *builder) GetObjectID(object Object) (int, error) {
 	_, err := b.Compile()
@@ -109,6 +119,9 @@ func (b *builder) Compile() (string, error) {
 			edge.Action.SetID(b.nextActionID())
 			edge.Action.SetObjectID(edge.Object.GetID())
 		}
+		if scope, ok := edge.Action.(Scope); ok {
+			errors = append(errors, scope.compileNested(b.nextActionID, edge.Object)...)
+		}
 		actions += edge.Action.String()
 		if err := edge.Action.CheckErr(); err != nil {
 			errors = append(errors, err)
@@ -167,6 +180,13 @@ func (b *builder) nextActionID() int {
 		if nextID <= edge.Action.GetID() {
 			nextID = edge.Action.GetID() + 1
 		}
+		if scope, ok := edge.Action.(Scope); ok {
+			for _, id := range scope.nestedIDs() {
+				if nextID <= id {
+					nextID = id + 1
+				}
+			}
+		}
 	}
 	return nextID
 }
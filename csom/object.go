@@ -3,6 +3,7 @@ package csom
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"text/template"
 )
 
@@ -93,3 +94,28 @@ func (o *object) GetParentID() int { return o.parentID }
 
 // CheckErr checks errors
 func (o *object) CheckErr() error { return o.err }
+
+type typedObject struct {
+	object
+	methodName string
+	params     []Parameter
+}
+
+// NewObjectMethodWithParams creates CSOM XML method object path node builder instance with typed parameters
+func NewObjectMethodWithParams(methodName string, params ...Parameter) Object {
+	return &typedObject{methodName: methodName, params: params}
+}
+
+// String stringifies an object
+func (o *typedObject) String() string {
+	return fmt.Sprintf(
+		`<Method Id="%d" ParentId="%d" Name="%s"><Parameters>%s</Parameters></Method>`,
+		o.GetID(), o.GetParentID(), o.methodName, renderParams(o.params),
+	)
+}
+
+// Template returns object's template, parameters are rendered with template delimiters escaped
+func (o *typedObject) Template() string {
+	params := strings.Replace(renderParams(o.params), "{{", `{{"{{"}}`, -1)
+	return fmt.Sprintf(`<Method Id="{{.ID}}" ParentId="{{.ParentID}}" Name="%s"><Parameters>%s</Parameters></Method>`, o.methodName, params)
+}
@@ -85,3 +85,24 @@ func TestObject(t *testing.T) {
 	})
 
 }
+
+func TestTypedObject(t *testing.T) {
+	o := NewObjectMethodWithParams("GetByTitle", NewParamString("{{.ID}} & more"))
+	o.SetID(2)
+	o.SetParentID(1)
+
+	shouldBe := `<Method Id="2" ParentId="1" Name="GetByTitle"><Parameters><Parameter Type="String">{{.ID}} &amp; more</Parameter></Parameters></Method>`
+	if o.String() != shouldBe {
+		t.Errorf("wrong typed object: %s", o.String())
+	}
+
+	clone := NewObject(o.Template())
+	clone.SetID(2)
+	clone.SetParentID(1)
+	if clone.String() != shouldBe {
+		t.Errorf("typed object template can't be reused: %s", clone.String())
+	}
+	if err := clone.CheckErr(); err != nil {
+		t.Error(err)
+	}
+}
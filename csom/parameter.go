@@ -0,0 +1,146 @@
+package csom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parameter CSOM typed method parameter
+type Parameter interface {
+	String() string                         // renders <Parameter /> node
+	render(tag string, attrs string) string // renders the value with a custom node name, e.g. <Property /> or <Object />
+}
+
+type param struct {
+	valueType string      // CSOM value type: String, Int32, Int64, UInt32, Double, Boolean, DateTime, Guid, Enum, Null, Array
+	value     string      // raw (not escaped) value
+	items     []Parameter // array items
+	typeID    string      // typed object TypeId
+	props     []*Property // typed object properties
+	object    Object      // object path reference
+}
+
+// Property CSOM typed object property
+type Property struct {
+	Name  string
+	Value Parameter
+}
+
+// NewParamString creates String parameter
+func NewParamString(value string) Parameter {
+	return &param{valueType: "String", value: value}
+}
+
+// NewParamInt32 creates Int32 parameter
+func NewParamInt32(value int32) Parameter {
+	return &param{valueType: "Int32", value: strconv.FormatInt(int64(value), 10)}
+}
+
+// NewParamInt64 creates Int64 parameter
+func NewParamInt64(value int64) Parameter {
+	return &param{valueType: "Int64", value: strconv.FormatInt(value, 10)}
+}
+
+// NewParamUInt32 creates UInt32 parameter
+func NewParamUInt32(value uint32) Parameter {
+	return &param{valueType: "UInt32", value: strconv.FormatUint(uint64(value), 10)}
+}
+
+// NewParamDouble creates Double parameter
+func NewParamDouble(value float64) Parameter {
+	return &param{valueType: "Double", value: strconv.FormatFloat(value, 'f', -1, 64)}
+}
+
+// NewParamBool creates Boolean parameter
+func NewParamBool(value bool) Parameter {
+	return &param{valueType: "Boolean", value: strconv.FormatBool(value)}
+}
+
+// NewParamDateTime creates DateTime parameter, the value is sent in UTC
+func NewParamDateTime(value time.Time) Parameter {
+	return &param{valueType: "DateTime", value: value.UTC().Format("2006-01-02T15:04:05.000Z")}
+}
+
+// NewParamGUID creates Guid parameter, accepts GUIDs with or without braces
+func NewParamGUID(value string) Parameter {
+	guid := strings.Trim(strings.TrimSpace(value), "{}")
+	return &param{valueType: "Guid", value: "{" + strings.ToLower(guid) + "}"}
+}
+
+// NewParamEnum creates Enum parameter
+func NewParamEnum(value int) Parameter {
+	return &param{valueType: "Enum", value: strconv.Itoa(value)}
+}
+
+// NewParamNull creates Null parameter
+func NewParamNull() Parameter {
+	return &param{valueType: "Null"}
+}
+
+// NewParamArray creates Array parameter
+func NewParamArray(items ...Parameter) Parameter {
+	return &param{valueType: "Array", items: items}
+}
+
+// NewParamObjectPath creates a parameter referencing an object path,
+// the object ID is resolved when the package is compiled
+func NewParamObjectPath(object Object) Parameter {
+	return &param{object: object}
+}
+
+// NewParamObject creates typed object parameter, e.g. creation information objects
+func NewParamObject(typeID string, props ...*Property) Parameter {
+	return &param{typeID: "{" + strings.Trim(typeID, "{}") + "}", props: props}
+}
+
+// NewProperty creates typed object property
+func NewProperty(name string, value Parameter) *Property {
+	return &Property{Name: name, Value: value}
+}
+
+// String renders <Parameter /> node
+func (p *param) String() string { return p.render("Parameter", "") }
+
+func (p *param) render(tag string, attrs string) string {
+	if p.object != nil {
+		return fmt.Sprintf(`<%s%s ObjectPathId="%d" />`, tag, attrs, p.object.GetID())
+	}
+	if p.typeID != "" {
+		inner := ""
+		for _, prop := range p.props {
+			inner += prop.Value.render("Property", ` Name="`+escapeXML(prop.Name)+`"`)
+		}
+		return fmt.Sprintf(`<%s%s TypeId="%s">%s</%s>`, tag, attrs, p.typeID, inner, tag)
+	}
+	if p.valueType == "Null" {
+		return fmt.Sprintf(`<%s%s Type="Null" />`, tag, attrs)
+	}
+	if p.valueType == "Array" {
+		inner := ""
+		for _, item := range p.items {
+			inner += item.render("Object", "")
+		}
+		return fmt.Sprintf(`<%s%s Type="Array">%s</%s>`, tag, attrs, inner, tag)
+	}
+	return fmt.Sprintf(`<%s%s Type="%s">%s</%s>`, tag, attrs, p.valueType, escapeXML(p.value), tag)
+}
+
+// renderParams renders parameters list to <Parameters /> node content
+func renderParams(params []Parameter) string {
+	res := ""
+	for _, p := range params {
+		res += p.String()
+	}
+	return res
+}
+
+// escapeXML escapes a value to be used in XML text or attribute
+func escapeXML(value string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
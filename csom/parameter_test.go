@@ -0,0 +1,86 @@
+package csom
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParameters(t *testing.T) {
+	cases := []struct {
+		name     string
+		param    Parameter
+		expected string
+	}{
+		{"String", NewParamString(`Tom & Jerry <"cartoon">`), `<Parameter Type="String">Tom &amp; Jerry &lt;&#34;cartoon&#34;&gt;</Parameter>`},
+		{"Int32", NewParamInt32(-42), `<Parameter Type="Int32">-42</Parameter>`},
+		{"Int64", NewParamInt64(9007199254740993), `<Parameter Type="Int64">9007199254740993</Parameter>`},
+		{"UInt32", NewParamUInt32(1033), `<Parameter Type="UInt32">1033</Parameter>`},
+		{"Double", NewParamDouble(0.5), `<Parameter Type="Double">0.5</Parameter>`},
+		{"Boolean", NewParamBool(true), `<Parameter Type="Boolean">true</Parameter>`},
+		{"DateTime", NewParamDateTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("UTC+1", 3600))), `<Parameter Type="DateTime">2020-01-02T02:04:05.000Z</Parameter>`},
+		{"Guid", NewParamGUID("{9DD47937-E620-4196-87A7-815C7E6AA384}"), `<Parameter Type="Guid">{9dd47937-e620-4196-87a7-815c7e6aa384}</Parameter>`},
+		{"Enum", NewParamEnum(2), `<Parameter Type="Enum">2</Parameter>`},
+		{"Null", NewParamNull(), `<Parameter Type="Null" />`},
+		{"Array", NewParamArray(NewParamString("a"), NewParamInt32(1)), `<Parameter Type="Array"><Object Type="String">a</Object><Object Type="Int32">1</Object></Parameter>`},
+		{
+			"Object",
+			NewParamObject("168f3091-4554-4f14-8866-b20d48e45b54", NewProperty("Name", NewParamString("R&D")), NewProperty("Id", NewParamNull())),
+			`<Parameter TypeId="{168f3091-4554-4f14-8866-b20d48e45b54}"><Property Name="Name" Type="String">R&amp;D</Property><Property Name="Id" Type="Null" /></Parameter>`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.param.String() != c.expected {
+				t.Errorf("wrong parameter rendering, expected %s, got %s", c.expected, c.param.String())
+			}
+		})
+	}
+
+	t.Run("ObjectPath", func(t *testing.T) {
+		b := NewBuilder()
+		web, _ := b.AddObject(NewObjectProperty("Web"), nil)
+		b.AddObject(NewObjectProperty("Lists"), nil)
+		method, _ := b.AddAction(NewActionMethodWithParams("Test", NewParamObjectPath(web)), nil)
+		if _, err := b.Compile(); err != nil {
+			t.Fatal(err)
+		}
+		expected := `<Method Id="3" ObjectPathId="2" Name="Test"><Parameters><Parameter ObjectPathId="1" /></Parameters></Method>`
+		if method.String() != expected {
+			t.Errorf("wrong object path parameter, got %s", method.String())
+		}
+	})
+}
+
+func TestTypedMethods(t *testing.T) {
+	b := NewBuilder()
+	b.AddObject(NewObjectProperty("Web"), nil)
+	lists, _ := b.AddObject(NewObjectProperty("Lists"), nil)
+	b.AddObject(NewObjectMethodWithParams("GetByTitle", NewParamString("{{.ID}} & more")), nil)
+	b.AddAction(NewSetPropertyWithParam("Title", NewParamString("New")), nil)
+	b.AddAction(NewActionMethodWithParams("Update"), nil)
+	b.AddAction(NewActionStaticMethodWithParams("ea8e1356-5910-4e69-bc05-d0c30ed657fc", "IsRecord", NewParamObjectPath(lists)), nil)
+
+	pkg, err := b.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<Request xmlns="http://schemas.microsoft.com/sharepoint/clientquery/2009" SchemaVersion="15.0.0.0" LibraryVersion="16.0.0.0" ApplicationName="Gosip">` +
+		`<Actions>` +
+		`<SetProperty Id="4" ObjectPathId="3" Name="Title"><Parameter Type="String">New</Parameter></SetProperty>` +
+		`<Method Id="5" ObjectPathId="3" Name="Update"><Parameters /></Method>` +
+		`<StaticMethod TypeId="{ea8e1356-5910-4e69-bc05-d0c30ed657fc}" Name="IsRecord" Id="6"><Parameters><Parameter ObjectPathId="2" /></Parameters></StaticMethod>` +
+		`</Actions>` +
+		`<ObjectPaths>` +
+		`<StaticProperty Id="0" TypeId="{3747adcd-a3c3-41b9-bfab-4a64dd2f1e0a}" Name="Current" />` +
+		`<Property Id="1" ParentId="0" Name="Web" />` +
+		`<Property Id="2" ParentId="1" Name="Lists" />` +
+		`<Method Id="3" ParentId="2" Name="GetByTitle"><Parameters><Parameter Type="String">{{.ID}} &amp; more</Parameter></Parameters></Method>` +
+		`</ObjectPaths>` +
+		`</Request>`
+
+	if pkg != expected {
+		t.Errorf("incorrect package: %s", pkg)
+	}
+}
@@ -0,0 +1,292 @@
+package csom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Response CSOM ProcessQuery response with results mapped by action IDs
+type Response struct {
+	SchemaVersion      string     `json:"SchemaVersion"`
+	LibraryVersion     string     `json:"LibraryVersion"`
+	ErrorInfo          *ErrorInfo `json:"ErrorInfo"`
+	TraceCorrelationID string     `json:"TraceCorrelationId"`
+
+	results map[int]*Result
+	ids     []int
+}
+
+// ErrorInfo CSOM response error information
+type ErrorInfo struct {
+	ErrorMessage  string `json:"ErrorMessage"`
+	ErrorValue    string `json:"ErrorValue"`
+	ErrorCode     int    `json:"ErrorCode"`
+	ErrorTypeName string `json:"ErrorTypeName"`
+}
+
+// Result CSOM action's result object
+type Result struct {
+	raw json.RawMessage
+}
+
+var (
+	dateRgx = regexp.MustCompile(`^/Date\((-?\d+(?:,-?\d+)*)\)/$`)
+	guidRgx = regexp.MustCompile(`^/Guid\(([0-9a-fA-F-]{36})\)/$`)
+)
+
+// ParseResponse parses CSOM ProcessQuery response payload
+func ParseResponse(data []byte) (*Response, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // removing BOM
+
+	var arr []json.RawMessage
+	if err := json.Unmarshal(data, &arr); err != nil {
+		return nil, fmt.Errorf("can't parse CSOM response: %w", err)
+	}
+	if len(arr) == 0 {
+		return nil, fmt.Errorf("can't parse CSOM response: empty response")
+	}
+
+	resp := &Response{results: map[int]*Result{}}
+	if err := json.Unmarshal(arr[0], &resp); err != nil {
+		return nil, fmt.Errorf("can't parse CSOM response header: %w", err)
+	}
+
+	// The rest of the response is a sequence of action ID and result pairs
+	for i := 1; i+1 < len(arr); i += 2 {
+		id, err := strconv.Atoi(string(arr[i]))
+		if err != nil {
+			return nil, fmt.Errorf("can't parse CSOM response action ID: %s", arr[i])
+		}
+		resp.results[id] = &Result{raw: arr[i+1]}
+		resp.ids = append(resp.ids, id)
+	}
+
+	return resp, nil
+}
+
+// Err returns response error, if any
+func (r *Response) Err() error {
+	if r.ErrorInfo == nil {
+		return nil
+	}
+	return &Error{ErrorInfo: *r.ErrorInfo, TraceCorrelationID: r.TraceCorrelationID}
+}
+
+// Result gets a result of a compiled action
+func (r *Response) Result(action Action) (*Result, error) {
+	return r.ResultByID(action.GetID())
+}
+
+// ResultByID gets a result by action ID
+func (r *Response) ResultByID(actionID int) (*Result, error) {
+	res, ok := r.results[actionID]
+	if !ok {
+		return nil, fmt.Errorf("no result for action ID %d", actionID)
+	}
+	return res, nil
+}
+
+// Last gets the last result in the response
+func (r *Response) Last() (*Result, error) {
+	if len(r.ids) == 0 {
+		return nil, fmt.Errorf("no results in the response")
+	}
+	return r.results[r.ids[len(r.ids)-1]], nil
+}
+
+// ActionIDs gets action IDs which have results in the response, in order
+func (r *Response) ActionIDs() []int {
+	return append([]int{}, r.ids...)
+}
+
+// Error CSOM response error
+type Error struct {
+	ErrorInfo
+	TraceCorrelationID string
+}
+
+// Error stringifies CSOM error
+func (e *Error) Error() string {
+	return fmt.Sprintf(
+		"%s (Code: %d, %s, Correlation ID: %s)",
+		e.ErrorMessage,
+		e.ErrorCode,
+		e.ErrorTypeName,
+		e.TraceCorrelationID,
+	)
+}
+
+// Raw gets result's raw JSON
+func (res *Result) Raw() json.RawMessage { return res.raw }
+
+// IsNull checks if the result is empty or a null object
+func (res *Result) IsNull() bool {
+	if res == nil || len(res.raw) == 0 || string(res.raw) == "null" {
+		return true
+	}
+	r := &struct {
+		IsNull *bool `json:"IsNull"`
+	}{}
+	if err := json.Unmarshal(res.raw, &r); err == nil && r.IsNull != nil {
+		return *r.IsNull
+	}
+	return false
+}
+
+// Decode unmarshals the result into v converting /Date(...)/ and /Guid(...)/ literals
+// to RFC3339 dates and GUID strings correspondingly, numbers are kept as json.Number in generic values
+func (res *Result) Decode(v interface{}) error {
+	if res.IsNull() {
+		return fmt.Errorf("result is null")
+	}
+	var data interface{}
+	if err := unmarshalNumbers(res.raw, &data); err != nil {
+		return err
+	}
+	normalized, err := json.Marshal(normalizeValue(data))
+	if err != nil {
+		return err
+	}
+	return unmarshalNumbers(normalized, v)
+}
+
+// Map gets the result as a generic map with converted literals, numbers are json.Number values
+func (res *Result) Map() (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if err := res.Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RawMap gets the result as a generic map as is, CSOM literals are not converted
+func (res *Result) RawMap() (map[string]interface{}, error) {
+	if res.IsNull() {
+		return nil, fmt.Errorf("result is null")
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(res.raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// unmarshalNumbers unmarshals JSON keeping numbers precision, e.g. Int64 values above 2^53
+func unmarshalNumbers(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// ObjectIdentity gets result's _ObjectIdentity_ value to be used in follow-up requests
+func (res *Result) ObjectIdentity() string {
+	r := &struct {
+		ObjectIdentity string `json:"_ObjectIdentity_"`
+	}{}
+	_ = json.Unmarshal(res.raw, &r)
+	return r.ObjectIdentity
+}
+
+// ObjectType gets result's _ObjectType_ value
+func (res *Result) ObjectType() string {
+	r := &struct {
+		ObjectType string `json:"_ObjectType_"`
+	}{}
+	_ = json.Unmarshal(res.raw, &r)
+	return r.ObjectType
+}
+
+// Prop gets result's property as a nested result
+func (res *Result) Prop(name string) (*Result, error) {
+	props := map[string]json.RawMessage{}
+	if err := json.Unmarshal(res.raw, &props); err != nil {
+		return nil, fmt.Errorf("result is not an object: %w", err)
+	}
+	prop, ok := props[name]
+	if !ok {
+		return nil, fmt.Errorf("no %s property in the result", name)
+	}
+	return &Result{raw: prop}, nil
+}
+
+// ChildItems gets result's collection _Child_Items_
+func (res *Result) ChildItems() ([]*Result, error) {
+	r := &struct {
+		ChildItems []json.RawMessage `json:"_Child_Items_"`
+	}{}
+	if err := json.Unmarshal(res.raw, &r); err != nil {
+		return nil, fmt.Errorf("can't get child items: %w", err)
+	}
+	if r.ChildItems == nil {
+		return nil, fmt.Errorf("can't get child items")
+	}
+	items := make([]*Result, len(r.ChildItems))
+	for i, item := range r.ChildItems {
+		items[i] = &Result{raw: item}
+	}
+	return items, nil
+}
+
+// ParseDate parses CSOM /Date(...)/ literal, both /Date(ms)/ and /Date(y,m,d,h,m,s,ms)/ notations
+func ParseDate(literal string) (time.Time, bool) {
+	m := dateRgx.FindStringSubmatch(literal)
+	if m == nil {
+		return time.Time{}, false
+	}
+	var parts []int
+	for _, p := range strings.Split(m[1], ",") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return time.Time{}, false
+		}
+		parts = append(parts, n)
+	}
+	if len(parts) == 1 {
+		ms := int64(parts[0])
+		return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond)).UTC(), true
+	}
+	for len(parts) < 7 {
+		parts = append(parts, 0)
+	}
+	// Month is zero based in CSOM dates, the same as in JavaScript
+	return time.Date(parts[0], time.Month(parts[1]+1), parts[2], parts[3], parts[4], parts[5], parts[6]*int(time.Millisecond), time.UTC), true
+}
+
+// ParseGUID parses CSOM /Guid(...)/ literal
+func ParseGUID(literal string) (string, bool) {
+	m := guidRgx.FindStringSubmatch(literal)
+	if m == nil {
+		return "", false
+	}
+	return strings.ToLower(m[1]), true
+}
+
+// normalizeValue converts CSOM literals in a generic JSON value
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if d, ok := ParseDate(v); ok {
+			return d.Format(time.RFC3339Nano)
+		}
+		if g, ok := ParseGUID(v); ok {
+			return g
+		}
+		return v
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = normalizeValue(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeValue(val)
+		}
+		return v
+	}
+	return value
+}
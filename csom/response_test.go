@@ -0,0 +1,207 @@
+package csom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseResponse(t *testing.T) {
+	payload := []byte("\xef\xbb\xbf" + `[
+		{ "SchemaVersion": "15.0.0.0", "LibraryVersion": "16.0.20221.12005", "ErrorInfo": null, "TraceCorrelationId": "a4e7f39f-b051-b000-c7d6-4b3bd0c5ab4e" },
+		2, { "IsNull": false },
+		4, {
+			"_ObjectType_": "SP.Web",
+			"_ObjectIdentity_": "a4e7f39f-b051-b000-c7d6-4b3bd0c5ab4e|740c6a0b-85e2-48a0-a494-e0f1759d4aa7:site:e5b1a8a4-bc19-4af1-a04b-4fc4fa3a7dd4:web:0d2b5ee0-bc9d-4c4b-a3e4-b43a7fd3bbd1",
+			"Id": "/Guid(0D2B5EE0-BC9D-4C4B-A3E4-B43A7FD3BBD1)/",
+			"Title": "Site",
+			"Created": "/Date(2019,11,3,12,19,45,120)/",
+			"LastItemModifiedDate": "/Date(1575375585000)/",
+			"Lists": { "_Child_Items_": [ { "Title": "A" }, { "Title": "B" } ] }
+		},
+		6, true
+	]`)
+
+	resp, err := ParseResponse(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.LibraryVersion != "16.0.20221.12005" {
+		t.Error("wrong header parsing")
+	}
+
+	if err := resp.Err(); err != nil {
+		t.Error(err)
+	}
+
+	if len(resp.ActionIDs()) != 3 {
+		t.Errorf("wrong results number: %d", len(resp.ActionIDs()))
+	}
+
+	t.Run("IsNull", func(t *testing.T) {
+		res, err := resp.ResultByID(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.IsNull() {
+			t.Error("should not be null")
+		}
+		if _, err := resp.ResultByID(3); err == nil {
+			t.Error("should throw an error for missing result")
+		}
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		res, err := resp.ResultByID(4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		web := &struct {
+			ID           string    `json:"Id"`
+			Title        string    `json:"Title"`
+			Created      time.Time `json:"Created"`
+			LastModified time.Time `json:"LastItemModifiedDate"`
+		}{}
+		if err := res.Decode(web); err != nil {
+			t.Fatal(err)
+		}
+		if web.ID != "0d2b5ee0-bc9d-4c4b-a3e4-b43a7fd3bbd1" {
+			t.Errorf("wrong GUID conversion: %s", web.ID)
+		}
+		if !web.Created.Equal(time.Date(2019, 12, 3, 12, 19, 45, 120*int(time.Millisecond), time.UTC)) {
+			t.Errorf("wrong date conversion: %s", web.Created)
+		}
+		if !web.LastModified.Equal(time.Date(2019, 12, 3, 12, 19, 45, 0, time.UTC)) {
+			t.Errorf("wrong epoch date conversion: %s", web.LastModified)
+		}
+		if res.ObjectType() != "SP.Web" {
+			t.Error("wrong object type")
+		}
+		if res.ObjectIdentity() == "" {
+			t.Error("can't get object identity")
+		}
+	})
+
+	t.Run("ChildItems", func(t *testing.T) {
+		res, _ := resp.ResultByID(4)
+		lists, err := res.Prop("Lists")
+		if err != nil {
+			t.Fatal(err)
+		}
+		items, err := lists.ChildItems()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 2 {
+			t.Error("wrong child items number")
+		}
+		if _, err := res.Prop("Unknown"); err == nil {
+			t.Error("should throw an error for missing prop")
+		}
+	})
+
+	t.Run("Last", func(t *testing.T) {
+		res, err := resp.Last()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b bool
+		if err := res.Decode(&b); err != nil {
+			t.Error(err)
+		}
+		if !b {
+			t.Error("wrong scalar result")
+		}
+	})
+}
+
+func TestParseResponseWithBuilder(t *testing.T) {
+	b := NewBuilder()
+	b.AddObject(NewObjectProperty("Web"), nil)
+	query, _ := b.AddAction(NewQueryWithProps([]string{}), nil)
+	if _, err := b.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.GetActions()) != 1 {
+		t.Error("wrong actions number")
+	}
+
+	resp, err := ParseResponse([]byte(`[{ "SchemaVersion": "15.0.0.0", "ErrorInfo": null }, 2, { "Title": "Site" }]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := resp.Result(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := res.Map()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["Title"] != "Site" {
+		t.Error("can't map result by action")
+	}
+}
+
+func TestParseResponseError(t *testing.T) {
+	resp, err := ParseResponse([]byte(`[{
+		"SchemaVersion": "15.0.0.0",
+		"ErrorInfo": { "ErrorMessage": "File Not Found.", "ErrorValue": null, "ErrorCode": -2147024894, "ErrorTypeName": "System.IO.FileNotFoundException" },
+		"TraceCorrelationId": "a4e7f39f-b051-b000-c7d6-4b3bd0c5ab4e"
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Err() == nil {
+		t.Error("should return an error")
+	}
+	if _, err := ParseResponse([]byte(`{}`)); err == nil {
+		t.Error("should not parse non-array payload")
+	}
+}
+
+func TestParseLiterals(t *testing.T) {
+	if _, ok := ParseDate("/Date(incorrect)/"); ok {
+		t.Error("incorrect date should not pass")
+	}
+	if d, ok := ParseDate("/Date(2020,0,1)/"); !ok || d.Month() != time.January {
+		t.Error("wrong short date notation parsing")
+	}
+	if _, ok := ParseGUID("0d2b5ee0-bc9d-4c4b-a3e4-b43a7fd3bbd1"); ok {
+		t.Error("not a literal should not pass")
+	}
+}
+
+func TestResultNumbersAndRawMap(t *testing.T) {
+	resp, err := ParseResponse([]byte(`[{ "SchemaVersion": "15.0.0.0", "ErrorInfo": null }, 2, {
+		"Size": 9007199254740993, "Created": "/Date(1575375585000)/", "Id": "/Guid(0D2B5EE0-BC9D-4C4B-A3E4-B43A7FD3BBD1)/"
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, _ := resp.ResultByID(2)
+
+	v := &struct{ Size int64 }{}
+	if err := res.Decode(v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Size != 9007199254740993 {
+		t.Errorf("Int64 precision is lost: %d", v.Size)
+	}
+	m, err := res.Map()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := m["Size"].(json.Number); !ok || n.String() != "9007199254740993" {
+		t.Errorf("Int64 precision is lost in map: %v", m["Size"])
+	}
+
+	raw, err := res.RawMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw["Created"] != "/Date(1575375585000)/" || raw["Id"] != "/Guid(0D2B5EE0-BC9D-4C4B-A3E4-B43A7FD3BBD1)/" {
+		t.Error("raw map values should be kept as is")
+	}
+}
@@ -0,0 +1,272 @@
+package csom
+
+import (
+	"fmt"
+)
+
+// Scope CSOM scope action builder, a container of nested actions
+// (ExceptionHandlingScope, ExceptionHandlingScopeSimple, ConditionalScope)
+type Scope interface {
+	Action
+	nestedIDs() []int
+	compileNested(nextID func() int, object Object) []error
+}
+
+// ScopeBlock CSOM scope block (TryScope, CatchScope, FinallyScope, TrueScope, FalseScope)
+type ScopeBlock struct {
+	name     string // block node name, empty for a simple scope which has no blocks
+	required bool   // render the block even with no actions
+	id       int
+	actions  []*actionEdge
+}
+
+// AddAction adds Action node to the scope block,
+// when object is nil the action is bound to the scope's object
+func (s *ScopeBlock) AddAction(action Action, object Object) (Action, Object) {
+	s.actions = append(s.actions, &actionEdge{
+		Action: action,
+		Object: object,
+	})
+	return action, object
+}
+
+// GetActions gets scope block actions array
+func (s *ScopeBlock) GetActions() []Action {
+	var actions []Action
+	for _, edge := range s.actions {
+		actions = append(actions, edge.Action)
+	}
+	return actions
+}
+
+func (s *ScopeBlock) String() string {
+	if s == nil || (len(s.actions) == 0 && !s.required) {
+		return ""
+	}
+	actions := ""
+	for _, edge := range s.actions {
+		actions += edge.Action.String()
+	}
+	if s.name == "" {
+		return actions
+	}
+	return fmt.Sprintf(`<%s Id="%d">%s</%s>`, s.name, s.id, actions, s.name)
+}
+
+func (s *ScopeBlock) nestedIDs() []int {
+	if s == nil {
+		return nil
+	}
+	ids := []int{s.id}
+	for _, edge := range s.actions {
+		ids = append(ids, edge.Action.GetID())
+		if scope, ok := edge.Action.(Scope); ok {
+			ids = append(ids, scope.nestedIDs()...)
+		}
+	}
+	return ids
+}
+
+func (s *ScopeBlock) compileNested(nextID func() int, object Object) []error {
+	if s == nil || (len(s.actions) == 0 && !s.required) {
+		return nil
+	}
+	var errors []error
+	if s.id == 0 && s.name != "" {
+		s.id = nextID()
+	}
+	for _, edge := range s.actions {
+		if edge.Object == nil {
+			edge.Object = object
+		}
+		if edge.Action.GetID() == 0 {
+			edge.Action.SetID(nextID())
+			edge.Action.SetObjectID(edge.Object.GetID())
+		}
+		if scope, ok := edge.Action.(Scope); ok {
+			errors = append(errors, scope.compileNested(nextID, edge.Object)...)
+		}
+	}
+	return errors
+}
+
+func (s *ScopeBlock) checkErr() []error {
+	if s == nil {
+		return nil
+	}
+	var errors []error
+	for _, edge := range s.actions {
+		if err := edge.Action.CheckErr(); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return errors
+}
+
+/* Exception handling scope */
+
+// ExceptionHandlingScope CSOM try/catch/finally scope builder,
+// the scope's result in a response is a ScopeResult
+type ExceptionHandlingScope struct {
+	action
+	simple  bool
+	try     *ScopeBlock
+	catch   *ScopeBlock
+	finally *ScopeBlock
+}
+
+// NewExceptionHandlingScope creates CSOM ExceptionHandlingScope builder instance
+func NewExceptionHandlingScope() *ExceptionHandlingScope {
+	return &ExceptionHandlingScope{
+		try:     &ScopeBlock{name: "TryScope", required: true},
+		catch:   &ScopeBlock{name: "CatchScope", required: true},
+		finally: &ScopeBlock{name: "FinallyScope"},
+	}
+}
+
+// NewExceptionHandlingScopeSimple creates CSOM ExceptionHandlingScopeSimple builder instance,
+// a simple scope only suppresses errors of the nested actions, use Try block to add actions
+func NewExceptionHandlingScopeSimple() *ExceptionHandlingScope {
+	return &ExceptionHandlingScope{
+		simple: true,
+		try:    &ScopeBlock{},
+	}
+}
+
+// Try gets try scope block
+func (s *ExceptionHandlingScope) Try() *ScopeBlock { return s.try }
+
+// Catch gets catch scope block
+func (s *ExceptionHandlingScope) Catch() *ScopeBlock { return s.catch }
+
+// Finally gets finally scope block
+func (s *ExceptionHandlingScope) Finally() *ScopeBlock { return s.finally }
+
+// String stringifies the scope
+func (s *ExceptionHandlingScope) String() string {
+	var res string
+	if s.simple {
+		res = fmt.Sprintf(`<ExceptionHandlingScopeSimple Id="%d">%s</ExceptionHandlingScopeSimple>`, s.GetID(), s.try.String())
+	} else {
+		res = fmt.Sprintf(
+			`<ExceptionHandlingScope Id="%d">%s%s%s</ExceptionHandlingScope>`,
+			s.GetID(), s.try.String(), s.catch.String(), s.finally.String(),
+		)
+	}
+	s.err = nil
+	if errs := append(append(s.try.checkErr(), s.catch.checkErr()...), s.finally.checkErr()...); len(errs) > 0 {
+		s.err = errs[0]
+	}
+	return res
+}
+
+func (s *ExceptionHandlingScope) nestedIDs() []int {
+	return append(append(s.try.nestedIDs(), s.catch.nestedIDs()...), s.finally.nestedIDs()...)
+}
+
+func (s *ExceptionHandlingScope) compileNested(nextID func() int, object Object) []error {
+	errors := s.try.compileNested(nextID, object)
+	errors = append(errors, s.catch.compileNested(nextID, object)...)
+	return append(errors, s.finally.compileNested(nextID, object)...)
+}
+
+/* Conditional scope */
+
+// Condition CSOM conditional scope test expression
+type Condition interface {
+	String() string
+}
+
+type condition struct {
+	render func() string
+}
+
+func (c *condition) String() string { return c.render() }
+
+// NewCondition creates a condition from a raw CSOM expression XML
+func NewCondition(expression string) Condition {
+	return &condition{render: func() string { return expression }}
+}
+
+// ServerObjectIsNull creates a condition testing that an object doesn't exist on the server
+func ServerObjectIsNull(object Object) Condition {
+	return &condition{render: func() string {
+		return fmt.Sprintf(
+			`<ExpressionProperty Name="ServerObjectIsNull"><QueryableObject ObjectPathId="%d" /></ExpressionProperty>`,
+			object.GetID(),
+		)
+	}}
+}
+
+// Not negates a condition
+func Not(c Condition) Condition {
+	return &condition{render: func() string { return "<Not>" + c.String() + "</Not>" }}
+}
+
+// ConditionalScope CSOM conditional scope builder,
+// the scope's result in a response is a ScopeResult with TestResult value
+type ConditionalScope struct {
+	action
+	test      Condition
+	trueBlock *ScopeBlock
+	elseBlock *ScopeBlock
+}
+
+// NewConditionalScope creates CSOM ConditionalScope builder instance
+func NewConditionalScope(test Condition) *ConditionalScope {
+	return &ConditionalScope{
+		test:      test,
+		trueBlock: &ScopeBlock{name: "TrueScope"},
+		elseBlock: &ScopeBlock{name: "FalseScope"},
+	}
+}
+
+// Then gets the block executed when the test is true
+func (s *ConditionalScope) Then() *ScopeBlock { return s.trueBlock }
+
+// Else gets the block executed when the test is false
+func (s *ConditionalScope) Else() *ScopeBlock { return s.elseBlock }
+
+// String stringifies the scope
+func (s *ConditionalScope) String() string {
+	res := fmt.Sprintf(
+		`<ConditionalScope Id="%d"><Test><Body>%s</Body></Test>%s%s</ConditionalScope>`,
+		s.GetID(), s.test.String(), s.trueBlock.String(), s.elseBlock.String(),
+	)
+	s.err = nil
+	if errs := append(s.trueBlock.checkErr(), s.elseBlock.checkErr()...); len(errs) > 0 {
+		s.err = errs[0]
+	}
+	return res
+}
+
+func (s *ConditionalScope) nestedIDs() []int {
+	return append(s.trueBlock.nestedIDs(), s.elseBlock.nestedIDs()...)
+}
+
+func (s *ConditionalScope) compileNested(nextID func() int, object Object) []error {
+	errors := s.trueBlock.compileNested(nextID, object)
+	return append(errors, s.elseBlock.compileNested(nextID, object)...)
+}
+
+/* Scope results */
+
+// ScopeResult CSOM scope result
+type ScopeResult struct {
+	HasException bool       `json:"HasException"` // ExceptionHandlingScope: an exception is thrown in try block
+	ErrorInfo    *ErrorInfo `json:"ErrorInfo"`    // ExceptionHandlingScope: the exception details
+	TestResult   bool       `json:"TestResult"`   // ConditionalScope: test expression result
+}
+
+// ScopeResult gets a scope result from the response
+func (r *Response) ScopeResult(scope Scope) (*ScopeResult, error) {
+	res, err := r.Result(scope)
+	if err != nil {
+		return nil, err
+	}
+	scopeRes := &ScopeResult{}
+	if err := res.Decode(scopeRes); err != nil {
+		return nil, err
+	}
+	return scopeRes, nil
+}
@@ -0,0 +1,118 @@
+package csom
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExceptionHandlingScope(t *testing.T) {
+	b := NewBuilder()
+	b.AddObject(NewObjectProperty("Web"), nil)
+	lists, _ := b.AddObject(NewObjectProperty("Lists"), nil)
+	list, _ := b.AddObject(NewObjectMethodWithParams("GetByTitle", NewParamString("Tasks")), nil)
+
+	scope := NewExceptionHandlingScope()
+	b.AddAction(scope, list)
+	scope.Try().AddAction(NewQueryWithProps([]string{}), nil)
+	scope.Catch().AddAction(NewActionMethodWithParams("Add", NewParamObject(
+		"16cd9cc8-d2f8-4b33-8f64-dbd6a6b8d1d8",
+		NewProperty("Title", NewParamString("Tasks")),
+		NewProperty("TemplateType", NewParamInt32(107)),
+	)), lists)
+	query, _ := b.AddAction(NewQueryWithProps([]string{}), list)
+
+	pkg, err := b.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<Actions>` +
+		`<ExceptionHandlingScope Id="4">` +
+		`<TryScope Id="5"><Query Id="6" ObjectPathId="3"><Query SelectAllProperties="true"><Properties></Properties></Query></Query></TryScope>` +
+		`<CatchScope Id="7"><Method Id="8" ObjectPathId="2" Name="Add"><Parameters><Parameter TypeId="{16cd9cc8-d2f8-4b33-8f64-dbd6a6b8d1d8}"><Property Name="Title" Type="String">Tasks</Property><Property Name="TemplateType" Type="Int32">107</Property></Parameter></Parameters></Method></CatchScope>` +
+		`</ExceptionHandlingScope>` +
+		`<Query Id="9" ObjectPathId="3"><Query SelectAllProperties="true"><Properties></Properties></Query></Query>` +
+		`</Actions>`
+
+	if !strings.Contains(pkg, expected) {
+		t.Errorf("incorrect package: %s", pkg)
+	}
+
+	if query.GetID() != 9 {
+		t.Error("nested actions IDs should be considered")
+	}
+
+	resp, err := ParseResponse([]byte(`[
+		{ "SchemaVersion": "15.0.0.0", "ErrorInfo": null },
+		4, { "HasException": true, "ErrorInfo": { "ErrorMessage": "List 'Tasks' does not exist.", "ErrorCode": -2130575322 } },
+		6, null
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scopeRes, err := resp.ScopeResult(scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !scopeRes.HasException || scopeRes.ErrorInfo == nil {
+		t.Error("wrong scope result")
+	}
+}
+
+func TestExceptionHandlingScopeSimple(t *testing.T) {
+	b := NewBuilder()
+	b.AddObject(NewObjectProperty("Web"), nil)
+	scope := NewExceptionHandlingScopeSimple()
+	b.AddAction(scope, nil)
+	scope.Try().AddAction(NewActionMethodWithParams("Update"), nil)
+
+	pkg, err := b.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<Actions><ExceptionHandlingScopeSimple Id="2"><Method Id="3" ObjectPathId="1" Name="Update"><Parameters /></Method></ExceptionHandlingScopeSimple></Actions>`
+	if !strings.Contains(pkg, expected) {
+		t.Errorf("incorrect package: %s", pkg)
+	}
+}
+
+func TestConditionalScope(t *testing.T) {
+	b := NewBuilder()
+	b.AddObject(NewObjectProperty("Web"), nil)
+	lists, _ := b.AddObject(NewObjectProperty("Lists"), nil)
+	list, _ := b.AddObject(NewObjectMethodWithParams("GetByTitle", NewParamString("Tasks")), nil)
+
+	scope := NewConditionalScope(ServerObjectIsNull(list))
+	b.AddAction(scope, lists)
+	scope.Then().AddAction(NewActionMethodWithParams("EnsureSiteAssetsLibrary"), nil)
+	scope.Else().AddAction(NewQueryWithProps([]string{}), list)
+
+	pkg, err := b.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<Actions>` +
+		`<ConditionalScope Id="4">` +
+		`<Test><Body><ExpressionProperty Name="ServerObjectIsNull"><QueryableObject ObjectPathId="3" /></ExpressionProperty></Body></Test>` +
+		`<TrueScope Id="5"><Method Id="6" ObjectPathId="2" Name="EnsureSiteAssetsLibrary"><Parameters /></Method></TrueScope>` +
+		`<FalseScope Id="7"><Query Id="8" ObjectPathId="3"><Query SelectAllProperties="true"><Properties></Properties></Query></Query></FalseScope>` +
+		`</ConditionalScope>` +
+		`</Actions>`
+
+	if !strings.Contains(pkg, expected) {
+		t.Errorf("incorrect package: %s", pkg)
+	}
+
+	t.Run("CheckErr", func(t *testing.T) {
+		b := NewBuilder()
+		b.AddObject(NewObjectProperty("Web"), nil)
+		scope := NewConditionalScope(NewCondition(`<ExpressionConstant Type="Boolean">true</ExpressionConstant>`))
+		b.AddAction(scope, nil)
+		scope.Then().AddAction(NewAction(`<Query Id="{{.ID}}" ObjectPathId="{{.Incorrect}}" />`), nil)
+		if _, err := b.Compile(); err == nil {
+			t.Error("nested action errors should be reported")
+		}
+	})
+}
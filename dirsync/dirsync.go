@@ -0,0 +1,359 @@
+// Package dirsync synchronizes a local directory tree with a document library folder.
+//
+// Files are compared by size and modification time, optionally by SHA-256 content hash stored
+// in the remote file's property bag (HashProperty). The state of the last sync is kept in the local
+// directory (StateFile), it allows telling changed files from unchanged ones on both sides and detecting deletions.
+// Without the state, same size files with different modification times are compared by content.
+// Folders are created as needed, empty folders are not synced.
+//
+//	res, err := dirsync.Sync(sp.Web(), "Shared Documents/Reports", "./reports", &dirsync.Options{
+//		Direction: dirsync.Push,
+//		Exclude:   []string{"*.tmp", ".git/**"},
+//		DryRun:    true,
+//	})
+package dirsync
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/koltyakov/gosip/api"
+)
+
+// HashProperty remote file property bag key of the content SHA-256 hash
+const HashProperty = "GosipContentSHA256"
+
+// StateFile the last sync state file name, it's stored in the local directory root and is never synced
+const StateFile = ".gosipsync.json"
+
+// Direction sync direction
+type Direction string
+
+// Sync directions
+const (
+	Both Direction = "both" // two-way sync, changes are applied in both directions
+	Push Direction = "push" // local directory is the source of truth
+	Pull Direction = "pull" // library folder is the source of truth
+)
+
+// ConflictPolicy resolves files changed on both sides in two-way sync
+type ConflictPolicy string
+
+// Conflict policies
+const (
+	LocalWins  ConflictPolicy = "local"  // local file is uploaded
+	RemoteWins ConflictPolicy = "remote" // remote file is downloaded
+	KeepBoth   ConflictPolicy = "both"   // local file is renamed to a conflict copy and uploaded, remote file is downloaded
+)
+
+// Op sync action operation
+type Op string
+
+// Sync operations
+const (
+	Upload       Op = "upload"
+	Download     Op = "download"
+	DeleteLocal  Op = "delete-local"
+	DeleteRemote Op = "delete-remote" // remote files are moved to the recycle bin
+	RenameLocal  Op = "rename-local"  // conflict copy of a local file
+)
+
+// Options sync options
+type Options struct {
+	Direction Direction      // Both by default
+	Conflict  ConflictPolicy // KeepBoth by default
+
+	// Delete propagates deletions: files deleted on one side since the last sync are deleted on the other side
+	// in two-way sync, files missing in the source are deleted in one-way sync
+	Delete bool
+
+	// Exclude glob patterns, matched against slash separated relative paths and base names,
+	// `dir/**` patterns exclude folders content
+	Exclude []string
+
+	Hash      bool // compare content hashes, hashes of uploaded files are saved to HashProperty
+	DryRun    bool // plan actions without applying them
+	ChunkSize int  // upload chunk size, see api.UploadOptions
+
+	OnAction func(action *Action) // optional callback, is called after each action is applied or planned in dry run
+}
+
+// Action sync action
+type Action struct {
+	Op     Op
+	Path   string // slash separated path relative to the synced folders
+	From   string // original path of the renamed local file
+	Reason string
+	Err    error
+}
+
+// String formats action for the output
+func (a *Action) String() string {
+	target := a.Path
+	if a.From != "" {
+		target = a.From + " -> " + a.Path
+	}
+	if a.Err != nil {
+		return fmt.Sprintf("%s %s (%s): %s", a.Op, target, a.Reason, a.Err)
+	}
+	return fmt.Sprintf("%s %s (%s)", a.Op, target, a.Reason)
+}
+
+// Result sync outcome
+type Result struct {
+	Actions []*Action
+	Failed  int
+}
+
+// Sync compares the local directory with the library folder and uploads, downloads or deletes files to converge.
+// Actions are applied sequentially, failed actions are reported in Result and the rest are applied.
+// An error is returned when the trees can't be listed, the state can't be saved or some actions failed.
+func Sync(web *api.Web, folderURL string, dir string, options *Options) (*Result, error) {
+	opts := &Options{}
+	if options != nil {
+		*opts = *options
+	}
+	if opts.Direction == "" {
+		opts.Direction = Both
+	}
+	if opts.Conflict == "" {
+		opts.Conflict = KeepBoth
+	}
+	switch opts.Direction {
+	case Both, Push, Pull:
+	default:
+		return nil, fmt.Errorf("unknown sync direction %s", opts.Direction)
+	}
+	switch opts.Conflict {
+	case LocalWins, RemoteWins, KeepBoth:
+	default:
+		return nil, fmt.Errorf("unknown conflict policy %s", opts.Conflict)
+	}
+
+	s := &syncer{web: web, dir: dir, options: opts, folders: map[string]bool{}}
+	if err := s.loadState(folderURL); err != nil {
+		return nil, err
+	}
+	local, err := s.scanLocal()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.scanRemote(folderURL)
+	if err != nil {
+		return nil, err
+	}
+	actions, err := s.plan(local, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Actions: actions}
+	skipped := map[string]bool{} // paths of conflicts without a local copy, remote version must not overwrite local file
+	for _, a := range actions {
+		if !opts.DryRun {
+			if skipped[a.Path] {
+				a.Err = fmt.Errorf("skipped as conflict copy is not created")
+			} else {
+				a.Err = s.apply(a, remote)
+			}
+			if a.Err != nil {
+				res.Failed++
+				if a.Op == RenameLocal {
+					skipped[a.From], skipped[a.Path] = true, true
+				}
+			}
+		}
+		if opts.OnAction != nil {
+			opts.OnAction(a)
+		}
+	}
+	if opts.DryRun {
+		return res, nil
+	}
+	if err := s.saveState(folderURL); err != nil {
+		return res, err
+	}
+	if res.Failed > 0 {
+		return res, fmt.Errorf("%d of %d actions failed", res.Failed, len(actions))
+	}
+	return res, nil
+}
+
+// fileInfo local or remote file size and modification time
+type fileInfo struct {
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// syncer sync state
+type syncer struct {
+	web     *api.Web
+	dir     string
+	root    string // library folder server relative URL
+	options *Options
+	state   map[string]*stateEntry
+	folders map[string]bool // ensured remote folders
+}
+
+// plan compares local and remote trees with the last sync state
+func (s *syncer) plan(local map[string]*fileInfo, remote map[string]*fileInfo) ([]*Action, error) {
+	paths := map[string]bool{}
+	for _, m := range []map[string]*fileInfo{local, remote} {
+		for p := range m {
+			paths[p] = true
+		}
+	}
+	for p := range s.state {
+		paths[p] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	var actions []*Action
+	add := func(op Op, p string, reason string) {
+		actions = append(actions, &Action{Op: op, Path: p, Reason: reason})
+	}
+	dir := s.options.Direction
+	for _, p := range sorted {
+		l, r, st := local[p], remote[p], s.state[p]
+		localChanged := l != nil && (st == nil || !st.Local.equal(l))
+		remoteChanged := r != nil && (st == nil || !st.Remote.equal(r))
+
+		switch {
+		case l == nil && r == nil:
+			delete(s.state, p)
+
+		case l != nil && r != nil:
+			if !localChanged && !remoteChanged {
+				continue
+			}
+			if st == nil || (localChanged && remoteChanged) {
+				same, err := s.same(p, l, r, st == nil)
+				if err != nil {
+					return nil, err
+				}
+				if same {
+					s.state[p] = &stateEntry{Local: *l, Remote: *r}
+					continue
+				}
+			}
+			switch {
+			case dir == Push:
+				add(Upload, p, "changed")
+			case dir == Pull:
+				add(Download, p, "changed")
+			case localChanged && !remoteChanged:
+				add(Upload, p, "changed locally")
+			case remoteChanged && !localChanged:
+				add(Download, p, "changed remotely")
+			case s.options.Conflict == LocalWins:
+				add(Upload, p, "conflict, local wins")
+			case s.options.Conflict == RemoteWins:
+				add(Download, p, "conflict, remote wins")
+			default:
+				copyPath := conflictPath(p, time.Now())
+				actions = append(actions, &Action{Op: RenameLocal, Path: copyPath, From: p, Reason: "conflict, keep both"})
+				add(Upload, copyPath, "conflict, keep both")
+				add(Download, p, "conflict, keep both")
+			}
+
+		case l != nil:
+			switch {
+			case dir == Pull && s.options.Delete:
+				add(DeleteLocal, p, "missing remotely")
+			case dir == Pull:
+			case dir == Both && st != nil && !localChanged && s.options.Delete:
+				add(DeleteLocal, p, "deleted remotely")
+			case st != nil:
+				add(Upload, p, "missing remotely")
+			default:
+				add(Upload, p, "new")
+			}
+
+		default:
+			switch {
+			case dir == Push && s.options.Delete:
+				add(DeleteRemote, p, "missing locally")
+			case dir == Push:
+			case dir == Both && st != nil && !remoteChanged && s.options.Delete:
+				add(DeleteRemote, p, "deleted locally")
+			case st != nil:
+				add(Download, p, "missing locally")
+			default:
+				add(Download, p, "new")
+			}
+		}
+	}
+	return actions, nil
+}
+
+// same checks the local and the remote files are the same, by hashes when Hash option is on.
+// Same size files without the last sync state are compared by content, the remote content is hashed
+// when there is no saved hash, so an already mirrored tree isn't treated as conflicting on the first sync.
+func (s *syncer) same(p string, l *fileInfo, r *fileInfo, first bool) (bool, error) {
+	if l.Size != r.Size {
+		return false, nil
+	}
+	if !s.options.Hash && (!first || l.equal(r)) {
+		return l.equal(r), nil
+	}
+	var remoteHash string
+	var err error
+	if s.options.Hash {
+		if remoteHash, err = s.remoteHash(p); err != nil {
+			return false, err
+		}
+	}
+	if remoteHash == "" {
+		if !first {
+			return false, nil
+		}
+		if remoteHash, err = s.remoteContentHash(p); err != nil {
+			return false, err
+		}
+	}
+	localHash, err := s.localHash(p)
+	if err != nil {
+		return false, err
+	}
+	return localHash == remoteHash, nil
+}
+
+// equal compares sizes and modification times, the times are compared with a second precision
+func (f fileInfo) equal(other *fileInfo) bool {
+	return f.Size == other.Size && f.Modified.Truncate(time.Second).Equal(other.Modified.Truncate(time.Second))
+}
+
+// conflictPath gets local conflict copy path, e.g. "dir/report (conflict 20200102-150405).txt"
+func conflictPath(p string, now time.Time) string {
+	ext := path.Ext(p)
+	return fmt.Sprintf("%s (conflict %s)%s", strings.TrimSuffix(p, ext), now.Format("20060102-150405"), ext)
+}
+
+// excluded checks the path matches exclusion patterns
+func (s *syncer) excluded(p string) bool {
+	if p == StateFile {
+		return true
+	}
+	for _, pattern := range s.options.Exclude {
+		if prefix := strings.TrimSuffix(pattern, "/**"); prefix != pattern {
+			if p == prefix || strings.HasPrefix(p, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(p)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,274 @@
+package dirsync
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/koltyakov/gosip/api"
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestExcluded(t *testing.T) {
+	s := &syncer{options: &Options{Exclude: []string{"*.tmp", "node_modules/**", "docs/draft-*"}}}
+	cases := map[string]bool{
+		StateFile:                  true,
+		"a.tmp":                    true,
+		"sub/b.tmp":                true,
+		"node_modules":             true,
+		"node_modules/pkg/a.js":    true,
+		"sub/node_modules/a.js":    false,
+		"docs/draft-1.md":          true,
+		"docs/final.md":            false,
+		"draft-1.md":               false,
+		"a.txt":                    false,
+		"node_modules_backup/a.js": false,
+	}
+	for p, expected := range cases {
+		if actual := s.excluded(p); actual != expected {
+			t.Errorf("%s: expected %t, got %t", p, expected, actual)
+		}
+	}
+}
+
+func TestConflictPath(t *testing.T) {
+	now := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+	cases := map[string]string{
+		"report.docx":     "report (conflict 20200102-150405).docx",
+		"dir/notes":       "dir/notes (conflict 20200102-150405)",
+		"dir.v2/file.txt": "dir.v2/file (conflict 20200102-150405).txt",
+	}
+	for p, expected := range cases {
+		if actual := conflictPath(p, now); actual != expected {
+			t.Errorf("%s: expected %s, got %s", p, expected, actual)
+		}
+	}
+}
+
+// mockModes headers presets the offline tests run with
+var mockModes = map[string]*api.RequestConfig{
+	"Verbose":         api.HeadersPresets.Verbose,
+	"Minimalmetadata": api.HeadersPresets.Minimalmetadata,
+	"Nometadata":      api.HeadersPresets.Nometadata,
+}
+
+// newMockSP creates SP object bound to the mock server with the mode headers preset
+func newMockSP(t *testing.T, srv *spmock.Server, mode string) *api.SP {
+	t.Helper()
+	return api.NewSP(srv.Client()).Conf(mockModes[mode])
+}
+
+func TestDirSync(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+	web := api.NewSP(srv.Client()).Web()
+	remotePath := spmock.SitePath + "/Shared Documents/Sync/"
+	for fileURL, content := range map[string]string{"a.txt": "remote a", "sub/b.txt": "remote b"} {
+		if err := srv.AddFile(remotePath+fileURL, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "gosip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	writeLocal := func(t *testing.T, p string, content string) {
+		t.Helper()
+		localPath := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(localPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readLocal := func(p string) string {
+		data, _ := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+		return string(data)
+	}
+	readRemote := func(p string) string {
+		data, _ := web.GetFile(remotePath + p).Download()
+		return string(data)
+	}
+	writeLocal(t, "c.txt", "local c")
+	writeLocal(t, "skip.tmp", "temp")
+	writeLocal(t, "node/x.txt", "excluded")
+
+	sync := func(t *testing.T, options *Options) []string {
+		t.Helper()
+		options.Exclude = []string{"*.tmp", "node/**"}
+		res, err := Sync(web, "Shared Documents/Sync", dir, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actions []string
+		for _, a := range res.Actions {
+			actions = append(actions, fmt.Sprintf("%s %s", a.Op, a.Path))
+		}
+		return actions
+	}
+
+	t.Run("Initial", func(t *testing.T) {
+		actions := sync(t, &Options{})
+		if fmt.Sprint(actions) != "[download a.txt upload c.txt download sub/b.txt]" {
+			t.Errorf("unexpected actions: %v", actions)
+		}
+		if readLocal("sub/b.txt") != "remote b" || readRemote("c.txt") != "local c" {
+			t.Error("files are not synced")
+		}
+		if readRemote("skip.tmp") != "" || readRemote("node/x.txt") != "" {
+			t.Error("excluded files are synced")
+		}
+		if actions := sync(t, &Options{}); len(actions) != 0 {
+			t.Errorf("unexpected actions of synced trees: %v", actions)
+		}
+	})
+
+	t.Run("Changes", func(t *testing.T) {
+		writeLocal(t, "c.txt", "local c changed")
+		if err := srv.AddFile(remotePath+"a.txt", []byte("remote a changed")); err != nil {
+			t.Fatal(err)
+		}
+		actions := sync(t, &Options{})
+		if fmt.Sprint(actions) != "[download a.txt upload c.txt]" {
+			t.Errorf("unexpected actions: %v", actions)
+		}
+		if readLocal("a.txt") != "remote a changed" || readRemote("c.txt") != "local c changed" {
+			t.Error("changes are not synced")
+		}
+	})
+
+	t.Run("Conflicts", func(t *testing.T) {
+		conflict := func(t *testing.T, local string, remote string) {
+			writeLocal(t, "a.txt", local)
+			if err := srv.AddFile(remotePath+"a.txt", []byte(remote)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		conflict(t, "local wins", "remote loses")
+		if actions := sync(t, &Options{Conflict: LocalWins}); fmt.Sprint(actions) != "[upload a.txt]" {
+			t.Errorf("unexpected actions: %v", actions)
+		}
+		if readRemote("a.txt") != "local wins" {
+			t.Error("local version is not uploaded")
+		}
+
+		conflict(t, "local version", "remote version")
+		actions := sync(t, &Options{})
+		if len(actions) != 3 || !strings.HasPrefix(actions[0], "rename-local a (conflict ") || actions[2] != "download a.txt" {
+			t.Fatalf("unexpected actions: %v", actions)
+		}
+		copyPath := strings.TrimPrefix(actions[1], "upload ")
+		if readLocal("a.txt") != "remote version" || readLocal(copyPath) != "local version" || readRemote(copyPath) != "local version" {
+			t.Error("both versions are not kept")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(dir, "sub", "b.txt")); err != nil {
+			t.Fatal(err)
+		}
+		if actions := sync(t, &Options{}); fmt.Sprint(actions) != "[download sub/b.txt]" {
+			t.Errorf("deleted file is not restored without Delete option: %v", actions)
+		}
+		if err := os.Remove(filepath.Join(dir, "sub", "b.txt")); err != nil {
+			t.Fatal(err)
+		}
+		if actions := sync(t, &Options{Delete: true}); fmt.Sprint(actions) != "[delete-remote sub/b.txt]" {
+			t.Errorf("unexpected actions: %v", actions)
+		}
+		if _, err := web.GetFile(remotePath + "sub/b.txt").Get(); err == nil {
+			t.Error("remote file is not deleted")
+		}
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		writeLocal(t, "d.txt", "new local")
+		actions := sync(t, &Options{Direction: Pull, Delete: true, DryRun: true})
+		if fmt.Sprint(actions) != "[delete-local d.txt]" {
+			t.Errorf("unexpected actions: %v", actions)
+		}
+		if readLocal("d.txt") != "new local" {
+			t.Error("dry run changed files")
+		}
+	})
+
+	t.Run("Hash", func(t *testing.T) {
+		if actions := sync(t, &Options{Direction: Push, Hash: true}); fmt.Sprint(actions) != "[upload d.txt]" {
+			t.Errorf("unexpected actions: %v", actions)
+		}
+		props, err := web.GetFile(remotePath + "d.txt").Props().GetProps([]string{HashProperty})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if props[HashProperty] != fmt.Sprintf("%x", sha256.Sum256([]byte("new local"))) {
+			t.Errorf("unexpected hash property: %v", props)
+		}
+
+		// Without the state files are compared by hashes
+		if err := os.Remove(filepath.Join(dir, StateFile)); err != nil {
+			t.Fatal(err)
+		}
+		writeLocal(t, "d.txt", "new local")
+		if actions := sync(t, &Options{Direction: Push, Hash: true}); strings.Contains(fmt.Sprint(actions), "d.txt") {
+			t.Errorf("unchanged file is synced: %v", actions)
+		}
+	})
+}
+
+func TestDirSyncMirrored(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+	web := api.NewSP(srv.Client()).Web()
+	remotePath := spmock.SitePath + "/Shared Documents/Mirror/"
+
+	dir, err := ioutil.TempDir("", "gosip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	modified := time.Now().Add(-48 * time.Hour)
+	for p, content := range map[string][2]string{
+		"same.txt":   {"same content", "same content"},
+		"differ.txt": {"local content", "other content"},
+	} {
+		if err := srv.AddFile(remotePath+p, []byte(content[1])); err != nil {
+			t.Fatal(err)
+		}
+		localPath := filepath.Join(dir, p)
+		if err := ioutil.WriteFile(localPath, []byte(content[0]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(localPath, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := Sync(web, "Shared Documents/Mirror", dir, &Options{Conflict: RemoteWins})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, a := range res.Actions {
+		actions = append(actions, fmt.Sprintf("%s %s", a.Op, a.Path))
+	}
+	if fmt.Sprint(actions) != "[download differ.txt]" {
+		t.Errorf("same content files are expected to be skipped on the first sync: %v", actions)
+	}
+
+	res, err = Sync(web, "Shared Documents/Mirror", dir, &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Actions) != 0 {
+		t.Errorf("unexpected actions of synced trees: %v", res.Actions)
+	}
+}
@@ -0,0 +1,138 @@
+package dirsync
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// syncState the last sync state persisted in StateFile
+type syncState struct {
+	Folder string                 `json:"folder"`
+	Files  map[string]*stateEntry `json:"files"`
+}
+
+// stateEntry file sides as they were after the last sync
+type stateEntry struct {
+	Local  fileInfo `json:"local"`
+	Remote fileInfo `json:"remote"`
+}
+
+// loadState reads the last sync state, the state of a different library folder is ignored
+func (s *syncer) loadState(folderURL string) error {
+	s.state = map[string]*stateEntry{}
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, StateFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	state := &syncState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return fmt.Errorf("can't read sync state: %w", err)
+	}
+	if state.Folder == folderURL && state.Files != nil {
+		s.state = state.Files
+	}
+	return nil
+}
+
+// saveState writes the sync state atomically
+func (s *syncer) saveState(folderURL string) error {
+	data, err := json.MarshalIndent(&syncState{Folder: folderURL, Files: s.state}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	statePath := filepath.Join(s.dir, StateFile)
+	if err := ioutil.WriteFile(statePath+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(statePath+".tmp", statePath)
+}
+
+// scanLocal lists local files, a missing directory is empty unless it's the sync source
+func (s *syncer) scanLocal() (map[string]*fileInfo, error) {
+	files := map[string]*fileInfo{}
+	if _, err := os.Stat(s.dir); os.IsNotExist(err) && s.options.Direction != Push {
+		return files, nil
+	}
+	err := filepath.Walk(s.dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.dir, filePath)
+		if err != nil || rel == "." {
+			return err
+		}
+		p := filepath.ToSlash(rel)
+		if s.excluded(p) || strings.HasPrefix(info.Name(), tempPrefix) || p == StateFile+".tmp" {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			files[p] = &fileInfo{Size: info.Size(), Modified: info.ModTime().UTC()}
+		}
+		return nil
+	})
+	return files, err
+}
+
+// tempPrefix downloaded files temporary names prefix
+const tempPrefix = ".gosipsync-"
+
+// localPath gets OS path of a relative slash separated path
+func (s *syncer) localPath(p string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(p))
+}
+
+// localInfo gets local file size and modification time
+func (s *syncer) localInfo(p string) (*fileInfo, error) {
+	info, err := os.Stat(s.localPath(p))
+	if err != nil {
+		return nil, err
+	}
+	return &fileInfo{Size: info.Size(), Modified: info.ModTime().UTC()}, nil
+}
+
+// localHash gets local file content hash
+func (s *syncer) localHash(p string) (string, error) {
+	f, err := os.Open(s.localPath(p))
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// renameLocal renames local file to its conflict copy
+func (s *syncer) renameLocal(from string, to string) error {
+	if err := os.Rename(s.localPath(from), s.localPath(to)); err != nil {
+		return err
+	}
+	delete(s.state, from)
+	return nil
+}
+
+// deleteLocal removes local file
+func (s *syncer) deleteLocal(p string) error {
+	if err := os.Remove(s.localPath(p)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(s.state, p)
+	return nil
+}
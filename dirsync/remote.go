@@ -0,0 +1,216 @@
+package dirsync
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/koltyakov/gosip/api"
+)
+
+// scanRemote lists library folder files, a missing folder is empty unless it's the sync source
+func (s *syncer) scanRemote(folderURL string) (map[string]*fileInfo, error) {
+	files := map[string]*fileInfo{}
+	folder := s.web.GetFolder(folderURL)
+	folderResp, err := folder.Select("ServerRelativeUrl").Get()
+	if err != nil {
+		if !isNotFound(err) || s.options.Direction == Pull {
+			return nil, err
+		}
+		s.root = strings.TrimSuffix(folderURL, "/")
+		if s.options.DryRun {
+			return files, nil
+		}
+		data, err := s.web.EnsureFolder(folderURL)
+		if err != nil {
+			return nil, err
+		}
+		ensured := api.FolderResp(data)
+		s.root = ensured.Data().ServerRelativeURL
+		return files, nil
+	}
+	s.root = folderResp.Data().ServerRelativeURL
+
+	err = s.web.GetFolder(s.root).Walk(func(entry *api.WalkEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if s.excluded(entry.Path) {
+			if entry.IsDir {
+				return api.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir {
+			files[entry.Path] = &fileInfo{Size: int64(entry.File.Length), Modified: entry.File.TimeLastModified.UTC()}
+		}
+		return nil
+	}, nil)
+	return files, err
+}
+
+// isNotFound checks the error is HTTP 404 response, HTTP errors are "<status> :: <details>" wrapped by the API client
+func isNotFound(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if strings.HasPrefix(err.Error(), "404 ") {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteFile gets remote file by its relative path
+func (s *syncer) remoteFile(p string) *api.File {
+	return s.web.GetFile(strings.Replace(s.root+"/"+p, "'", "''", -1))
+}
+
+// remoteHash gets remote file content hash saved in the property bag, empty when there is no hash
+func (s *syncer) remoteHash(p string) (string, error) {
+	props, err := s.remoteFile(p).Props().GetProps([]string{HashProperty})
+	if err != nil {
+		return "", err
+	}
+	return props[HashProperty], nil
+}
+
+// remoteContentHash gets remote file content hash by reading the file stream
+func (s *syncer) remoteContentHash(p string) (string, error) {
+	body, err := s.remoteFile(p).GetReader()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = body.Close() }()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// upload uploads local file, the parent folders are created as needed
+func (s *syncer) upload(p string) error {
+	parent := s.root
+	if dir := path.Dir(p); dir != "." {
+		parent += "/" + dir
+		if !s.folders[dir] {
+			if _, err := s.web.EnsureFolder(parent); err != nil {
+				return err
+			}
+			s.folders[dir] = true
+		}
+	}
+
+	local, err := s.localInfo(p)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(s.localPath(p))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	var content io.Reader = f
+	var hash hash.Hash
+	if s.options.Hash {
+		hash = sha256.New()
+		content = io.TeeReader(f, hash)
+	}
+	folder := s.web.GetFolder(strings.Replace(parent, "'", "''", -1))
+	_, err = folder.Files().Upload(path.Base(p), content, local.Size, &api.UploadOptions{
+		Overwrite: true,
+		ChunkSize: s.options.ChunkSize,
+	})
+	if err != nil {
+		return err
+	}
+	if hash != nil {
+		if err := s.remoteFile(p).Props().Set(HashProperty, fmt.Sprintf("%x", hash.Sum(nil))); err != nil {
+			return err
+		}
+	}
+
+	// The remote modification time is requested after the hash is saved as the property update changes it
+	resp, err := s.remoteFile(p).Select("Length,TimeLastModified").Get()
+	if err != nil {
+		return err
+	}
+	info := resp.Data()
+	s.state[p] = &stateEntry{
+		Local:  *local,
+		Remote: fileInfo{Size: int64(info.Length), Modified: info.TimeLastModified.UTC()},
+	}
+	return nil
+}
+
+// download downloads remote file through a temporary file, the local modification time is set to the remote one
+func (s *syncer) download(p string, remote *fileInfo) error {
+	localPath := s.localPath(p)
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+	body, err := s.remoteFile(p).GetReader()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+
+	f, err := ioutil.TempFile(filepath.Dir(localPath), tempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, body)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), localPath)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := os.Chtimes(localPath, remote.Modified, remote.Modified); err != nil {
+		return err
+	}
+
+	local, err := s.localInfo(p)
+	if err != nil {
+		return err
+	}
+	s.state[p] = &stateEntry{Local: *local, Remote: *remote}
+	return nil
+}
+
+// deleteRemote moves remote file to the recycle bin
+func (s *syncer) deleteRemote(p string) error {
+	if err := s.remoteFile(p).Recycle(); err != nil && !isNotFound(err) {
+		return err
+	}
+	delete(s.state, p)
+	return nil
+}
+
+// apply applies sync action
+func (s *syncer) apply(a *Action, remote map[string]*fileInfo) error {
+	switch a.Op {
+	case Upload:
+		return s.upload(a.Path)
+	case Download:
+		return s.download(a.Path, remote[a.Path])
+	case DeleteLocal:
+		return s.deleteLocal(a.Path)
+	case DeleteRemote:
+		return s.deleteRemote(a.Path)
+	case RenameLocal:
+		return s.renameLocal(a.From, a.Path)
+	}
+	return fmt.Errorf("unknown sync operation %s", a.Op)
+}
@@ -0,0 +1,299 @@
+// Package faults provides fault-injection transport for SPClient.
+//
+// The transport injects throttling, service unavailability, authentication errors,
+// connection resets, slow responses and truncated bodies, either randomly with a probability
+// or as scripted sequences per URL pattern. It allows verifying retry policies,
+// digest acquisition and cancellation logic without a real tenant:
+//
+//	ft := faults.New(
+//		faults.Script(`/_api/web$`, faults.Throttle(1), faults.Unavailable(), faults.Pass()),
+//		faults.Random(`/_api/`, 0.1, faults.Reset()),
+//	)
+//	ft.Attach(client)
+package faults
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/koltyakov/gosip"
+)
+
+// Kind fault kind
+type Kind int
+
+const (
+	// KindPass passes the request through without a fault
+	KindPass Kind = iota
+	// KindThrottle responds with 429 Too Many Requests and Retry-After header
+	KindThrottle
+	// KindUnavailable responds with 503 Service Unavailable
+	KindUnavailable
+	// KindUnauthorized responds with 401 Unauthorized
+	KindUnauthorized
+	// KindReset fails with connection reset by peer error
+	KindReset
+	// KindSlow delays the request before sending it
+	KindSlow
+	// KindTruncate cuts the response body, reading it fails with unexpected EOF
+	KindTruncate
+)
+
+// String gets fault kind name
+func (k Kind) String() string {
+	switch k {
+	case KindPass:
+		return "pass"
+	case KindThrottle:
+		return "throttle"
+	case KindUnavailable:
+		return "unavailable"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindReset:
+		return "reset"
+	case KindSlow:
+		return "slow"
+	case KindTruncate:
+		return "truncate"
+	}
+	return "unknown"
+}
+
+// Fault injected fault definition
+type Fault struct {
+	Kind       Kind
+	RetryAfter int           // Retry-After header value in seconds, used with throttle and unavailable faults
+	Delay      time.Duration // slow response delay
+	Keep       int           // number of response body bytes kept by truncate fault, a half when not positive, up to the whole body
+}
+
+// Pass creates no fault, used in scripted sequences to let a request through
+func Pass() Fault { return Fault{Kind: KindPass} }
+
+// Throttle creates 429 fault with Retry-After header
+func Throttle(retryAfter int) Fault { return Fault{Kind: KindThrottle, RetryAfter: retryAfter} }
+
+// Unavailable creates 503 fault
+func Unavailable() Fault { return Fault{Kind: KindUnavailable} }
+
+// Unauthorized creates 401 fault
+func Unauthorized() Fault { return Fault{Kind: KindUnauthorized} }
+
+// Reset creates connection reset fault
+func Reset() Fault { return Fault{Kind: KindReset} }
+
+// Slow creates slow response fault
+func Slow(delay time.Duration) Fault { return Fault{Kind: KindSlow, Delay: delay} }
+
+// Truncate creates truncated body fault, keep is a number of body bytes to send,
+// a half of the body is sent when keep is not positive, the whole body when keep exceeds its length
+func Truncate(keep int) Fault { return Fault{Kind: KindTruncate, Keep: keep} }
+
+// Rule faults injection rule for requests matching URL pattern
+type Rule struct {
+	Pattern     *regexp.Regexp // request URL pattern, matches all requests when nil
+	Method      string         // request method, matches any method when empty
+	Sequence    []Fault        // scripted faults applied to matching requests in order, then requests pass
+	Probability float64        // probability of injecting Fault when no sequence is defined, 0..1
+	Fault       Fault          // randomly injected fault
+
+	pos int
+}
+
+// Script creates rule with scripted faults sequence for requests matching the pattern
+func Script(pattern string, sequence ...Fault) *Rule {
+	return &Rule{Pattern: compilePattern(pattern), Sequence: sequence}
+}
+
+// Random creates rule injecting the fault with a probability for requests matching the pattern
+func Random(pattern string, probability float64, fault Fault) *Rule {
+	return &Rule{Pattern: compilePattern(pattern), Probability: probability, Fault: fault}
+}
+
+func compilePattern(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		return nil
+	}
+	return regexp.MustCompile("(?i)" + pattern)
+}
+
+// Transport fault-injection HTTP transport
+type Transport struct {
+	Transport http.RoundTripper // underlying transport, http.DefaultTransport when not set
+	Rules     []*Rule           // rules are checked in order, the first injected fault wins
+	Rand      *rand.Rand        // random source for probability rules
+
+	mu    sync.Mutex
+	stats map[Kind]int
+}
+
+// New creates fault-injection transport
+func New(rules ...*Rule) *Transport {
+	return &Transport{
+		Rules: rules,
+		Rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		stats: map[Kind]int{},
+	}
+}
+
+// Attach plugs the transport into SPClient wrapping the client's transport
+func (t *Transport) Attach(client *gosip.SPClient) {
+	if client.Transport != nil && client.Transport != t {
+		t.Transport = client.Transport
+	}
+	client.Transport = t
+}
+
+// Injected gets a number of injected faults of the kind
+func (t *Transport) Injected(kind Kind) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats[kind]
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault := t.next(req)
+	switch fault.Kind {
+	case KindThrottle:
+		return errorResponse(req, http.StatusTooManyRequests, fault.RetryAfter,
+			"-2147024860, Microsoft.SharePoint.SPQueryThrottledException", "The request has been throttled."), nil
+	case KindUnavailable:
+		return errorResponse(req, http.StatusServiceUnavailable, fault.RetryAfter,
+			"-1, System.InvalidOperationException", "The server is currently unavailable."), nil
+	case KindUnauthorized:
+		return errorResponse(req, http.StatusUnauthorized, 0,
+			"-2147024891, System.UnauthorizedAccessException", "Access denied."), nil
+	case KindReset:
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	case KindSlow:
+		if err := sleep(req.Context(), fault.Delay); err != nil {
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil || fault.Kind != KindTruncate {
+		return resp, err
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	keep := fault.Keep
+	if keep <= 0 {
+		keep = len(data) / 2
+	}
+	if keep > len(data) {
+		keep = len(data) // the body is sent completely, still reading it fails with unexpected EOF
+	}
+	resp.Body = ioutil.NopCloser(io.MultiReader(
+		bytes.NewReader(data[:keep]),
+		&errReader{err: io.ErrUnexpectedEOF},
+	))
+	resp.ContentLength = int64(len(data))
+	return resp, nil
+}
+
+// next resolves the fault for the request and registers it in stats
+func (t *Transport) next(req *http.Request) Fault {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stats == nil {
+		t.stats = map[Kind]int{}
+	}
+	fault := Pass()
+	for _, rule := range t.Rules {
+		if rule.Method != "" && rule.Method != req.Method {
+			continue
+		}
+		if rule.Pattern != nil && !rule.Pattern.MatchString(req.URL.String()) {
+			continue
+		}
+		if len(rule.Sequence) > 0 {
+			if rule.pos >= len(rule.Sequence) {
+				continue
+			}
+			fault = rule.Sequence[rule.pos]
+			rule.pos++
+		} else if rule.Probability > 0 && t.random() < rule.Probability {
+			fault = rule.Fault
+		}
+		if fault.Kind != KindPass {
+			break
+		}
+	}
+	t.stats[fault.Kind]++
+	return fault
+}
+
+func (t *Transport) random() float64 {
+	if t.Rand == nil {
+		t.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return t.Rand.Float64()
+}
+
+// errorResponse creates SharePoint-like error response
+func errorResponse(req *http.Request, statusCode int, retryAfter int, code string, message string) *http.Response {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	body := fmt.Sprintf(`{"error":{"code":"%s","message":{"lang":"en-US","value":"%s"}}}`, code, message)
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json;odata=verbose;charset=utf-8"}},
+		Body:          ioutil.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	if retryAfter > 0 {
+		resp.Header.Set("Retry-After", strconv.Itoa(retryAfter))
+	}
+	return resp
+}
+
+// sleep waits for the delay or the context cancellation
+func sleep(ctx context.Context, delay time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// errReader reader which always fails
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read(p []byte) (int, error) { return 0, r.err }
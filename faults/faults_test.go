@@ -0,0 +1,265 @@
+package faults
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koltyakov/gosip"
+	"github.com/koltyakov/gosip/api"
+	"github.com/koltyakov/gosip/auth/anon"
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func newTestClient(t *testing.T, rules ...*Rule) (*gosip.SPClient, *Transport, func()) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.URL.Path, "/_api/ContextInfo") {
+			_, _ = fmt.Fprintf(w, `{"d":{"GetContextWebInformation":{"FormDigestValue":"FAKE","FormDigestTimeoutSeconds":120,"LibraryVersion":"FAKE"}}}`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"d":{"Title":"Faults %s"}}`, r.URL.Path)
+	}))
+	client := &gosip.SPClient{AuthCnfg: &anon.AuthCnfg{SiteURL: srv.URL}}
+	ft := New(rules...)
+	ft.Attach(client)
+	return client, ft, srv.Close
+}
+
+func execute(client *gosip.SPClient, method string, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequest(method, client.AuthCnfg.GetSiteURL()+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Execute(req)
+}
+
+func TestRetryPolicies(t *testing.T) {
+	t.Run("RetriedUntilSuccess", func(t *testing.T) {
+		client, ft, closer := newTestClient(t, Script(`/_api/web$`, Unavailable(), Unavailable()))
+		defer closer()
+		resp, err := execute(client, "GET", "/_api/web")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if ft.Injected(KindUnavailable) != 2 {
+			t.Errorf("expected 2 injected faults, got %d", ft.Injected(KindUnavailable))
+		}
+	})
+
+	t.Run("NoRetryPolicy", func(t *testing.T) {
+		client, _, closer := newTestClient(t, Script(`/_api/web$`, Unavailable()))
+		defer closer()
+		client.RetryPolicies = map[int]int{503: 0}
+		if _, err := execute(client, "GET", "/_api/web"); err == nil || !strings.HasPrefix(err.Error(), "503") {
+			t.Errorf("503 is expected, got %v", err)
+		}
+	})
+
+	t.Run("ThrottleRetryAfter", func(t *testing.T) {
+		client, _, closer := newTestClient(t, Script(`/_api/web$`, Throttle(120)))
+		defer closer()
+		client.RetryPolicies = map[int]int{429: 0}
+		resp, err := execute(client, "GET", "/_api/web")
+		if err == nil || resp.StatusCode != http.StatusTooManyRequests {
+			t.Fatalf("429 is expected, got %v", err)
+		}
+		if resp.Header.Get("Retry-After") != "120" {
+			t.Errorf("unexpected Retry-After: %s", resp.Header.Get("Retry-After"))
+		}
+	})
+
+	t.Run("ThrottleCanceled", func(t *testing.T) {
+		client, _, closer := newTestClient(t, Script(`/_api/web$`, Throttle(120)))
+		defer closer()
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		req, _ := http.NewRequest("GET", client.AuthCnfg.GetSiteURL()+"/_api/web", nil)
+		started := time.Now()
+		if _, err := client.Execute(req.WithContext(ctx)); err == nil {
+			t.Error("throttling error is expected")
+		}
+		if time.Since(started) > 5*time.Second {
+			t.Error("retry should be canceled with the context")
+		}
+	})
+}
+
+func TestDigestFaults(t *testing.T) {
+	client, ft, closer := newTestClient(t, Script(`/_api/contextinfo`, Unauthorized()))
+	defer closer()
+	client.RetryPolicies = map[int]int{401: 0}
+
+	if _, err := execute(client, "POST", "/_api/web"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("digest error is expected, got %v", err)
+	}
+	resp, err := execute(client, "POST", "/_api/web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if ft.Injected(KindUnauthorized) != 1 {
+		t.Errorf("expected 1 injected fault, got %d", ft.Injected(KindUnauthorized))
+	}
+}
+
+func TestNetworkFaults(t *testing.T) {
+	t.Run("Reset", func(t *testing.T) {
+		client, _, closer := newTestClient(t, Script("", Reset()))
+		defer closer()
+		if _, err := execute(client, "GET", "/_api/web"); err == nil || !strings.Contains(err.Error(), "connection reset") {
+			t.Errorf("connection reset is expected, got %v", err)
+		}
+		if _, err := execute(client, "GET", "/_api/web"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Slow", func(t *testing.T) {
+		client, _, closer := newTestClient(t, Script("", Slow(time.Minute)))
+		defer closer()
+		client.Timeout = 100 * time.Millisecond
+		if _, err := execute(client, "GET", "/_api/web"); err == nil {
+			t.Error("timeout is expected")
+		}
+	})
+
+	t.Run("Truncate", func(t *testing.T) {
+		client, _, closer := newTestClient(t, Script("", Truncate(5)))
+		defer closer()
+		resp, err := execute(client, "GET", "/_api/web")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("unexpected EOF is expected, got %v", err)
+		}
+		if string(data) != `{"d":` {
+			t.Errorf("unexpected truncated body: %s", data)
+		}
+	})
+
+	t.Run("TruncateKeepExceedsBody", func(t *testing.T) {
+		client, _, closer := newTestClient(t, Script("", Truncate(1000)))
+		defer closer()
+		resp, err := execute(client, "GET", "/_api/web")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("unexpected EOF is expected, got %v", err)
+		}
+		if string(data) != `{"d":{"Title":"Faults /_api/web"}}` {
+			t.Errorf("the whole body is expected, got: %s", data)
+		}
+	})
+}
+
+// requestsLog transport which logs request URLs
+type requestsLog struct {
+	mu   sync.Mutex
+	urls []string
+}
+
+func (l *requestsLog) RoundTrip(req *http.Request) (*http.Response, error) {
+	l.mu.Lock()
+	l.urls = append(l.urls, req.URL.String())
+	l.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (l *requestsLog) count(pattern string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rgx := regexp.MustCompile("(?i)" + pattern)
+	cnt := 0
+	for _, u := range l.urls {
+		if rgx.MatchString(u) {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+func TestChunkedUploadCleanup(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	upload := func(t *testing.T, rule *Rule) (*requestsLog, error) {
+		t.Helper()
+		log := &requestsLog{}
+		client := srv.Client()
+		client.Transport = log
+		New(rule).Attach(client)
+		files := api.NewSP(client).Web().GetFolder("Shared Documents").Files()
+		_, err := files.AddChunked("chunked.txt", strings.NewReader("0123456789"), &api.AddChunkedOptions{
+			Overwrite: true,
+			ChunkSize: 4,
+		})
+		return log, err
+	}
+
+	t.Run("ContinueFailed", func(t *testing.T) {
+		log, err := upload(t, Script(`/ContinueUpload`, Reset()))
+		if err == nil || !strings.Contains(err.Error(), "connection reset") {
+			t.Errorf("connection reset is expected, got %v", err)
+		}
+		if log.count(`/CancelUpload`) != 1 {
+			t.Error("upload session should be canceled")
+		}
+	})
+
+	t.Run("FinishFailed", func(t *testing.T) {
+		log, err := upload(t, Script(`/FinishUpload`, Reset()))
+		if err == nil || !strings.Contains(err.Error(), "connection reset") {
+			t.Errorf("connection reset is expected, got %v", err)
+		}
+		if log.count(`/CancelUpload`) != 1 {
+			t.Error("upload session should be canceled")
+		}
+	})
+
+	t.Run("StartFailed", func(t *testing.T) {
+		log, err := upload(t, Script(`/StartUpload`, Reset()))
+		if err == nil {
+			t.Error("connection reset is expected")
+		}
+		if log.count(`/CancelUpload`) != 1 {
+			t.Error("upload session should be canceled as the server may have started it")
+		}
+	})
+}
+
+func TestRandomFaults(t *testing.T) {
+	client, ft, closer := newTestClient(t,
+		Random(`/_api/never`, 1, Reset()),
+		Random(`/_api/web`, 0, Reset()),
+	)
+	defer closer()
+	for i := 0; i < 10; i++ {
+		resp, err := execute(client, "GET", "/_api/web")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+	if _, err := execute(client, "GET", "/_api/never"); err == nil {
+		t.Error("fault is expected")
+	}
+	if ft.Injected(KindPass) != 10 || ft.Injected(KindReset) != 1 {
+		t.Errorf("unexpected stats: pass %d, reset %d", ft.Injected(KindPass), ft.Injected(KindReset))
+	}
+}
@@ -0,0 +1,243 @@
+package listdata
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/koltyakov/gosip/api"
+)
+
+// ExportOptions list data export options
+type ExportOptions struct {
+	Format   Format   // CSV by default
+	Fields   []string // fields to export, visible editable fields by default
+	Filter   string   // optional OData $filter
+	PageSize int      // items page size, 2000 by default
+}
+
+// Export streams list items to w, returns exported items count
+func Export(list *api.List, w io.Writer, options *ExportOptions) (int, error) {
+	opts := &ExportOptions{}
+	if options != nil {
+		*opts = *options
+	}
+	if opts.Format == "" {
+		opts.Format = CSV
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = 2000
+	}
+	if opts.Format != CSV && opts.Format != JSONL {
+		return 0, fmt.Errorf("unsupported format %s", opts.Format)
+	}
+
+	allFields, err := getFields(list)
+	if err != nil {
+		return 0, err
+	}
+	fields, err := exportFields(allFields, opts.Fields)
+	if err != nil {
+		return 0, err
+	}
+
+	items := itemsQuery(list, withCatchAll(allFields, fields)).Top(opts.PageSize)
+	if opts.Filter != "" {
+		items = items.Filter(opts.Filter)
+	}
+
+	var csvWriter *csv.Writer
+	jsonEncoder := json.NewEncoder(w)
+	jsonEncoder.SetEscapeHTML(false)
+	if opts.Format == CSV {
+		csvWriter = csv.NewWriter(w)
+		header := make([]string, len(fields))
+		for i, f := range fields {
+			header[i] = f.InternalName
+		}
+		if err := csvWriter.Write(header); err != nil {
+			return 0, err
+		}
+	}
+
+	count := 0
+	err = items.Iterator().ForEach(func(itemData []byte) error {
+		itemResp := api.ItemResp(itemData)
+		item := itemResp.ToMap()
+		labels := termLabels(item)
+		for _, f := range fields {
+			resolveTermLabels(f, item[f.prop()], labels)
+		}
+		if opts.Format == CSV {
+			record := make([]string, len(fields))
+			for i, f := range fields {
+				record[i] = csvValue(exportValue(f, item[f.prop()]))
+			}
+			if err := csvWriter.Write(record); err != nil {
+				return err
+			}
+		} else {
+			record := map[string]interface{}{}
+			for _, f := range fields {
+				record[f.InternalName] = exportValue(f, item[f.prop()])
+			}
+			if err := jsonEncoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		count++
+		return nil
+	})
+	if csvWriter != nil {
+		csvWriter.Flush()
+		if flushErr := csvWriter.Error(); err == nil {
+			err = flushErr
+		}
+	}
+	return count, err
+}
+
+// itemsQuery gets items query selecting and expanding the fields values
+func itemsQuery(list *api.List, fields []*field) *api.Items {
+	var selects, expands []string
+	for _, f := range fields {
+		prop := f.prop()
+		switch f.kind() {
+		case "Lookup":
+			selects = append(selects, prop+"/Id", prop+"/"+f.lookupField())
+			expands = append(expands, prop)
+		case "User":
+			selects = append(selects, prop+"/Id", prop+"/EMail", prop+"/Name", prop+"/Title")
+			expands = append(expands, prop)
+		default:
+			selects = append(selects, prop)
+		}
+	}
+	items := list.Items().Select(strings.Join(selects, ","))
+	if len(expands) > 0 {
+		items = items.Expand(strings.Join(expands, ","))
+	}
+	return items
+}
+
+// catchAllField taxonomy catch all lookup, its Term projection holds labels of the item's terms by WssId
+var catchAllField = &field{InternalName: "TaxCatchAll", TypeAsString: "LookupMulti", LookupField: "Term"}
+
+// withCatchAll adds the taxonomy catch all lookup to the query fields when managed metadata fields are queried
+func withCatchAll(listFields []*field, fields []*field) []*field {
+	if findField(listFields, catchAllField.InternalName) == nil {
+		return fields
+	}
+	for _, f := range fields {
+		if f.kind() == "TaxonomyFieldType" {
+			return append(fields[:len(fields):len(fields)], catchAllField)
+		}
+	}
+	return fields
+}
+
+// termLabels gets item's terms labels by WssId from the taxonomy catch all lookup
+func termLabels(item map[string]interface{}) map[string]string {
+	labels := map[string]string{}
+	for _, v := range multiValues(item[catchAllField.InternalName]) {
+		if m, ok := v.(map[string]interface{}); ok {
+			labels[toString(m["Id"])] = toString(m["Term"])
+		}
+	}
+	return labels
+}
+
+// resolveTermLabels replaces managed metadata values labels, which hold WssId in REST responses,
+// with the terms labels, labels which can't be resolved are cleared
+func resolveTermLabels(f *field, raw interface{}, labels map[string]string) {
+	if f.kind() != "TaxonomyFieldType" {
+		return
+	}
+	values := []interface{}{raw}
+	if f.multi() {
+		values = multiValues(raw)
+	}
+	for _, v := range values {
+		if m, ok := v.(map[string]interface{}); ok {
+			m["Label"] = labels[toString(m["WssId"])]
+		}
+	}
+}
+
+// exportFields gets fields to export by names, visible editable fields by default
+func exportFields(fields []*field, names []string) ([]*field, error) {
+	var res []*field
+	if len(names) == 0 {
+		for _, f := range fields {
+			if !f.Hidden && !f.ReadOnlyField && !skipTypes[f.TypeAsString] {
+				res = append(res, f)
+			}
+		}
+		return res, nil
+	}
+	for _, name := range names {
+		f := findField(fields, name)
+		if f == nil {
+			return nil, fmt.Errorf("field %s is not found", name)
+		}
+		res = append(res, f)
+	}
+	return res, nil
+}
+
+// exportValue converts item's property value to a re-importable value,
+// multi values are converted to []string
+func exportValue(f *field, raw interface{}) interface{} {
+	if raw == nil {
+		return nil
+	}
+	if f.multi() {
+		values := []string{}
+		for _, v := range multiValues(raw) {
+			values = append(values, exportScalar(f, v))
+		}
+		return values
+	}
+	switch raw.(type) {
+	case float64, bool:
+		return raw
+	}
+	return exportScalar(f, raw)
+}
+
+// exportScalar converts a single value to its string representation
+func exportScalar(f *field, raw interface{}) string {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return toString(raw)
+	}
+	switch f.kind() {
+	case "Lookup":
+		return toString(m[f.lookupField()])
+	case "User":
+		for _, key := range []string{"EMail", "Email", "Name", "LoginName", "Title"} {
+			if s := toString(m[key]); s != "" {
+				return s
+			}
+		}
+		return ""
+	case "TaxonomyFieldType":
+		if label := toString(m["Label"]); label != "" {
+			return label + "|" + toString(m["TermGuid"])
+		}
+		return toString(m["TermGuid"])
+	case "URL":
+		return api.FormURLValue(toString(m["Url"]), toString(m["Description"]))
+	}
+	return toString(raw)
+}
+
+// csvValue converts exported value to CSV cell
+func csvValue(value interface{}) string {
+	if values, ok := value.([]string); ok {
+		return strings.Join(values, multiSeparator)
+	}
+	return toString(value)
+}
@@ -0,0 +1,151 @@
+package listdata
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/koltyakov/gosip/api"
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+// mockModes headers presets the offline tests run with
+var mockModes = map[string]*api.RequestConfig{
+	"Verbose":         api.HeadersPresets.Verbose,
+	"Minimalmetadata": api.HeadersPresets.Minimalmetadata,
+	"Nometadata":      api.HeadersPresets.Nometadata,
+}
+
+// newMockSP creates SP object bound to the mock server with the mode headers preset
+func newMockSP(t *testing.T, srv *spmock.Server, mode string) *api.SP {
+	t.Helper()
+	return api.NewSP(srv.Client()).Conf(mockModes[mode])
+}
+
+func TestListDataExportImport(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	if _, err := srv.AddList("Departments", 100); err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"Sales", "R&D"} {
+		if _, err := srv.AddItem("Departments", map[string]interface{}{"Title": title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, title := range []string{"Source", "Target"} {
+		if _, err := srv.AddList(title, 100); err != nil {
+			t.Fatal(err)
+		}
+		for name, typ := range map[string]string{"Amount": "Number", "Active": "Boolean", "Due": "DateTime", "Owner": "User", "Tags": "MultiChoice"} {
+			if err := srv.AddField(title, name, typ); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := srv.AddLookupField(title, "Department", "Departments"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sp := api.NewSP(srv.Client())
+	owner, err := sp.Web().EnsureUser("jane@mock.local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, dep := range []int{1, 2} {
+		if _, err := srv.AddItem("Source", map[string]interface{}{
+			"Title":        fmt.Sprintf("Item, \"%d\"", i+1),
+			"Amount":       10.5 * float64(i+1),
+			"Active":       i == 0,
+			"Due":          "2020-03-05T10:00:00Z",
+			"OwnerId":      owner.ID,
+			"Tags":         []interface{}{"A", "B"},
+			"DepartmentId": dep,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fields := []string{"Title", "Amount", "Active", "Due", "Owner", "Tags", "Department"}
+	imported := 0
+	for _, format := range []Format{CSV, JSONL} {
+		t.Run(string(format), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			options := &ExportOptions{Format: format, Fields: fields, PageSize: 1}
+			count, err := Export(sp.Web().Lists().GetByTitle("Source"), buf, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != 2 {
+				t.Errorf("expected 2 items, got %d", count)
+			}
+			exported := buf.String()
+			for _, value := range []string{"jane@mock.local", "R&D", "2020-03-05T10:00:00Z"} {
+				if !strings.Contains(exported, value) {
+					t.Errorf("%s is expected in export:\n%s", value, exported)
+				}
+			}
+
+			target := sp.Web().Lists().GetByTitle("Target")
+			res, err := Import(target, strings.NewReader(exported), &ImportOptions{Format: format, BatchSize: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Added != 2 || len(res.Errors) != 0 {
+				t.Fatalf("unexpected import result: %+v", res)
+			}
+
+			buf.Reset()
+			options.Filter = fmt.Sprintf("Id gt %d", imported)
+			if _, err := Export(target, buf, options); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != exported {
+				t.Errorf("round trip mismatch:\n%s\nexpected:\n%s", buf.String(), exported)
+			}
+			imported += res.Added
+		})
+	}
+
+	t.Run("UnknownColumn", func(t *testing.T) {
+		_, err := Import(sp.Web().Lists().GetByTitle("Target"), strings.NewReader("Unknown\nvalue\n"), nil)
+		if err == nil {
+			t.Error("unknown column should fail")
+		}
+	})
+
+	t.Run("RowErrors", func(t *testing.T) {
+		data := "Title,Department,Amount\nOK,Sales,1\nBad lookup,Marketing,2\nBad number,Sales,NaN1\n"
+		res, err := Import(sp.Web().Lists().GetByTitle("Target"), strings.NewReader(data), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Added != 1 || len(res.Errors) != 2 || res.Errors[0].Row != 2 || res.Errors[1].Row != 3 {
+			t.Errorf("unexpected import result: %+v", res)
+		}
+	})
+
+	t.Run("FormValuesFailed", func(t *testing.T) {
+		if err := srv.AddField("Target", "Terms", "TaxonomyFieldTypeMulti"); err != nil {
+			t.Fatal(err)
+		}
+		target := sp.Web().Lists().GetByTitle("Target")
+		data := "Title,Terms\nGood term,Term|0f8e4a6b-3c2d-4e5f-8a9b-1c2d3e4f5a6b\nBad term,Term|unknown\n"
+		res, err := Import(target, strings.NewReader(data), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Added != 1 || len(res.Errors) != 1 || res.Errors[0].Row != 2 {
+			t.Errorf("unexpected import result: %+v", res)
+		}
+		// The item created before the failed form values update is deleted
+		items, err := target.Items().Select("Id").Filter("Title eq 'Bad term'").Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items.Data()) != 0 {
+			t.Error("the item of the failed row should be deleted")
+		}
+	})
+}
@@ -0,0 +1,475 @@
+package listdata
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/koltyakov/gosip/api"
+	"github.com/koltyakov/gosip/odata"
+)
+
+// termGUIDRgx matches term GUID without label
+var termGUIDRgx = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// ImportOptions list data import options
+type ImportOptions struct {
+	Format Format // CSV by default
+
+	// Mapping source column to field internal name, by default columns are matched
+	// with fields internal names, entity property names or titles
+	Mapping map[string]string
+
+	// UserMap maps exported users to target logon names, e.g. on-premise logins to SharePoint Online emails
+	UserMap map[string]string
+
+	BatchSize int // items added with a single $batch request, 10 by default
+}
+
+// ImportResult list data import outcome
+type ImportResult struct {
+	Added  int
+	Errors []*RowError // rows failed to import
+}
+
+// RowError import row error
+type RowError struct {
+	Row int // data row number, starting from 1
+	Err error
+}
+
+// Error returns row error message
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+// Unwrap returns row's underlying error
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Import reads items from r and adds them to the list with $batch requests. Users are resolved with Web.EnsureUser,
+// lookups are resolved by the lookup field value. Rows failed to convert or add are reported in ImportResult.Errors,
+// an error is returned when the data can't be read or the columns can't be mapped to the list fields.
+func Import(list *api.List, r io.Reader, options *ImportOptions) (*ImportResult, error) {
+	opts := &ImportOptions{}
+	if options != nil {
+		*opts = *options
+	}
+	if opts.Format == "" {
+		opts.Format = CSV
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 10
+	}
+
+	fields, err := getFields(list)
+	if err != nil {
+		return nil, err
+	}
+	imp := newImporter(list, fields, opts.Mapping, opts.UserMap)
+
+	var next func() (map[string]interface{}, error)
+	switch opts.Format {
+	case CSV:
+		next, err = csvRows(r)
+		if err != nil {
+			return nil, err
+		}
+	case JSONL:
+		next = jsonlRows(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %s", opts.Format)
+	}
+
+	res := &ImportResult{}
+	var batch []*importRow
+	for rowNum := 1; ; rowNum++ {
+		values, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return res, fmt.Errorf("unable to read row %d: %w", rowNum, err)
+		}
+		row := &importRow{num: rowNum}
+		row.payload, row.formValues, row.err = imp.convert(values)
+		if _, isColumnErr := row.err.(*columnError); isColumnErr {
+			return res, row.err
+		}
+		batch = append(batch, row)
+		if len(batch) == opts.BatchSize {
+			imp.add(batch, res)
+			batch = nil
+		}
+	}
+	imp.add(batch, res)
+	return res, nil
+}
+
+// importRow converted row
+type importRow struct {
+	num        int
+	payload    map[string]interface{}
+	formValues map[string]string // values which can only be set with UpdateValidate, e.g. multi-value taxonomy
+	err        error
+}
+
+// columnError column can't be mapped to a list field
+type columnError struct {
+	column string
+}
+
+func (e *columnError) Error() string {
+	return fmt.Sprintf("unable to map column %s to a list field", e.column)
+}
+
+// importer import state, users and lookups caches
+type importer struct {
+	list    *api.List
+	fields  []*field
+	mapping map[string]string
+	userMap map[string]string
+	columns map[string]*field
+	users   map[string]*api.UserInfo // ensured users by logon name
+	lookups map[string]int           // lookup item ID by list ID and value
+}
+
+func newImporter(list *api.List, fields []*field, mapping map[string]string, userMap map[string]string) *importer {
+	imp := &importer{
+		list:    list,
+		fields:  fields,
+		mapping: mapping,
+		userMap: map[string]string{},
+		columns: map[string]*field{},
+		users:   map[string]*api.UserInfo{},
+		lookups: map[string]int{},
+	}
+	for from, to := range userMap {
+		imp.userMap[strings.ToLower(from)] = to
+	}
+	return imp
+}
+
+// add adds the batch rows with a single $batch request, values which can only be set with UpdateValidate
+// are updated afterwards, an item failed to update is deleted so the row can be imported again
+func (imp *importer) add(batch []*importRow, res *ImportResult) {
+	var rows []*importRow
+	var bodies [][]byte
+	for _, row := range batch {
+		if row.err != nil {
+			continue
+		}
+		body, _ := json.Marshal(row.payload)
+		rows = append(rows, row)
+		bodies = append(bodies, body)
+	}
+	if len(rows) > 0 {
+		results, err := imp.list.Items().AddBatch(bodies)
+		for i, row := range rows {
+			if err != nil {
+				row.err = err
+				continue
+			}
+			if row.err = results[i].Err; row.err != nil || len(row.formValues) == 0 {
+				continue
+			}
+			item := imp.list.Items().GetByID(results[i].Data.Data().ID)
+			if _, row.err = item.UpdateValidate(row.formValues, nil); row.err != nil {
+				_ = item.Delete()
+			}
+		}
+	}
+	for _, row := range batch {
+		if row.err != nil {
+			res.Errors = append(res.Errors, &RowError{Row: row.num, Err: row.err})
+			continue
+		}
+		res.Added++
+	}
+}
+
+// column resolves source column to a writable field
+func (imp *importer) column(name string) (*field, error) {
+	if f, ok := imp.columns[name]; ok {
+		return f, nil
+	}
+	fieldName := name
+	if mapped, ok := imp.mapping[name]; ok {
+		fieldName = mapped
+	}
+	f := findField(imp.fields, fieldName)
+	if f == nil || f.ReadOnlyField || skipTypes[f.TypeAsString] {
+		return nil, &columnError{column: name}
+	}
+	imp.columns[name] = f
+	return f, nil
+}
+
+// convert converts row values to item payload
+func (imp *importer) convert(values map[string]interface{}) (map[string]interface{}, map[string]string, error) {
+	payload := map[string]interface{}{}
+	formValues := map[string]string{}
+	for column, raw := range values {
+		f, err := imp.column(column)
+		if err != nil {
+			return nil, nil, err
+		}
+		if f.TypeAsString == "TaxonomyFieldTypeMulti" {
+			var terms []*api.FieldTaxonomyValue
+			for _, v := range importValues(raw) {
+				term, err := parseTerm(v)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%s: %w", column, err)
+				}
+				terms = append(terms, term)
+			}
+			formValues[f.InternalName] = api.FormTaxonomyValue(terms...)
+			continue
+		}
+		key, value, err := imp.value(f, raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", column, err)
+		}
+		payload[key] = value
+	}
+	return payload, formValues, nil
+}
+
+// value converts a column value to item payload property
+func (imp *importer) value(f *field, raw interface{}) (string, interface{}, error) {
+	key := f.prop()
+	if f.kind() == "Lookup" || f.kind() == "User" {
+		key += "Id"
+	}
+
+	if f.multi() {
+		collectionType := "Collection(Edm.String)"
+		results := []interface{}{}
+		for _, v := range importValues(raw) {
+			switch f.kind() {
+			case "Lookup", "User":
+				collectionType = "Collection(Edm.Int32)"
+				id, err := imp.resolve(f, v)
+				if err != nil {
+					return "", nil, err
+				}
+				results = append(results, id)
+			default:
+				results = append(results, v)
+			}
+		}
+		return key, map[string]interface{}{
+			"__metadata": map[string]string{"type": collectionType},
+			"results":    results,
+		}, nil
+	}
+
+	if raw == nil || raw == "" {
+		return key, nil, nil
+	}
+	s := toString(raw)
+	switch f.kind() {
+	case "Lookup", "User":
+		id, err := imp.resolve(f, s)
+		return key, id, err
+	case "Number", "Currency", "Integer", "Counter":
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("wrong number %s", s)
+		}
+		return key, n, nil
+	case "Boolean":
+		switch strings.ToLower(s) {
+		case "true", "1", "yes":
+			return key, true, nil
+		case "false", "0", "no":
+			return key, false, nil
+		}
+		return "", nil, fmt.Errorf("wrong boolean %s", s)
+	case "DateTime":
+		d, err := parseDate(s)
+		if err != nil {
+			return "", nil, err
+		}
+		return key, d.UTC().Format(time.RFC3339), nil
+	case "URL":
+		url, description := parseURLValue(s)
+		return key, map[string]interface{}{
+			"__metadata":  map[string]string{"type": "SP.FieldUrlValue"},
+			"Url":         url,
+			"Description": description,
+		}, nil
+	case "TaxonomyFieldType":
+		term, err := parseTerm(s)
+		if err != nil {
+			return "", nil, err
+		}
+		return key, map[string]interface{}{
+			"__metadata": map[string]string{"type": "SP.Taxonomy.TaxonomyFieldValue"},
+			"Label":      term.Label,
+			"TermGuid":   term.TermGUID,
+			"WssId":      -1,
+		}, nil
+	}
+	return key, s, nil
+}
+
+// resolve resolves user or lookup ID by the exported value
+func (imp *importer) resolve(f *field, value string) (int, error) {
+	if f.kind() == "User" {
+		user, err := imp.ensureUser(value)
+		if err != nil {
+			return 0, err
+		}
+		return user.ID, nil
+	}
+
+	cacheKey := strings.ToLower(f.LookupList + "|" + f.lookupField() + "|" + value)
+	if id, ok := imp.lookups[cacheKey]; ok {
+		return id, nil
+	}
+	lookupList := imp.list.ParentWeb().Lists().GetByID(strings.Trim(f.LookupList, "{}"))
+	resp, err := lookupList.Items().
+		Select("Id").
+		FilterExpr(odata.Field(f.lookupField()).Eq(value)).
+		Top(1).
+		Get()
+	if err != nil {
+		return 0, fmt.Errorf("unable to resolve lookup %s: %w", value, err)
+	}
+	items := resp.Data()
+	if len(items) == 0 {
+		return 0, fmt.Errorf("lookup value %s is not found", value)
+	}
+	id := items[0].Data().ID
+	imp.lookups[cacheKey] = id
+	return id, nil
+}
+
+// ensureUser ensures the exported user in the target web, UserMap is applied to the logon name
+func (imp *importer) ensureUser(value string) (*api.UserInfo, error) {
+	if mapped, ok := imp.userMap[strings.ToLower(value)]; ok {
+		value = mapped
+	}
+	if user, ok := imp.users[strings.ToLower(value)]; ok {
+		return user, nil
+	}
+	user, err := imp.list.ParentWeb().EnsureUser(value)
+	if err != nil {
+		return nil, fmt.Errorf("unable to ensure user %s: %w", value, err)
+	}
+	imp.users[strings.ToLower(value)] = user
+	return user, nil
+}
+
+// csvRows gets CSV rows reader, the first row is the columns header
+func csvRows(r io.Reader) (func() (map[string]interface{}, error), error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return func() (map[string]interface{}, error) { return nil, io.EOF }, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read header: %w", err)
+	}
+	return func() (map[string]interface{}, error) {
+		record, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		values := map[string]interface{}{}
+		for i, column := range header {
+			if i < len(record) {
+				values[column] = record[i]
+			}
+		}
+		return values, nil
+	}, nil
+}
+
+// jsonlRows gets JSON Lines rows reader, empty lines are skipped
+func jsonlRows(r io.Reader) func() (map[string]interface{}, error) {
+	reader := bufio.NewReader(r)
+	return func() (map[string]interface{}, error) {
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(strings.TrimSpace(string(line))) > 0 {
+				values := map[string]interface{}{}
+				if err := json.Unmarshal(line, &values); err != nil {
+					return nil, err
+				}
+				return values, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// importValues gets multi values from CSV `;#` joined string or JSON array
+func importValues(raw interface{}) []string {
+	var values []string
+	switch v := raw.(type) {
+	case nil:
+	case []string:
+		values = v
+	case []interface{}:
+		for _, val := range v {
+			values = append(values, toString(val))
+		}
+	default:
+		for _, val := range strings.Split(toString(v), multiSeparator) {
+			if val != "" {
+				values = append(values, val)
+			}
+		}
+	}
+	return values
+}
+
+// parseTerm parses `Label|TermGuid` or `TermGuid` taxonomy value
+func parseTerm(value string) (*api.FieldTaxonomyValue, error) {
+	if termGUIDRgx.MatchString(value) {
+		return &api.FieldTaxonomyValue{TermGUID: value}, nil
+	}
+	parts := strings.SplitN(value, "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("wrong taxonomy value %s, Label|TermGuid is expected", value)
+	}
+	return &api.FieldTaxonomyValue{Label: parts[0], TermGUID: parts[1]}, nil
+}
+
+// parseURLValue parses `URL, Description` hyperlink value with doubled commas in the URL
+func parseURLValue(value string) (string, string) {
+	for i := 0; i < len(value); i++ {
+		if value[i] != ',' {
+			continue
+		}
+		if i+1 < len(value) && value[i+1] == ',' {
+			i++
+			continue
+		}
+		return strings.Replace(value[:i], ",,", ",", -1), strings.TrimPrefix(value[i+1:], " ")
+	}
+	return strings.Replace(value, ",,", ",", -1), ""
+}
+
+// dateFormats import dates formats
+var dateFormats = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateFormats {
+		if d, err := time.Parse(layout, value); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("wrong date %s", value)
+}
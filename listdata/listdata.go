@@ -0,0 +1,153 @@
+// Package listdata exports and imports SharePoint list items in CSV and JSON Lines formats.
+//
+// Values are rendered in a re-importable form:
+//   - lookups by the lookup field value (Title by default), resolved back by the value on import
+//   - users and groups by email or login name, resolved back with Web.EnsureUser on import
+//   - managed metadata as `Label|TermGuid`, labels are resolved with TaxCatchAll lookup,
+//     terms without resolved label are rendered as `TermGuid`
+//   - hyperlinks as `URL, Description`, commas in the URL are doubled
+//   - dates in RFC3339 format
+//
+// Multi values are joined with `;#` in CSV and are arrays in JSON Lines. Columns are fields internal names.
+//
+//	f, _ := os.Create("tasks.csv")
+//	defer f.Close()
+//	n, err := listdata.Export(sp.Web().GetList("Lists/Tasks"), f, nil)
+//
+// Migrate copies items between lists of the same or different sites preserving authorship,
+// attachments and lookups, the progress is saved to a checkpoint file to resume interrupted runs.
+package listdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/koltyakov/gosip/api"
+)
+
+// Format data format
+type Format string
+
+// Data formats
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+)
+
+// multiSeparator CSV multi values separator
+const multiSeparator = ";#"
+
+// skipTypes field types which are neither exported nor imported by default
+var skipTypes = map[string]bool{
+	"Computed": true, "Calculated": true, "Attachments": true, "File": true,
+	"ContentTypeId": true, "Guid": true, "ModStat": true, "WorkflowStatus": true,
+}
+
+// field list field metadata used in values conversion
+type field struct {
+	InternalName        string `json:"InternalName"`
+	EntityPropertyName  string `json:"EntityPropertyName"`
+	Title               string `json:"Title"`
+	TypeAsString        string `json:"TypeAsString"`
+	Hidden              bool   `json:"Hidden"`
+	ReadOnlyField       bool   `json:"ReadOnlyField"`
+	LookupList          string `json:"LookupList"`
+	LookupField         string `json:"LookupField"`
+	AllowMultipleValues bool   `json:"AllowMultipleValues"`
+}
+
+// prop gets item's entity property name
+func (f *field) prop() string {
+	if f.EntityPropertyName != "" {
+		return f.EntityPropertyName
+	}
+	return f.InternalName
+}
+
+// kind gets field's base type, e.g. Lookup for LookupMulti
+func (f *field) kind() string {
+	switch f.TypeAsString {
+	case "LookupMulti":
+		return "Lookup"
+	case "UserMulti":
+		return "User"
+	case "TaxonomyFieldTypeMulti":
+		return "TaxonomyFieldType"
+	}
+	return f.TypeAsString
+}
+
+// multi checks if the field holds multiple values
+func (f *field) multi() bool {
+	switch f.TypeAsString {
+	case "LookupMulti", "UserMulti", "TaxonomyFieldTypeMulti", "MultiChoice":
+		return true
+	}
+	return f.AllowMultipleValues
+}
+
+// lookupField gets lookup field's projected field
+func (f *field) lookupField() string {
+	if f.LookupField == "" {
+		return "Title"
+	}
+	return f.LookupField
+}
+
+// getFields gets list fields metadata
+func getFields(list *api.List) ([]*field, error) {
+	resp, err := list.Fields().Get()
+	if err != nil {
+		return nil, err
+	}
+	var fields []*field
+	for _, f := range resp.Data() {
+		fld := &field{}
+		if err := json.Unmarshal(f.Normalized(), fld); err != nil {
+			return nil, fmt.Errorf("unable to parse field: %w", err)
+		}
+		fields = append(fields, fld)
+	}
+	return fields, nil
+}
+
+// findField finds a field by internal name, entity property name or title
+func findField(fields []*field, name string) *field {
+	for _, f := range fields {
+		if f.InternalName == name {
+			return f
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.InternalName, name) || strings.EqualFold(f.prop(), name) || strings.EqualFold(f.Title, name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// multiValues gets multi value field values, verbose mode wraps them with `results`
+func multiValues(raw interface{}) []interface{} {
+	if m, ok := raw.(map[string]interface{}); ok {
+		raw = m["results"]
+	}
+	values, _ := raw.([]interface{})
+	return values
+}
+
+// toString converts a scalar value to its string representation
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
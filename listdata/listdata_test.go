@@ -0,0 +1,114 @@
+package listdata
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValues(t *testing.T) {
+	t.Run("Export", func(t *testing.T) {
+		cases := []struct {
+			field    *field
+			raw      interface{}
+			expected string
+		}{
+			{&field{TypeAsString: "Lookup", LookupField: "Code"}, map[string]interface{}{"Id": 1.0, "Code": "C1"}, "C1"},
+			{&field{TypeAsString: "LookupMulti"}, map[string]interface{}{"results": []interface{}{map[string]interface{}{"Title": "A"}, map[string]interface{}{"Title": "B"}}}, "[A B]"},
+			{&field{TypeAsString: "User"}, map[string]interface{}{"Id": 5.0, "EMail": "", "Name": "i:0#.w|contoso\\john"}, `i:0#.w|contoso\john`},
+			{&field{TypeAsString: "TaxonomyFieldTypeMulti"}, []interface{}{map[string]interface{}{"Label": "Term", "TermGuid": "guid"}}, "[Term|guid]"},
+			{&field{TypeAsString: "URL"}, map[string]interface{}{"Url": "https://contoso.com/a,b", "Description": "Contoso"}, "https://contoso.com/a,,b, Contoso"},
+			{&field{TypeAsString: "Number"}, 10.5, "10.5"},
+			{&field{TypeAsString: "Text"}, nil, "<nil>"},
+		}
+		for _, c := range cases {
+			if actual := fmt.Sprintf("%v", exportValue(c.field, c.raw)); actual != c.expected {
+				t.Errorf("%s: expected %s, got %s", c.field.TypeAsString, c.expected, actual)
+			}
+		}
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		if url, desc := parseURLValue("https://contoso.com/a,,b, Contoso, Inc"); url != "https://contoso.com/a,b" || desc != "Contoso, Inc" {
+			t.Errorf("unexpected URL value: %s, %s", url, desc)
+		}
+		if url, desc := parseURLValue("https://contoso.com"); url != "https://contoso.com" || desc != "" {
+			t.Errorf("unexpected URL value: %s, %s", url, desc)
+		}
+		if values := importValues("A;#B;#"); fmt.Sprintf("%v", values) != "[A B]" {
+			t.Errorf("unexpected values: %v", values)
+		}
+		if values := importValues([]interface{}{"A", 1.0}); fmt.Sprintf("%v", values) != "[A 1]" {
+			t.Errorf("unexpected values: %v", values)
+		}
+		if _, err := parseTerm("Label only"); err == nil {
+			t.Error("wrong term should fail")
+		}
+		if term, err := parseTerm("0F8E4A6B-3C2D-4E5F-8A9B-1C2D3E4F5A6B"); err != nil || term.Label != "" || term.TermGUID == "" {
+			t.Errorf("term GUID without label is expected, got %+v, %v", term, err)
+		}
+
+		imp := &importer{}
+		for _, c := range []struct {
+			field    *field
+			raw      interface{}
+			expected string
+		}{
+			{&field{InternalName: "Active", TypeAsString: "Boolean"}, "yes", "Active:true"},
+			{&field{InternalName: "Due", TypeAsString: "DateTime"}, "2020-03-05", "Due:2020-03-05T00:00:00Z"},
+			{&field{InternalName: "Tags", TypeAsString: "MultiChoice"}, "A;#B", "Tags:map[__metadata:map[type:Collection(Edm.String)] results:[A B]]"},
+			{&field{InternalName: "Owner", TypeAsString: "User"}, "", "OwnerId:<nil>"},
+			{&field{InternalName: "Amount", TypeAsString: "Number"}, 2.0, "Amount:2"},
+		} {
+			key, value, err := imp.value(c.field, c.raw)
+			if err != nil {
+				t.Error(err)
+			}
+			if actual := fmt.Sprintf("%s:%v", key, value); actual != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, actual)
+			}
+		}
+		if _, _, err := imp.value(&field{InternalName: "Amount", TypeAsString: "Number"}, "ten"); err == nil {
+			t.Error("wrong number should fail")
+		}
+	})
+}
+
+func TestTermLabels(t *testing.T) {
+	single := &field{InternalName: "Category", TypeAsString: "TaxonomyFieldType"}
+	multi := &field{InternalName: "Tags", TypeAsString: "TaxonomyFieldTypeMulti"}
+	text := &field{InternalName: "Title", TypeAsString: "Text"}
+
+	if fields := withCatchAll([]*field{single, text}, []*field{single}); len(fields) != 1 {
+		t.Error("catch all should be queried only when the list has it")
+	}
+	listFields := []*field{single, multi, text, {InternalName: "TaxCatchAll", TypeAsString: "LookupMulti"}}
+	if fields := withCatchAll(listFields, []*field{text}); len(fields) != 1 {
+		t.Error("catch all should be queried only with managed metadata fields")
+	}
+	if fields := withCatchAll(listFields, []*field{text, multi}); len(fields) != 3 || fields[2] != catchAllField {
+		t.Errorf("catch all is expected in query fields: %v", fields)
+	}
+
+	// REST responses hold WssId in the Label
+	item := map[string]interface{}{
+		"Category": map[string]interface{}{"Label": "3", "TermGuid": "a-1", "WssId": 3.0},
+		"Tags": map[string]interface{}{"results": []interface{}{
+			map[string]interface{}{"Label": "4", "TermGuid": "b-2", "WssId": 4.0},
+			map[string]interface{}{"Label": "9", "TermGuid": "c-3", "WssId": 9.0},
+		}},
+		"TaxCatchAll": map[string]interface{}{"results": []interface{}{
+			map[string]interface{}{"Id": 3.0, "Term": "Finance"},
+			map[string]interface{}{"Id": 4.0, "Term": "Urgent"},
+		}},
+	}
+	labels := termLabels(item)
+	for _, f := range []*field{single, multi} {
+		resolveTermLabels(f, item[f.prop()], labels)
+	}
+	if actual := fmt.Sprintf("%v", exportValue(single, item["Category"])); actual != "Finance|a-1" {
+		t.Errorf("unexpected term: %s", actual)
+	}
+	if actual := fmt.Sprintf("%v", exportValue(multi, item["Tags"])); actual != "[Urgent|b-2 c-3]" {
+		t.Errorf("unexpected terms: %s", actual)
+	}
+}
@@ -0,0 +1,469 @@
+package listdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/koltyakov/gosip/api"
+	"github.com/koltyakov/gosip/odata"
+)
+
+// FieldMapping migration source to target field mapping
+type FieldMapping struct {
+	Source string // source field internal name
+	Target string // target field internal name, the source name by default
+
+	// Transform optionally transforms the value before it's written to the target, the value is in the export form,
+	// e.g. a lookup value or a user email, item is the source item properties
+	Transform func(value interface{}, item map[string]interface{}) (interface{}, error)
+}
+
+// MigrateOptions list items migration options
+type MigrateOptions struct {
+	Fields []*FieldMapping // fields to migrate, source visible editable fields existing in the target by default
+	Filter string          // source items OData $filter
+
+	// UserMap maps source users (emails or logins) to target logon names, e.g. on-premise logins to SharePoint Online emails
+	UserMap map[string]string
+
+	// LookupMaps target lookup fields source to target item IDs maps, e.g. MigrateResult.IDs of the lookup list migration,
+	// lookups without a map are resolved by the lookup field value
+	LookupMaps map[string]map[int]int
+
+	Attachments      bool           // copy attachments
+	SkipSystemFields bool           // don't preserve Author, Editor, Created and Modified
+	DateLayout       string         // target web's regional settings date layout, api.FormDateLayout by default
+	Location         *time.Location // target web's time zone, required unless SkipSystemFields
+
+	// Checkpoint file path, the migration is resumed from the checkpoint when the file exists
+	Checkpoint string
+
+	PageSize int                                     // source items page size, 500 by default
+	OnItem   func(sourceID, targetID int, err error) // optional per item progress callback
+}
+
+// MigrateResult list items migration outcome
+type MigrateResult struct {
+	Migrated int         // items migrated in this run
+	IDs      map[int]int // source to target item IDs, including the ones migrated in the previous runs
+	Errors   []*ItemError
+}
+
+// ItemError migration item error
+type ItemError struct {
+	ID  int // source item ID
+	Err error
+}
+
+// Error returns item error message
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("item %d: %s", e.ID, e.Err)
+}
+
+// Unwrap returns item's underlying error
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// checkpoint migration progress state
+type checkpoint struct {
+	LastID int            `json:"lastId"` // last processed source item ID
+	IDs    map[string]int `json:"ids"`    // source to target item IDs
+	Failed []int          `json:"failed"` // source items IDs failed to migrate, retried on resume
+}
+
+// retryChunkSize failed items IDs retried with a single query
+const retryChunkSize = 50
+
+// systemFields fields preserved with UpdateValidate
+var systemFields = []*field{
+	{InternalName: "Author", TypeAsString: "User"},
+	{InternalName: "Editor", TypeAsString: "User"},
+	{InternalName: "Created", TypeAsString: "DateTime"},
+	{InternalName: "Modified", TypeAsString: "DateTime"},
+}
+
+// Migrate copies items from the source list to the target list, the lists can belong to different sites,
+// farms and clients. Items are processed in ID order, the progress is saved to the checkpoint file after each item.
+// Items failed to migrate are reported in MigrateResult.Errors and are retried on resume,
+// an error is returned when the lists metadata can't be read, the checkpoint can't be saved or Location is missing
+// while Author, Editor, Created and Modified are preserved.
+func Migrate(source *api.List, target *api.List, options *MigrateOptions) (*MigrateResult, error) {
+	opts := &MigrateOptions{}
+	if options != nil {
+		*opts = *options
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = 500
+	}
+	if opts.DateLayout == "" {
+		opts.DateLayout = api.FormDateLayout
+	}
+	// Form dates are read in the target web's time zone, a wrong zone shifts preserved dates
+	if opts.Location == nil && !opts.SkipSystemFields {
+		return nil, fmt.Errorf("target web's time zone Location is required to preserve system fields")
+	}
+
+	sourceFields, err := getFields(source)
+	if err != nil {
+		return nil, err
+	}
+	targetFields, err := getFields(target)
+	if err != nil {
+		return nil, err
+	}
+	m := &migration{
+		source:       source,
+		target:       target,
+		sourceFields: sourceFields,
+		options:      opts,
+		imp:          newImporter(target, targetFields, nil, opts.UserMap),
+		state:        &checkpoint{IDs: map[string]int{}},
+	}
+	if err := m.mapFields(sourceFields, targetFields); err != nil {
+		return nil, err
+	}
+	if err := m.loadCheckpoint(); err != nil {
+		return nil, err
+	}
+
+	res := &MigrateResult{IDs: map[int]int{}}
+	retry := append([]int{}, m.state.Failed...)
+	processed := map[int]bool{}
+	process := func(itemData []byte) error {
+		itemResp := api.ItemResp(itemData)
+		item := itemResp.ToMap()
+		sourceID := itemResp.Data().ID
+		processed[sourceID] = true
+		m.state.Failed = removeID(m.state.Failed, sourceID)
+		targetID, err := m.migrate(sourceID, item)
+		if err != nil {
+			res.Errors = append(res.Errors, &ItemError{ID: sourceID, Err: err})
+			m.state.Failed = append(m.state.Failed, sourceID)
+		} else {
+			res.Migrated++
+			m.state.IDs[strconv.Itoa(sourceID)] = targetID
+		}
+		if sourceID > m.state.LastID {
+			m.state.LastID = sourceID
+		}
+		if opts.OnItem != nil {
+			opts.OnItem(sourceID, targetID, err)
+		}
+		return m.saveCheckpoint()
+	}
+
+	// Previously failed items are retried in chunks keeping the $filter within URL length limits,
+	// the ones which are not returned anymore, e.g. deleted or filtered out, are not retried again
+	for start := 0; start < len(retry) && err == nil; start += retryChunkSize {
+		end := start + retryChunkSize
+		if end > len(retry) {
+			end = len(retry)
+		}
+		if err = m.query(retry[start:end]).Iterator().ForEach(process); err == nil {
+			for _, id := range retry[start:end] {
+				if !processed[id] {
+					m.state.Failed = removeID(m.state.Failed, id)
+				}
+			}
+			err = m.saveCheckpoint()
+		}
+	}
+	if err == nil {
+		err = m.query(nil).Iterator().ForEach(process)
+	}
+	for sourceID, targetID := range m.state.IDs {
+		id, _ := strconv.Atoi(sourceID)
+		res.IDs[id] = targetID
+	}
+	return res, err
+}
+
+// migration migration state
+type migration struct {
+	source       *api.List
+	target       *api.List
+	sourceFields []*field
+	options      *MigrateOptions
+	imp          *importer
+	mappings     []*fieldMapping
+	state        *checkpoint
+}
+
+// fieldMapping resolved field mapping
+type fieldMapping struct {
+	source    *field
+	target    *field
+	transform func(value interface{}, item map[string]interface{}) (interface{}, error)
+}
+
+// mapFields resolves fields mappings
+func (m *migration) mapFields(sourceFields []*field, targetFields []*field) error {
+	writable := func(f *field) bool { return f != nil && !f.ReadOnlyField && !skipTypes[f.TypeAsString] }
+	if len(m.options.Fields) == 0 {
+		defaults, _ := exportFields(sourceFields, nil)
+		for _, f := range defaults {
+			if t := findField(targetFields, f.InternalName); writable(t) {
+				m.mappings = append(m.mappings, &fieldMapping{source: f, target: t})
+			}
+		}
+		return nil
+	}
+	for _, mapping := range m.options.Fields {
+		s := findField(sourceFields, mapping.Source)
+		if s == nil {
+			return fmt.Errorf("source field %s is not found", mapping.Source)
+		}
+		targetName := mapping.Target
+		if targetName == "" {
+			targetName = mapping.Source
+		}
+		t := findField(targetFields, targetName)
+		if !writable(t) {
+			return fmt.Errorf("target field %s is not found or is read only", targetName)
+		}
+		m.mappings = append(m.mappings, &fieldMapping{source: s, target: t, transform: mapping.Transform})
+	}
+	return nil
+}
+
+// query gets source items query, previously failed items are requested when retry IDs are provided,
+// otherwise items after the checkpoint are requested
+func (m *migration) query(retry []int) *api.Items {
+	fields := []*field{{InternalName: "Id", TypeAsString: "Counter"}}
+	for _, mapping := range m.mappings {
+		fields = append(fields, mapping.source)
+	}
+	if !m.options.SkipSystemFields {
+		fields = append(fields, systemFields...)
+	}
+	if m.options.Attachments {
+		fields = append(fields, &field{InternalName: "Attachments", TypeAsString: "Attachments"})
+	}
+
+	filter := odata.Field("Id").Gt(m.state.LastID)
+	if len(retry) > 0 {
+		ids := make([]interface{}, len(retry))
+		for i, id := range retry {
+			ids[i] = id
+		}
+		filter = odata.Field("Id").In(ids...)
+	}
+	if m.options.Filter != "" {
+		filter = odata.And(odata.Raw(m.options.Filter), filter)
+	}
+	return itemsQuery(m.source, withCatchAll(m.sourceFields, fields)).
+		FilterExpr(filter).
+		OrderBy("Id", true).
+		Top(m.options.PageSize)
+}
+
+// migrate migrates a single item, a partially migrated item is deleted from the target
+func (m *migration) migrate(sourceID int, item map[string]interface{}) (int, error) {
+	payload, formValues, err := m.convert(item)
+	if err != nil {
+		return 0, err
+	}
+	if !m.options.SkipSystemFields {
+		if err := m.systemValues(item, formValues); err != nil {
+			return 0, err
+		}
+	}
+
+	body, _ := json.Marshal(payload)
+	itemResp, err := m.target.Items().Add(body)
+	if err != nil {
+		return 0, err
+	}
+	targetID := itemResp.Data().ID
+	targetItem := m.target.Items().GetByID(targetID)
+
+	if err := m.copyAttachments(sourceID, targetItem, item); err != nil {
+		_ = targetItem.Delete()
+		return 0, err
+	}
+	// System fields are updated the last as other updates change Editor and Modified
+	if len(formValues) > 0 {
+		if _, err := targetItem.UpdateValidate(formValues, &api.ValidateUpdateOptions{NewDocumentUpdate: true}); err != nil {
+			_ = targetItem.Delete()
+			return 0, err
+		}
+	}
+	return targetID, nil
+}
+
+// convert converts source item properties to target payload
+func (m *migration) convert(item map[string]interface{}) (map[string]interface{}, map[string]string, error) {
+	values := map[string]interface{}{}
+	lookupIDs := map[string]interface{}{}
+	labels := termLabels(item)
+	for _, mapping := range m.mappings {
+		raw := item[mapping.source.prop()]
+		resolveTermLabels(mapping.source, raw, labels)
+		if ids, ok := m.options.LookupMaps[mapping.target.InternalName]; ok && mapping.target.kind() == "Lookup" {
+			value, err := remapLookup(mapping.target, raw, ids)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", mapping.target.InternalName, err)
+			}
+			lookupIDs[mapping.target.prop()+"Id"] = value
+			continue
+		}
+		value := exportValue(mapping.source, raw)
+		if mapping.transform != nil {
+			var err error
+			if value, err = mapping.transform(value, item); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", mapping.source.InternalName, err)
+			}
+		}
+		values[mapping.target.InternalName] = value
+	}
+	payload, formValues, err := m.imp.convert(values)
+	if err != nil {
+		return nil, nil, err
+	}
+	for key, value := range lookupIDs {
+		payload[key] = value
+	}
+	return payload, formValues, nil
+}
+
+// systemValues adds Author, Editor, Created and Modified form values
+func (m *migration) systemValues(item map[string]interface{}, formValues map[string]string) error {
+	for _, f := range systemFields {
+		raw := item[f.InternalName]
+		if raw == nil {
+			continue
+		}
+		if f.kind() == "User" {
+			user, err := m.imp.ensureUser(exportScalar(f, raw))
+			if err != nil {
+				return err
+			}
+			formValues[f.InternalName] = api.FormUserValue(user.LoginName)
+			continue
+		}
+		d, err := parseDate(toString(raw))
+		if err != nil {
+			return err
+		}
+		formValues[f.InternalName] = api.FormDateValue(d.In(m.options.Location), m.options.DateLayout)
+	}
+	return nil
+}
+
+// copyAttachments copies source item attachments to the target item
+func (m *migration) copyAttachments(sourceID int, targetItem *api.Item, item map[string]interface{}) error {
+	if !m.options.Attachments || item["Attachments"] != true {
+		return nil
+	}
+	attachments := m.source.Items().GetByID(sourceID).Attachments()
+	resp, err := attachments.Get()
+	if err != nil {
+		return err
+	}
+	for _, a := range resp.Data() {
+		name := a.Data().FileName
+		content, err := attachments.GetByName(name).Download()
+		if err != nil {
+			return fmt.Errorf("unable to download attachment %s: %w", name, err)
+		}
+		if _, err := targetItem.Attachments().Add(name, bytes.NewBuffer(content)); err != nil {
+			return fmt.Errorf("unable to add attachment %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// remapLookup maps source lookup IDs to target IDs
+func remapLookup(f *field, raw interface{}, ids map[int]int) (interface{}, error) {
+	remap := func(value interface{}) (int, error) {
+		m, _ := value.(map[string]interface{})
+		id, _ := m["Id"].(float64)
+		targetID, ok := ids[int(id)]
+		if !ok {
+			return 0, fmt.Errorf("lookup item %d is not mapped", int(id))
+		}
+		return targetID, nil
+	}
+	if !f.multi() {
+		if raw == nil {
+			return nil, nil
+		}
+		return remap(raw)
+	}
+	results := []interface{}{}
+	for _, v := range multiValues(raw) {
+		id, err := remap(v)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, id)
+	}
+	return map[string]interface{}{
+		"__metadata": map[string]string{"type": "Collection(Edm.Int32)"},
+		"results":    results,
+	}, nil
+}
+
+func (m *migration) loadCheckpoint() error {
+	if m.options.Checkpoint == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(m.options.Checkpoint)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, m.state); err != nil {
+		return fmt.Errorf("unable to parse checkpoint: %w", err)
+	}
+	if m.state.IDs == nil {
+		m.state.IDs = map[string]int{}
+	}
+	return nil
+}
+
+// saveCheckpoint writes the checkpoint atomically
+func (m *migration) saveCheckpoint() error {
+	if m.options.Checkpoint == "" {
+		return nil
+	}
+	sort.Ints(m.state.Failed)
+	data, _ := json.MarshalIndent(m.state, "", "  ")
+	tmp, err := ioutil.TempFile(filepath.Dir(m.options.Checkpoint), filepath.Base(m.options.Checkpoint)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to save checkpoint: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("unable to save checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("unable to save checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), m.options.Checkpoint); err != nil {
+		return fmt.Errorf("unable to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// removeID removes the ID from the IDs
+func removeID(ids []int, id int) []int {
+	for i, v := range ids {
+		if v == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
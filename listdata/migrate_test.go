@@ -0,0 +1,238 @@
+package listdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/koltyakov/gosip/api"
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+func TestMigrate(t *testing.T) {
+	source := spmock.NewServer()
+	defer source.Close()
+	target := spmock.NewServer()
+	defer target.Close()
+
+	for _, srv := range []*spmock.Server{source, target} {
+		if _, err := srv.AddList("Departments", 100); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.AddList("Tasks", 100); err != nil {
+			t.Fatal(err)
+		}
+		if err := srv.AddLookupField("Tasks", "Department", "Departments"); err != nil {
+			t.Fatal(err)
+		}
+		if err := srv.AddField("Tasks", "Owner", "User"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := source.AddField("Tasks", "Amount", "Number"); err != nil {
+		t.Fatal(err)
+	}
+	if err := target.AddField("Tasks", "Total", "Number"); err != nil {
+		t.Fatal(err)
+	}
+	// Shifts target lookup IDs
+	if _, err := target.AddItem("Departments", map[string]interface{}{"Title": "Legacy"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"Sales", "R&D"} {
+		if _, err := source.AddItem("Departments", map[string]interface{}{"Title": title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sourceSP := api.NewSP(source.Client())
+	targetSP := api.NewSP(target.Client())
+	jane, err := sourceSP.Web().EnsureUser("jane@mock.local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 3; i++ {
+		if _, err := source.AddItem("Tasks", map[string]interface{}{
+			"Title":        fmt.Sprintf("Task %d", i),
+			"Amount":       float64(i),
+			"DepartmentId": i%2 + 1,
+			"OwnerId":      jane.ID,
+			"AuthorId":     jane.ID,
+			"EditorId":     jane.ID,
+			"Created":      "2019-01-02T08:30:00Z",
+			"Modified":     "2019-02-03T17:45:00Z",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	attachments := sourceSP.Web().Lists().GetByTitle("Tasks").Items().GetByID(1).Attachments()
+	if _, err := attachments.Add("notes.txt", strings.NewReader("attached")); err != nil {
+		t.Fatal(err)
+	}
+
+	deps, err := Migrate(
+		sourceSP.Web().Lists().GetByTitle("Departments"),
+		targetSP.Web().Lists().GetByTitle("Departments"),
+		&MigrateOptions{Fields: []*FieldMapping{{Source: "Title"}}},
+	)
+	if err == nil {
+		t.Fatal("missing target time zone should fail when system fields are preserved")
+	}
+	deps, err = Migrate(
+		sourceSP.Web().Lists().GetByTitle("Departments"),
+		targetSP.Web().Lists().GetByTitle("Departments"),
+		&MigrateOptions{Fields: []*FieldMapping{{Source: "Title"}}, Location: time.UTC},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps.Migrated != 2 || deps.IDs[1] != 2 || deps.IDs[2] != 3 {
+		t.Fatalf("unexpected departments migration result: %+v", deps)
+	}
+
+	dir, err := ioutil.TempDir("", "gosip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	failOn := "Task 2"
+	options := &MigrateOptions{
+		Fields: []*FieldMapping{
+			{Source: "Title"},
+			{Source: "Department"},
+			{Source: "Owner"},
+			{Source: "Amount", Target: "Total", Transform: func(value interface{}, item map[string]interface{}) (interface{}, error) {
+				if item["Title"] == failOn {
+					return nil, fmt.Errorf("transform failed")
+				}
+				return value.(float64) * 10, nil
+			}},
+		},
+		LookupMaps:  map[string]map[int]int{"Department": deps.IDs},
+		Location:    time.UTC,
+		Attachments: true,
+		Checkpoint:  filepath.Join(dir, "checkpoint.json"),
+		Filter:      "Id le 2",
+	}
+	sourceTasks := sourceSP.Web().Lists().GetByTitle("Tasks")
+	targetTasks := targetSP.Web().Lists().GetByTitle("Tasks")
+
+	res, err := Migrate(sourceTasks, targetTasks, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Migrated != 1 || len(res.Errors) != 1 || res.Errors[0].ID != 2 {
+		t.Fatalf("unexpected first run result: %+v", res)
+	}
+
+	// Resumes after the checkpoint and retries the failed item
+	failOn = ""
+	options.Filter = ""
+	res, err = Migrate(sourceTasks, targetTasks, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Migrated != 2 || len(res.Errors) != 0 || len(res.IDs) != 3 {
+		t.Fatalf("unexpected resumed run result: %+v", res)
+	}
+
+	for sourceID, targetID := range res.IDs {
+		itemResp, err := targetTasks.Items().GetByID(targetID).
+			Select("Title,Total,Department/Title,Owner/Email,Author/Email,Editor/Email,Created,Modified,Attachments").
+			Expand("Department,Owner,Author,Editor").
+			Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		item := itemResp.ToMap()
+		if item["Title"] != fmt.Sprintf("Task %d", sourceID) || item["Total"] != float64(sourceID*10) {
+			t.Errorf("unexpected item %d values: %v", sourceID, item)
+		}
+		dep := map[int]string{1: "R&D", 0: "Sales"}[sourceID%2]
+		if d, _ := item["Department"].(map[string]interface{}); d == nil || d["Title"] != dep {
+			t.Errorf("item %d department is expected to be %s: %v", sourceID, dep, item["Department"])
+		}
+		for _, user := range []string{"Owner", "Author", "Editor"} {
+			if u, _ := item[user].(map[string]interface{}); u == nil || u["Email"] != "jane@mock.local" {
+				t.Errorf("item %d %s is not preserved: %v", sourceID, user, item[user])
+			}
+		}
+		if item["Created"] != "2019-01-02T08:30:00Z" || item["Modified"] != "2019-02-03T17:45:00Z" {
+			t.Errorf("item %d dates are not preserved: %v, %v", sourceID, item["Created"], item["Modified"])
+		}
+		if item["Attachments"] != (sourceID == 1) {
+			t.Errorf("item %d attachments flag mismatch", sourceID)
+		}
+	}
+
+	content, err := targetTasks.Items().GetByID(res.IDs[1]).Attachments().GetByName("notes.txt").Download()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "attached" {
+		t.Errorf("unexpected attachment content: %s", content)
+	}
+}
+
+func TestMigrateRetryChunks(t *testing.T) {
+	source := spmock.NewServer()
+	defer source.Close()
+	target := spmock.NewServer()
+	defer target.Close()
+
+	for _, srv := range []*spmock.Server{source, target} {
+		if _, err := srv.AddList("Notes", 100); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var failed []int
+	for i := 1; i <= 2*retryChunkSize+5; i++ {
+		if _, err := source.AddItem("Notes", map[string]interface{}{"Title": fmt.Sprintf("Note %d", i)}); err != nil {
+			t.Fatal(err)
+		}
+		failed = append(failed, i)
+	}
+	// Deleted source items stay in the checkpoint
+	failed = append(failed, 500, 501)
+
+	dir, err := ioutil.TempDir("", "gosip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	checkpointPath := filepath.Join(dir, "checkpoint.json")
+	state, _ := json.Marshal(&checkpoint{LastID: 501, Failed: failed})
+	if err := ioutil.WriteFile(checkpointPath, state, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Migrate(
+		api.NewSP(source.Client()).Web().Lists().GetByTitle("Notes"),
+		api.NewSP(target.Client()).Web().Lists().GetByTitle("Notes"),
+		&MigrateOptions{Fields: []*FieldMapping{{Source: "Title"}}, SkipSystemFields: true, Checkpoint: checkpointPath},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Migrated != 2*retryChunkSize+5 || len(res.Errors) != 0 {
+		t.Fatalf("unexpected result: migrated %d, errors %v", res.Migrated, res.Errors)
+	}
+
+	data, err := ioutil.ReadFile(checkpointPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := &checkpoint{}
+	if err := json.Unmarshal(data, saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Failed) != 0 || saved.LastID != 501 {
+		t.Errorf("unexpected checkpoint: last ID %d, failed %v", saved.LastID, saved.Failed)
+	}
+}
@@ -0,0 +1,211 @@
+// Package odata provides type-safe OData $filter expression builder.
+//
+// Literals are formatted by their Go types: strings are quoted with apostrophes escaping,
+// time.Time becomes datetime'...', GUID becomes guid'...', numbers and booleans are kept as is:
+//
+//	filter := odata.And(
+//		odata.Field("Title").Eq("O'Reilly"),
+//		odata.Field("Created").Ge(time.Now().AddDate(0, -1, 0)),
+//		odata.Or(
+//			odata.Field("Author/Title").StartsWith("John"),
+//			odata.Field("Priority").Gt(2),
+//		),
+//	)
+//	items, err := list.Items().FilterExpr(filter).Get()
+package odata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expr OData $filter expression
+type Expr struct {
+	raw string
+	op  string // top level logical operator: "", "and", "or"
+}
+
+// String renders the expression
+func (e Expr) String() string { return e.raw }
+
+// IsEmpty checks if the expression is empty, empty expressions are skipped in And/Or
+func (e Expr) IsEmpty() bool { return e.raw == "" }
+
+// And combines the expression with others using `and` operator
+func (e Expr) And(exprs ...Expr) Expr { return And(append([]Expr{e}, exprs...)...) }
+
+// Or combines the expression with others using `or` operator
+func (e Expr) Or(exprs ...Expr) Expr { return Or(append([]Expr{e}, exprs...)...) }
+
+// Raw creates expression from a raw $filter string
+func Raw(filter string) Expr {
+	filter = strings.TrimSpace(filter)
+	op := ""
+	if filter != "" {
+		op = "raw"
+	}
+	return Expr{raw: filter, op: op}
+}
+
+// And combines expressions using `and` operator
+func And(exprs ...Expr) Expr { return join("and", exprs) }
+
+// Or combines expressions using `or` operator
+func Or(exprs ...Expr) Expr { return join("or", exprs) }
+
+// Not negates the expression
+func Not(e Expr) Expr {
+	if e.IsEmpty() {
+		return e
+	}
+	return Expr{raw: "not (" + e.raw + ")"}
+}
+
+func join(op string, exprs []Expr) Expr {
+	var parts []string
+	for _, e := range exprs {
+		if e.IsEmpty() {
+			continue
+		}
+		if len(exprs) > 1 && e.op != "" && e.op != op {
+			parts = append(parts, "("+e.raw+")")
+			continue
+		}
+		parts = append(parts, e.raw)
+	}
+	switch len(parts) {
+	case 0:
+		return Expr{}
+	case 1:
+		for _, e := range exprs {
+			if !e.IsEmpty() {
+				return e
+			}
+		}
+	}
+	return Expr{raw: strings.Join(parts, " "+op+" "), op: op}
+}
+
+// FieldRef field reference, lookup projections are referenced with a slash, e.g. `Author/Title`
+type FieldRef struct {
+	name string
+}
+
+// Field creates field reference by internal name
+func Field(name string) FieldRef { return FieldRef{name: name} }
+
+// Nav references lookup's projected field, e.g. Field("Author").Nav("Title")
+func (f FieldRef) Nav(name string) FieldRef { return FieldRef{name: f.name + "/" + name} }
+
+// String gets field reference name
+func (f FieldRef) String() string { return f.name }
+
+// Eq creates `eq` comparison, nil value is compared with null
+func (f FieldRef) Eq(value interface{}) Expr { return f.compare("eq", value) }
+
+// Ne creates `ne` comparison, nil value is compared with null
+func (f FieldRef) Ne(value interface{}) Expr { return f.compare("ne", value) }
+
+// Gt creates `gt` comparison
+func (f FieldRef) Gt(value interface{}) Expr { return f.compare("gt", value) }
+
+// Ge creates `ge` comparison
+func (f FieldRef) Ge(value interface{}) Expr { return f.compare("ge", value) }
+
+// Lt creates `lt` comparison
+func (f FieldRef) Lt(value interface{}) Expr { return f.compare("lt", value) }
+
+// Le creates `le` comparison
+func (f FieldRef) Le(value interface{}) Expr { return f.compare("le", value) }
+
+// IsNull checks the field is empty
+func (f FieldRef) IsNull() Expr { return f.compare("eq", nil) }
+
+// NotNull checks the field is not empty
+func (f FieldRef) NotNull() Expr { return f.compare("ne", nil) }
+
+// StartsWith creates `startswith(Field,'value')` function call
+func (f FieldRef) StartsWith(value string) Expr {
+	return Expr{raw: fmt.Sprintf("startswith(%s,%s)", f.name, Literal(value))}
+}
+
+// SubstringOf creates `substringof('value',Field)` function call
+func (f FieldRef) SubstringOf(value string) Expr {
+	return Expr{raw: fmt.Sprintf("substringof(%s,%s)", Literal(value), f.name)}
+}
+
+// In creates `or` group of `eq` comparisons, with no values the expression matches nothing
+func (f FieldRef) In(values ...interface{}) Expr {
+	if len(values) == 0 {
+		return And(f.IsNull(), f.NotNull())
+	}
+	exprs := make([]Expr, len(values))
+	for i, v := range values {
+		exprs[i] = f.Eq(v)
+	}
+	return Or(exprs...)
+}
+
+func (f FieldRef) compare(op string, value interface{}) Expr {
+	return Expr{raw: fmt.Sprintf("%s %s %s", f.name, op, Literal(value))}
+}
+
+// GUID GUID literal value
+type GUID string
+
+// DateTime date literal value, time.Time values are formatted the same way
+type DateTime time.Time
+
+// Literal formats a value as OData literal
+func Literal(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "'" + strings.Replace(v, "'", "''", -1) + "'"
+	case GUID:
+		return "guid'" + strings.Trim(string(v), "{}") + "'"
+	case time.Time:
+		return "datetime'" + v.UTC().Format("2006-01-02T15:04:05Z") + "'"
+	case *time.Time:
+		if v == nil {
+			return "null"
+		}
+		return Literal(*v)
+	case DateTime:
+		return Literal(time.Time(v))
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int8:
+		return strconv.FormatInt(int64(v), 10)
+	case int16:
+		return strconv.FormatInt(int64(v), 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case FieldRef:
+		return v.name
+	case fmt.Stringer:
+		return Literal(v.String())
+	}
+	return Literal(fmt.Sprintf("%v", value))
+}
@@ -0,0 +1,83 @@
+package odata
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLiteral(t *testing.T) {
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("+3", 3*60*60))
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "null"},
+		{"O'Reilly", "'O''Reilly'"},
+		{"", "''"},
+		{42, "42"},
+		{int64(-7), "-7"},
+		{int8(5), "5"},
+		{int16(-5), "-5"},
+		{uint8(5), "5"},
+		{uint16(5), "5"},
+		{uint32(5), "5"},
+		{uint64(18446744073709551615), "18446744073709551615"},
+		{1.5, "1.5"},
+		{true, "true"},
+		{date, "datetime'2020-01-02T00:04:05Z'"},
+		{&date, "datetime'2020-01-02T00:04:05Z'"},
+		{DateTime(date), "datetime'2020-01-02T00:04:05Z'"},
+		{GUID("{6D5F5D36-0C1E-4A5C-8F63-8A0A3C4D0E1F}"), "guid'6D5F5D36-0C1E-4A5C-8F63-8A0A3C4D0E1F'"},
+		{Field("Modified"), "Modified"},
+	}
+	for _, c := range cases {
+		if actual := Literal(c.value); actual != c.expected {
+			t.Errorf("%#v: expected %s, got %s", c.value, c.expected, actual)
+		}
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	cases := []struct {
+		expr     Expr
+		expected string
+	}{
+		{Field("Title").Eq("It's"), "Title eq 'It''s'"},
+		{Field("Author").Nav("Title").Ne("John"), "Author/Title ne 'John'"},
+		{Field("Priority").Gt(1), "Priority gt 1"},
+		{Field("Priority").Ge(1), "Priority ge 1"},
+		{Field("Priority").Lt(1), "Priority lt 1"},
+		{Field("Priority").Le(1), "Priority le 1"},
+		{Field("Manager").IsNull(), "Manager eq null"},
+		{Field("Manager").NotNull(), "Manager ne null"},
+		{Field("Title").StartsWith("A'"), "startswith(Title,'A''')"},
+		{Field("Title").SubstringOf("abc"), "substringof('abc',Title)"},
+		{Field("Id").In(1, 2, 3), "Id eq 1 or Id eq 2 or Id eq 3"},
+		{Field("Id").In(1), "Id eq 1"},
+		{Field("Id").In(), "Id eq null and Id ne null"},
+		{
+			And(Field("A").Eq(1), Or(Field("B").Eq(2), Field("C").Eq(3)), Field("D").Eq(4)),
+			"A eq 1 and (B eq 2 or C eq 3) and D eq 4",
+		},
+		{
+			Or(And(Field("A").Eq(1), Field("B").Eq(2)), Field("C").Eq(3)),
+			"(A eq 1 and B eq 2) or C eq 3",
+		},
+		{
+			And(Field("A").Eq(1), And(Field("B").Eq(2), Field("C").Eq(3))),
+			"A eq 1 and B eq 2 and C eq 3",
+		},
+		{And(Expr{}, Field("A").Eq(1), Expr{}), "A eq 1"},
+		{And(Or(Field("A").Eq(1), Field("B").Eq(2))), "A eq 1 or B eq 2"},
+		{And(), ""},
+		{Not(Field("A").Eq(1)), "not (A eq 1)"},
+		{Field("A").Eq(1).And(Raw("B eq 2 or C eq 3")), "A eq 1 and (B eq 2 or C eq 3)"},
+		{Field("A").Eq(1).Or(Field("B").Eq(2)), "A eq 1 or B eq 2"},
+		{Field("Modified").Gt(Field("Created")), "Modified gt Created"},
+	}
+	for _, c := range cases {
+		if actual := c.expr.String(); actual != c.expected {
+			t.Errorf("expected %s, got %s", c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package spmock
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// handleBatch handles OData $batch requests, changeset requests are processed one by one without rollback
+// as SharePoint does, the responses are flattened to a single multipart response
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	requests, err := readBatchRequests(r.Header.Get("Content-Type"), r.Body)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	boundary := "batchresponse_" + newGUID()
+	body := &bytes.Buffer{}
+	for _, req := range requests {
+		// Batched requests are authorized with the batch request
+		if req.Header.Get("X-RequestDigest") == "" {
+			req.Header.Set("X-RequestDigest", r.Header.Get("X-RequestDigest"))
+		}
+		rec := httptest.NewRecorder()
+		s.serve(rec, req)
+		res := rec.Result()
+
+		body.WriteString("--" + boundary + "\r\n")
+		body.WriteString("Content-Type: application/http\r\nContent-Transfer-Encoding: binary\r\n\r\n")
+		body.WriteString(fmt.Sprintf("HTTP/1.1 %d %s\r\n", res.StatusCode, http.StatusText(res.StatusCode)))
+		for key := range res.Header {
+			body.WriteString(key + ": " + res.Header.Get(key) + "\r\n")
+		}
+		body.WriteString("\r\n")
+		body.Write(rec.Body.Bytes())
+		body.WriteString("\r\n")
+	}
+	body.WriteString("--" + boundary + "--\r\n")
+
+	w.Header().Set("Content-Type", "multipart/mixed; boundary="+boundary)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body.Bytes())
+}
+
+// readBatchRequests reads multipart $batch requests, changesets are flattened
+func readBatchRequests(contentType string, body io.Reader) ([]*http.Request, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return nil, badRequest("The batch request must have a \"Content-Type\" header with multipart/mixed media type and a boundary.")
+	}
+	var requests []*http.Request
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return requests, nil
+		}
+		if err != nil {
+			return nil, badRequest("Invalid batch request. %s", err)
+		}
+		partType := part.Header.Get("Content-Type")
+		if strings.HasPrefix(strings.ToLower(partType), "multipart/") {
+			nested, err := readBatchRequests(partType, part)
+			if err != nil {
+				return nil, err
+			}
+			requests = append(requests, nested...)
+			continue
+		}
+		if !strings.EqualFold(partType, "application/http") {
+			return nil, badRequest("The batch part content type %s is not supported.", partType)
+		}
+		content, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, badRequest("Invalid batch request part. %s", err)
+		}
+		buf := bufio.NewReader(bytes.NewReader(content))
+		req, err := http.ReadRequest(buf)
+		if err != nil {
+			return nil, badRequest("Invalid batch request part. %s", err)
+		}
+		// Parts have no Content-Length, the body lasts until the part's end
+		reqBody, _ := ioutil.ReadAll(buf)
+		req.Body = ioutil.NopCloser(bytes.NewReader(bytes.TrimRight(reqBody, "\r\n")))
+		requests = append(requests, req)
+	}
+}
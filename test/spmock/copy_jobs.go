@@ -0,0 +1,314 @@
+package spmock
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// copyJob copy migration job state, the job is started on the first progress request and is processed on the second one
+type copyJob struct {
+	id      string
+	sources []string // server relative URLs
+	dest    string   // destination folder server relative URL
+	options copyJobOptions
+	polls   int
+}
+
+// copyJobOptions SP.CopyMigrationOptions
+type copyJobOptions struct {
+	NameConflictBehavior int  // 0 fail, 1 replace, 2 keep both
+	IsMoveMode           bool // sources are removed after copying
+	ExcludeChildren      bool // folders are copied without content
+}
+
+// createCopyJobs handles Site/CreateCopyJobs, a single job is created for all the sources,
+// verbose requests should wrap the sources collection with `results`
+func (s *Server) createCopyJobs(w http.ResponseWriter, r *http.Request) error {
+	body := &struct {
+		ExportObjectURIs json.RawMessage `json:"exportObjectUris"`
+		DestinationURI   string          `json:"destinationUri"`
+		Options          copyJobOptions  `json:"options"`
+	}{}
+	if err := readBodyTo(r, body); err != nil {
+		return err
+	}
+	sources, err := readCollection(r, body.ExportObjectURIs)
+	if err != nil {
+		return err
+	}
+	dest := s.serverPath(body.DestinationURI)
+	if _, ok := s.folders[strings.ToLower(dest)]; !ok {
+		return notFound("File Not Found.")
+	}
+	job := &copyJob{id: newGUID(), dest: dest, options: body.Options}
+	uniqueIDs := []string{}
+	for _, uri := range sources {
+		src := s.serverPath(uri)
+		job.sources = append(job.sources, src)
+		if f, ok := s.files[strings.ToLower(src)]; ok {
+			uniqueIDs = append(uniqueIDs, f.uniqueID)
+		} else if f, ok := s.folders[strings.ToLower(src)]; ok {
+			uniqueIDs = append(uniqueIDs, f.uniqueID)
+		}
+	}
+	s.copyJobs[job.id] = job
+
+	key := uuid.New()
+	info := map[string]interface{}{
+		"EncryptionKey":           base64.StdEncoding.EncodeToString(key[:]),
+		"JobId":                   job.id,
+		"JobQueueUri":             "https://mock.queue.core.windows.net/" + job.id,
+		"SourceListItemUniqueIds": uniqueIDs,
+	}
+	var jobs interface{} = []interface{}{info}
+	if detectMode(r.Header.Get("Accept")) == modeVerbose {
+		info["SourceListItemUniqueIds"] = map[string]interface{}{"results": uniqueIDs}
+		jobs = map[string]interface{}{"results": jobs}
+	}
+	s.writeValue(w, r, "CreateCopyJobs", jobs)
+	return nil
+}
+
+// getCopyJobProgress handles Site/GetCopyJobProgress, the processed job is removed
+func (s *Server) getCopyJobProgress(w http.ResponseWriter, r *http.Request) error {
+	body := &struct {
+		Info struct {
+			JobID string `json:"JobId"`
+		} `json:"copyJobInfo"`
+	}{}
+	if err := readBodyTo(r, body); err != nil {
+		return err
+	}
+	state, logs := 0, []string{}
+	if job, ok := s.copyJobs[body.Info.JobID]; ok {
+		job.polls++
+		if job.polls == 1 {
+			state = 4
+			logs = append(logs, copyJobLog("JobStart", map[string]interface{}{"JobId": job.id}))
+		} else {
+			logs = append(logs, s.runCopyJob(job)...)
+			delete(s.copyJobs, job.id)
+		}
+	}
+
+	mode := detectMode(r.Header.Get("Accept"))
+	var payload interface{} = map[string]interface{}{"JobState": state, "Logs": logs}
+	if mode == modeVerbose {
+		payload = map[string]interface{}{"d": map[string]interface{}{"GetCopyJobProgress": map[string]interface{}{
+			"JobState": state,
+			"Logs":     map[string]interface{}{"results": logs},
+		}}}
+	}
+	writeJSON(w, http.StatusOK, mode.contentType(), payload)
+	return nil
+}
+
+// runCopyJob copies or moves the job sources, returns per-item error logs and the job end log
+func (s *Server) runCopyJob(job *copyJob) []string {
+	var logs []string
+	processed, errors := 0, 0
+	for _, src := range job.sources {
+		target := job.dest + "/" + path.Base(src)
+		var n int
+		var err error
+		if _, ok := s.files[strings.ToLower(src)]; ok {
+			_, err = s.copyFile(src, target, job.options.NameConflictBehavior, job.options.IsMoveMode)
+			n = 1
+		} else if _, ok := s.folders[strings.ToLower(src)]; ok {
+			n, err = s.copyFolder(src, target, job.options.NameConflictBehavior, job.options.IsMoveMode, job.options.ExcludeChildren)
+		} else {
+			err = notFound("File Not Found.")
+		}
+		processed += n
+		if err != nil {
+			errors++
+			logs = append(logs, copyJobLog("JobError", map[string]interface{}{
+				"Message":   err.Error(),
+				"Url":       s.URL + src,
+				"ErrorCode": "-2147024816",
+				"ErrorType": "Microsoft.SharePoint.SPException",
+			}))
+		}
+	}
+	return append(logs, copyJobLog("JobEnd", map[string]interface{}{
+		"JobId":                  job.id,
+		"ObjectsProcessed":       strconv.Itoa(processed),
+		"TotalExpectedSPObjects": strconv.Itoa(processed),
+		"TotalErrors":            strconv.Itoa(errors),
+	}))
+}
+
+// moveCopyUtil handles SP.MoveCopyUtil file and folder methods
+func (s *Server) moveCopyUtil(w http.ResponseWriter, r *http.Request, method string) error {
+	body := &struct {
+		SrcPath struct {
+			DecodedURL string `json:"DecodedUrl"`
+		} `json:"srcPath"`
+		DestPath struct {
+			DecodedURL string `json:"DecodedUrl"`
+		} `json:"destPath"`
+		Overwrite bool `json:"overwrite"`
+		Options   struct {
+			KeepBoth bool `json:"KeepBoth"`
+		} `json:"options"`
+	}{}
+	if err := readBodyTo(r, body); err != nil {
+		return err
+	}
+	src, dest := s.serverPath(body.SrcPath.DecodedURL), s.serverPath(body.DestPath.DecodedURL)
+	conflict := 0
+	if body.Overwrite {
+		conflict = 1
+	}
+	if body.Options.KeepBoth {
+		conflict = 2
+	}
+	move := strings.HasPrefix(method, "move")
+	if strings.HasSuffix(method, "filebypath") {
+		if _, ok := s.files[strings.ToLower(src)]; !ok {
+			return notFound("File Not Found.")
+		}
+		if _, err := s.copyFile(src, dest, conflict, move); err != nil {
+			return err
+		}
+	} else {
+		if _, ok := s.folders[strings.ToLower(src)]; !ok {
+			return notFound("File Not Found.")
+		}
+		if _, err := s.copyFolder(src, dest, conflict, move, false); err != nil {
+			return err
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// copyFile copies a file resolving the name conflict, returns the copy URL
+func (s *Server) copyFile(src string, dest string, conflict int, move bool) (string, error) {
+	if _, exists := s.files[strings.ToLower(dest)]; exists {
+		switch conflict {
+		case 0:
+			return "", &apiError{
+				status:  http.StatusBadRequest,
+				code:    "-2130575257, Microsoft.SharePoint.SPException",
+				message: fmt.Sprintf("A file with the name %s already exists.", dest),
+			}
+		case 2:
+			dest = s.freeName(dest)
+		}
+	}
+	f := s.files[strings.ToLower(src)]
+	if _, err := s.putFile(dest, f.content, true); err != nil {
+		return "", err
+	}
+	if move {
+		s.deleteFile(src)
+	}
+	return dest, nil
+}
+
+// copyFolder copies a folder with its content, returns the number of copied objects
+func (s *Server) copyFolder(src string, dest string, conflict int, move bool, excludeChildren bool) (int, error) {
+	if strings.HasPrefix(strings.ToLower(dest)+"/", strings.ToLower(src)+"/") {
+		return 0, badRequest("The destination %s is inside the source folder.", dest)
+	}
+	if _, exists := s.folders[strings.ToLower(dest)]; exists && conflict == 2 {
+		dest = s.freeName(dest)
+	} else if exists && conflict == 0 {
+		return 0, &apiError{
+			status:  http.StatusBadRequest,
+			code:    "-2130575257, Microsoft.SharePoint.SPException",
+			message: fmt.Sprintf("A folder with the name %s already exists.", dest),
+		}
+	}
+	if _, ok := s.folders[strings.ToLower(path.Dir(dest))]; !ok {
+		return 0, notFound("File Not Found.")
+	}
+	if _, err := s.ensureFolder(dest); err != nil {
+		return 0, err
+	}
+	copied := 1
+	if !excludeChildren {
+		for _, f := range s.childFiles(src) {
+			if _, err := s.copyFile(f.url, dest+"/"+path.Base(f.url), 1, false); err != nil {
+				return copied, err
+			}
+			copied++
+		}
+		for _, f := range s.childFolders(src) {
+			n, err := s.copyFolder(f.url, dest+"/"+path.Base(f.url), 1, false, false)
+			copied += n
+			if err != nil {
+				return copied, err
+			}
+		}
+	}
+	if move {
+		s.deleteFolder(src)
+	}
+	return copied, nil
+}
+
+// freeName gets a free file or folder name with a number suffix, e.g. "file 1.txt"
+func (s *Server) freeName(itemURL string) string {
+	ext := path.Ext(itemURL)
+	if _, ok := s.folders[strings.ToLower(itemURL)]; ok {
+		ext = ""
+	}
+	base := strings.TrimSuffix(itemURL, ext)
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s %d%s", base, i, ext)
+		_, fileExists := s.files[strings.ToLower(name)]
+		_, folderExists := s.folders[strings.ToLower(name)]
+		if !fileExists && !folderExists {
+			return name
+		}
+	}
+}
+
+// serverPath gets server relative URL of an absolute or relative URL
+func (s *Server) serverPath(itemURL string) string {
+	if u, err := url.Parse(itemURL); err == nil && u.IsAbs() {
+		itemURL = u.Path
+	}
+	return s.absPath(itemURL)
+}
+
+// copyJobLog formats copy job log entry
+func copyJobLog(event string, props map[string]interface{}) string {
+	props["Event"] = event
+	props["Time"] = "01/02/2020 15:04:05.000"
+	data, _ := json.Marshal(props)
+	return string(data)
+}
+
+// readCollection reads strings collection parameter, verbose requests wrap collections with `results`
+func readCollection(r *http.Request, raw json.RawMessage) ([]string, error) {
+	var values []string
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+		if detectMode(r.Header.Get("Content-Type")) == modeVerbose {
+			return nil, badRequest("An unexpected 'StartArray' node was found when reading from the JSON reader. A 'StartObject' node was expected.")
+		}
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return nil, badRequest("Invalid JSON. %s", err)
+		}
+		return values, nil
+	}
+	wrapper := &struct {
+		Results []string `json:"results"`
+	}{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, wrapper); err != nil {
+			return nil, badRequest("Invalid JSON. %s", err)
+		}
+	}
+	return wrapper.Results, nil
+}
@@ -0,0 +1,226 @@
+package spmock
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// chunkedUpload handles StartUpload, ContinueUpload, FinishUpload and CancelUpload file methods,
+// chunks are accepted only at the session's current offset
+func (s *Server) chunkedUpload(w http.ResponseWriter, r *http.Request, n *node, q *query) error {
+	if strings.HasPrefix(s.LibraryVersion, "15.") {
+		return resourceNotFound(n.action.name)
+	}
+	uploadID, _ := n.action.arg("uploadid")
+	key := strings.ToLower(uploadID)
+	if uploadID == "" {
+		return badRequest("The upload ID is required.")
+	}
+
+	if n.action.name == "startupload" {
+		content, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		if _, ok := s.uploads[key]; ok {
+			return badRequest("The upload session %s already exists.", uploadID)
+		}
+		s.uploads[key] = &upload{fileURL: n.url, content: content}
+		s.writeValue(w, r, "StartUpload", strconv.Itoa(len(content)))
+		return nil
+	}
+
+	u, ok := s.uploads[key]
+	if !ok || !strings.EqualFold(u.fileURL, n.url) {
+		return badRequest("The upload session %s was not found.", uploadID)
+	}
+	if n.action.name == "cancelupload" {
+		delete(s.uploads, key)
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+
+	offset, _ := strconv.Atoi(n.action.named["fileoffset"])
+	if offset != len(u.content) {
+		return badRequest("The file offset %d doesn't match the upload session offset %d.", offset, len(u.content))
+	}
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	u.content = append(u.content, content...)
+
+	if n.action.name == "continueupload" {
+		s.writeValue(w, r, "ContinueUpload", strconv.Itoa(len(u.content)))
+		return nil
+	}
+	delete(s.uploads, key)
+	f, err := s.putFile(u.fileURL, u.content, true)
+	if err != nil {
+		return err
+	}
+	s.writeEntity(w, r, http.StatusOK, s.fileEntity(f), q, false)
+	return nil
+}
+
+// etag gets file ETag
+func (f *file) etag() string {
+	return fmt.Sprintf(`"{%s},%d"`, strings.ToUpper(f.uniqueID), f.version)
+}
+
+// fileContent writes file content, a single `bytes=start-end` Range is responded with 206 Partial Content
+func (s *Server) fileContent(w http.ResponseWriter, r *http.Request, f *file) error {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("ETag", f.etag())
+	w.Header().Set("Accept-Ranges", "bytes")
+	rng := r.Header.Get("Range")
+	if rng == "" {
+		w.Header().Set("Content-Length", strconv.Itoa(len(f.content)))
+		_, _ = w.Write(f.content)
+		return nil
+	}
+	size := len(f.content)
+	var start, end int
+	if n, err := fmt.Sscanf(rng, "bytes=%d-%d", &start, &end); n == 0 || (err != nil && n != 1) {
+		return badRequest("Invalid range %s.", rng)
+	} else if n == 1 {
+		end = size - 1
+	}
+	if end >= size {
+		end = size - 1
+	}
+	if start > end {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		return nil
+	}
+	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+	w.WriteHeader(http.StatusPartialContent)
+	_, _ = w.Write(f.content[start : end+1])
+	return nil
+}
+
+// filePropertiesEntity gets file property bag entity
+func (s *Server) filePropertiesEntity(f *file) *entity {
+	props := map[string]interface{}{}
+	for key, val := range f.props {
+		props[strings.Replace(key, "_", "_x005f_", -1)] = val
+	}
+	return &entity{
+		typ:   "SP.PropertyValues",
+		uri:   fmt.Sprintf("%s/_api/Web/GetFileByServerRelativeUrl('%s')/Properties", s.SiteURL(), escapeLiteral(f.url)),
+		props: props,
+	}
+}
+
+// csomRequest CSOM request package methods
+type csomRequest struct {
+	Actions     []csomMethod `xml:"Actions>Method"`
+	ObjectPaths []csomMethod `xml:"ObjectPaths>Method"`
+}
+
+type csomMethod struct {
+	Name       string   `xml:"Name,attr"`
+	Parameters []string `xml:"Parameters>Parameter"`
+}
+
+// setFileProperties handles `Web.GetFileById(id).Properties.SetFieldValue(key, value)` CSOM requests,
+// returns false for other requests
+func (s *Server) setFileProperties(body []byte) ([]byte, bool) {
+	req := &csomRequest{}
+	if err := xml.Unmarshal(body, req); err != nil {
+		return nil, false
+	}
+	var target *file
+	for _, m := range req.ObjectPaths {
+		if m.Name == "GetFileById" && len(m.Parameters) == 1 {
+			for _, f := range s.files {
+				if strings.EqualFold(f.uniqueID, m.Parameters[0]) {
+					target = f
+				}
+			}
+		}
+	}
+	if target == nil || len(req.Actions) == 0 {
+		return nil, false
+	}
+	props := map[string]string{}
+	for _, m := range req.Actions {
+		if m.Name != "SetFieldValue" || len(m.Parameters) != 2 {
+			return nil, false
+		}
+		props[m.Parameters[0]] = m.Parameters[1]
+	}
+	if target.props == nil {
+		target.props = map[string]string{}
+	}
+	for key, val := range props {
+		target.props[key] = val
+	}
+	res, _ := json.Marshal([]interface{}{map[string]interface{}{
+		"SchemaVersion":      "15.0.0.0",
+		"LibraryVersion":     s.LibraryVersion,
+		"ErrorInfo":          nil,
+		"TraceCorrelationId": newGUID(),
+	}})
+	return res, true
+}
+
+// fileVersionEntities gets previous file versions, version IDs are 512 per major version
+func (s *Server) fileVersionEntities(uri string, f *file) []*entity {
+	var entities []*entity
+	for i, v := range f.versions {
+		id := (i + 1) * 512
+		entities = append(entities, &entity{
+			typ: "SP.FileVersion",
+			uri: fmt.Sprintf("%s(%d)", uri, id),
+			props: map[string]interface{}{
+				"ID":               id,
+				"VersionLabel":     fmt.Sprintf("%d.0", i+1),
+				"IsCurrentVersion": false,
+				"Created":          v.created.Format(time.RFC3339),
+				"Size":             v.size,
+				"Url":              fmt.Sprintf("_vti_history/%d%s", id, strings.TrimPrefix(f.url, SitePath)),
+			},
+		})
+	}
+	return entities
+}
+
+// storageMetricsEntity gets folder tree storage metrics, the total size includes previous versions
+func (s *Server) storageMetricsEntity(uri string, folderURL string) *entity {
+	prefix := strings.ToLower(folderURL) + "/"
+	count, streamSize, totalSize := 0, 0, 0
+	modified := s.folders[strings.ToLower(folderURL)].modified
+	for key, f := range s.files {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		count++
+		streamSize += len(f.content)
+		totalSize += len(f.content)
+		for _, v := range f.versions {
+			totalSize += v.size
+		}
+		if f.modified.After(modified) {
+			modified = f.modified
+		}
+	}
+	return &entity{
+		typ: "SP.StorageMetrics",
+		uri: uri,
+		props: map[string]interface{}{
+			"LastModified":        modified.Format(time.RFC3339),
+			"TotalFileCount":      strconv.Itoa(count),
+			"TotalFileStreamSize": strconv.Itoa(streamSize),
+			"TotalSize":           strconv.Itoa(totalSize),
+		},
+	}
+}
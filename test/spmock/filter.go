@@ -0,0 +1,402 @@
+package spmock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// filterExpr compiled OData $filter expression
+type filterExpr func(props map[string]interface{}) interface{}
+
+// filterParser OData $filter subset parser: eq, ne, gt, ge, lt, le, and, or, not, parentheses,
+// startswith, endswith, substringof functions, string, number, boolean, null, datetime and guid literals
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseFilter compiles OData $filter expression
+func parseFilter(filter string) (filterExpr, error) {
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %s", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// match checks if props match the expression
+func (expr filterExpr) match(props map[string]interface{}) bool {
+	b, ok := expr(props).(bool)
+	return ok && b
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *filterParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *filterParser) expect(token string) error {
+	if t := p.next(); t != token {
+		return fmt.Errorf("expected %s, got %q", token, t)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.ToLower(p.peek()) == "or" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(props map[string]interface{}) interface{} { return l.match(props) || right.match(props) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for strings.ToLower(p.peek()) == "and" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(props map[string]interface{}) interface{} { return l.match(props) && right.match(props) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if strings.ToLower(p.peek()) == "not" {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(props map[string]interface{}) interface{} { return !expr.match(props) }, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(p.peek())
+	switch op {
+	case "eq", "ne", "gt", "ge", "lt", "le":
+		p.next()
+	default:
+		return left, nil
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return func(props map[string]interface{}) interface{} {
+		return compareValues(left(props), right(props), op)
+	}, nil
+}
+
+func (p *filterParser) parseOperand() (filterExpr, error) {
+	t := p.next()
+	switch {
+	case t == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case t == "(":
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(")")
+	case strings.HasPrefix(t, "'") || strings.Contains(t, "'"):
+		return literalExpr(parseFilterLiteral(t)), nil
+	case t == "true" || t == "false":
+		return literalExpr(t == "true"), nil
+	case t == "null":
+		return literalExpr(nil), nil
+	}
+	if n, err := strconv.ParseFloat(strings.TrimRight(t, "LlMmDd"), 64); err == nil {
+		return literalExpr(n), nil
+	}
+	if p.peek() == "(" {
+		return p.parseFunc(strings.ToLower(t))
+	}
+	field := t
+	return func(props map[string]interface{}) interface{} { return fieldValue(props, field) }, nil
+}
+
+func (p *filterParser) parseFunc(name string) (filterExpr, error) {
+	p.next() // (
+	var args []filterExpr
+	for p.peek() != ")" {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek() == "," {
+			p.next()
+		}
+	}
+	p.next() // )
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%s expects two arguments", name)
+	}
+	str := func(v interface{}) string {
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprintf("%v", v)
+	}
+	switch name {
+	case "startswith":
+		return func(props map[string]interface{}) interface{} {
+			return strings.HasPrefix(str(args[0](props)), str(args[1](props)))
+		}, nil
+	case "endswith":
+		return func(props map[string]interface{}) interface{} {
+			return strings.HasSuffix(str(args[0](props)), str(args[1](props)))
+		}, nil
+	case "substringof":
+		return func(props map[string]interface{}) interface{} {
+			return strings.Contains(str(args[1](props)), str(args[0](props)))
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported function %s", name)
+}
+
+func literalExpr(value interface{}) filterExpr {
+	return func(props map[string]interface{}) interface{} { return value }
+}
+
+// parseFilterLiteral parses quoted literals: 'string', datetime'...', guid'...'
+func parseFilterLiteral(token string) interface{} {
+	lower := strings.ToLower(token)
+	value := unquote(token)
+	if strings.HasPrefix(lower, "datetime'") {
+		if d, err := parseDate(value); err == nil {
+			return d
+		}
+	}
+	if strings.HasPrefix(lower, "guid'") {
+		return strings.ToLower(value)
+	}
+	return value
+}
+
+// fieldValue gets a property value, lookup projections like Author/Title are resolved from nested objects
+func fieldValue(props map[string]interface{}, field string) interface{} {
+	parts := strings.SplitN(field, "/", 2)
+	value, ok := props[parts[0]]
+	if !ok {
+		for key, val := range props {
+			if strings.EqualFold(key, parts[0]) {
+				value = val
+				break
+			}
+		}
+	}
+	if len(parts) == 2 {
+		if nested, ok := value.(map[string]interface{}); ok {
+			return fieldValue(nested, parts[1])
+		}
+		return nil
+	}
+	return value
+}
+
+// compareValues compares two values with OData comparison operator
+func compareValues(left interface{}, right interface{}, op string) bool {
+	if left == nil || right == nil {
+		switch op {
+		case "eq":
+			return left == nil && right == nil
+		case "ne":
+			return !(left == nil && right == nil)
+		}
+		return false
+	}
+
+	cmp, ok := compareTyped(left, right)
+	if !ok {
+		return op == "ne"
+	}
+	switch op {
+	case "eq":
+		return cmp == 0
+	case "ne":
+		return cmp != 0
+	case "gt":
+		return cmp > 0
+	case "ge":
+		return cmp >= 0
+	case "lt":
+		return cmp < 0
+	case "le":
+		return cmp <= 0
+	}
+	return false
+}
+
+// compareTyped compares values converting them to a common type, returns false when values are not comparable
+func compareTyped(left interface{}, right interface{}) (int, bool) {
+	if lt, ok := toTime(left); ok {
+		if rt, ok := toTime(right); ok {
+			switch {
+			case lt.Before(rt):
+				return -1, true
+			case lt.After(rt):
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	if ln, ok := toNumber(left); ok {
+		if rn, ok := toNumber(right); ok {
+			switch {
+			case ln < rn:
+				return -1, true
+			case ln > rn:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	if lb, ok := left.(bool); ok {
+		if rb, ok := right.(bool); ok {
+			if lb == rb {
+				return 0, true
+			}
+			if rb {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	ls, lok := left.(string)
+	rs, rok := right.(string)
+	if lok && rok {
+		return strings.Compare(strings.ToLower(ls), strings.ToLower(rs)), true
+	}
+	return 0, false
+}
+
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		if d, err := parseDate(v); err == nil {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if d, err := time.Parse(layout, value); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("can't parse date: %s", value)
+}
+
+// tokenizeFilter splits $filter expression to tokens
+func tokenizeFilter(filter string) ([]string, error) {
+	var tokens []string
+	i := 0
+	for i < len(filter) {
+		c := filter[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '\'':
+			end, err := quotedEnd(filter, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, filter[i:end])
+			i = end
+		default:
+			start := i
+			for i < len(filter) && !strings.ContainsRune(" \t\n(),'", rune(filter[i])) {
+				i++
+			}
+			// Typed literal, e.g. datetime'2020-01-01T00:00:00Z'
+			if i < len(filter) && filter[i] == '\'' {
+				end, err := quotedEnd(filter, i)
+				if err != nil {
+					return nil, err
+				}
+				i = end
+			}
+			tokens = append(tokens, filter[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+// quotedEnd finds the end position of a quoted literal respecting doubled quotes escaping
+func quotedEnd(s string, start int) (int, error) {
+	for i := start + 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\'' {
+			i++
+			continue
+		}
+		return i + 1, nil
+	}
+	return 0, fmt.Errorf("unterminated string literal")
+}
@@ -0,0 +1,195 @@
+package spmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// formDateLayouts ValidateUpdateListItem dates formats, en-US regional settings
+var formDateLayouts = []string{"1/2/2006 3:04 PM", "1/2/2006 15:04", "1/2/2006", time.RFC3339}
+
+func (s *Server) attachmentNode(attachments *node, name string) (*node, error) {
+	if attachments.item.attachmentByName(name) == nil {
+		return nil, notFound("File Not Found.")
+	}
+	return &node{
+		kind: "attachment",
+		uri:  fmt.Sprintf("%s('%s')", attachments.uri, name),
+		list: attachments.list,
+		item: attachments.item,
+		url:  name,
+	}, nil
+}
+
+func (s *Server) attachmentEntity(collectionURI string, l *list, it *item, a *attachment) *entity {
+	return &entity{
+		typ: "SP.Attachment",
+		uri: fmt.Sprintf("%s('%s')", collectionURI, a.name),
+		props: map[string]interface{}{
+			"FileName":          a.name,
+			"ServerRelativeUrl": fmt.Sprintf("%s/Attachments/%d/%s", l.url, it.id, a.name),
+		},
+	}
+}
+
+func (it *item) attachmentByName(name string) *attachment {
+	for _, a := range it.attachments {
+		if strings.EqualFold(a.name, name) {
+			return a
+		}
+	}
+	return nil
+}
+
+func (it *item) deleteAttachment(name string) {
+	for i, a := range it.attachments {
+		if strings.EqualFold(a.name, name) {
+			it.attachments = append(it.attachments[:i], it.attachments[i+1:]...)
+			return
+		}
+	}
+}
+
+// authorID gets item's Author/Editor user ID, the mock admin by default
+func authorID(value interface{}) int {
+	if id, _ := toNumber(value); id > 0 {
+		return int(id)
+	}
+	return 1
+}
+
+// validateUpdateListItem updates item's fields with form values, including read only system fields,
+// values which fail validation are reported with HasException
+func (s *Server) validateUpdateListItem(w http.ResponseWriter, r *http.Request, l *list, it *item) error {
+	body := &struct {
+		FormValues []struct {
+			FieldName  string `json:"FieldName"`
+			FieldValue string `json:"FieldValue"`
+		} `json:"formValues"`
+		NewDocumentUpdate bool `json:"bNewDocumentUpdate"`
+	}{}
+	if err := readBodyTo(r, body); err != nil {
+		return err
+	}
+
+	props := map[string]interface{}{}
+	var results []interface{}
+	for _, fv := range body.FormValues {
+		key, value, err := s.formValue(l, fv.FieldName, fv.FieldValue)
+		result := map[string]interface{}{
+			"FieldName":    fv.FieldName,
+			"FieldValue":   fv.FieldValue,
+			"HasException": err != nil,
+			"ErrorCode":    0,
+			"ErrorMessage": nil,
+		}
+		if err != nil {
+			result["ErrorCode"] = -2146232832
+			result["ErrorMessage"] = err.Error()
+		} else {
+			props[key] = value
+		}
+		results = append(results, result)
+	}
+
+	// Failed validation doesn't update the item
+	for _, res := range results {
+		if res.(map[string]interface{})["HasException"] == true {
+			props = nil
+			break
+		}
+	}
+	if props != nil {
+		if _, ok := props["Modified"]; !ok && !body.NewDocumentUpdate {
+			props["Modified"] = time.Now().UTC().Format(time.RFC3339)
+		}
+		for key, val := range props {
+			it.props[key] = val
+		}
+	}
+
+	mode := detectMode(r.Header.Get("Accept"))
+	var payload interface{} = map[string]interface{}{"value": results}
+	if mode == modeVerbose {
+		payload = map[string]interface{}{"d": map[string]interface{}{
+			"ValidateUpdateListItem": map[string]interface{}{"results": results},
+		}}
+	}
+	writeJSON(w, http.StatusOK, mode.contentType(), payload)
+	return nil
+}
+
+// formValue converts a form value string to item property
+func (s *Server) formValue(l *list, fieldName string, value string) (string, interface{}, error) {
+	f := l.fieldByName(fieldName)
+	if f == nil {
+		return "", nil, fmt.Errorf("Column '%s' does not exist. It may have been deleted by another user.", fieldName)
+	}
+	key := f.internalName
+	if value == "" {
+		if f.typeAsString == "User" || f.typeAsString == "Lookup" {
+			key += "Id"
+		}
+		return key, nil, nil
+	}
+	switch f.typeAsString {
+	case "User", "UserMulti":
+		var keys []struct {
+			Key string `json:"Key"`
+		}
+		if err := json.Unmarshal([]byte(value), &keys); err != nil || len(keys) == 0 {
+			return "", nil, fmt.Errorf("The user does not exist or is not unique.")
+		}
+		var ids []interface{}
+		for _, k := range keys {
+			ids = append(ids, s.web.ensureUser(k.Key)["Id"])
+		}
+		if f.typeAsString == "User" {
+			return key + "Id", ids[0], nil
+		}
+		return key + "Id", ids, nil
+	case "Lookup":
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("Invalid lookup value.")
+		}
+		return key + "Id", id, nil
+	case "DateTime":
+		for _, layout := range formDateLayouts {
+			if d, err := time.Parse(layout, value); err == nil {
+				return key, d.UTC().Format(time.RFC3339), nil
+			}
+		}
+		return "", nil, fmt.Errorf("Invalid date/time value")
+	case "Number", "Currency", "Integer":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("Invalid number value.")
+		}
+		return key, n, nil
+	case "Boolean":
+		return key, value == "1" || strings.EqualFold(value, "yes") || strings.EqualFold(value, "true"), nil
+	case "MultiChoice":
+		var choices []interface{}
+		for _, c := range strings.Split(strings.Trim(value, ";#"), ";#") {
+			choices = append(choices, c)
+		}
+		return key, choices, nil
+	case "TaxonomyFieldType", "TaxonomyFieldTypeMulti":
+		// `Label|TermGuid;` terms, only the terms GUIDs are validated
+		for _, term := range strings.Split(strings.TrimSuffix(value, ";"), ";") {
+			parts := strings.SplitN(term, "|", 2)
+			if _, err := uuid.Parse(parts[len(parts)-1]); err != nil {
+				return "", nil, fmt.Errorf("The given guid does not exist in the term store.")
+			}
+		}
+		return key, value, nil
+	}
+	return key, value, nil
+}
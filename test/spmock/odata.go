@@ -0,0 +1,374 @@
+package spmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// odataMode OData response metadata mode
+type odataMode int
+
+const (
+	modeVerbose odataMode = iota
+	modeMinimalMetadata
+	modeNoMetadata
+)
+
+// defaultPageSize items page size when no $top is provided, the same as in SharePoint
+const defaultPageSize = 100
+
+var skipTokenRgx = regexp.MustCompile(`(?i)p_ID=(\d+)`)
+
+// detectMode resolves OData mode from Accept header, minimalmetadata is the default
+func detectMode(accept string) odataMode {
+	accept = strings.ToLower(accept)
+	switch {
+	case strings.Contains(accept, "odata=verbose"):
+		return modeVerbose
+	case strings.Contains(accept, "odata=nometadata"):
+		return modeNoMetadata
+	}
+	return modeMinimalMetadata
+}
+
+func (mode odataMode) contentType() string {
+	switch mode {
+	case modeVerbose:
+		return "application/json;odata=verbose;charset=utf-8"
+	case modeNoMetadata:
+		return "application/json;odata=nometadata;streaming=true;charset=utf-8"
+	}
+	return "application/json;odata=minimalmetadata;streaming=true;charset=utf-8"
+}
+
+// entity REST API entity to render
+type entity struct {
+	typ   string // OData type, e.g. SP.List
+	uri   string // absolute API URI
+	etag  string
+	props map[string]interface{}
+}
+
+// query parsed OData query options
+type query struct {
+	selects   []string
+	filter    filterExpr
+	top       int // -1 when not provided
+	skipToken int // p_ID value from $skiptoken
+	skip      int // $skip value, used by not list items collections
+	orderBy   []orderField
+	values    url.Values
+}
+
+type orderField struct {
+	name string
+	desc bool
+}
+
+// parseQuery parses $select, $filter, $top, $skip, $skiptoken and $orderby query options
+func parseQuery(values url.Values) (*query, error) {
+	q := &query{top: -1, values: values}
+	for _, sel := range strings.Split(values.Get("$select"), ",") {
+		if sel = strings.TrimSpace(sel); sel != "" && sel != "*" {
+			q.selects = append(q.selects, sel)
+		}
+	}
+	if filter := values.Get("$filter"); filter != "" {
+		expr, err := parseFilter(filter)
+		if err != nil {
+			return nil, badRequest("The expression %q is not valid: %s", filter, err)
+		}
+		q.filter = expr
+	}
+	if top := values.Get("$top"); top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n < 0 {
+			return nil, badRequest("Invalid $top value: %s", top)
+		}
+		q.top = n
+	}
+	if skip := values.Get("$skip"); skip != "" {
+		n, err := strconv.Atoi(skip)
+		if err != nil || n < 0 {
+			return nil, badRequest("Invalid $skip value: %s", skip)
+		}
+		q.skip = n
+	}
+	if m := skipTokenRgx.FindStringSubmatch(values.Get("$skiptoken")); m != nil {
+		q.skipToken, _ = strconv.Atoi(m[1])
+	}
+	for _, order := range strings.Split(values.Get("$orderby"), ",") {
+		parts := strings.Fields(order)
+		if len(parts) == 0 {
+			continue
+		}
+		q.orderBy = append(q.orderBy, orderField{
+			name: parts[0],
+			desc: len(parts) > 1 && strings.EqualFold(parts[1], "desc"),
+		})
+	}
+	return q, nil
+}
+
+// apply filters, sorts and limits entities, returns the page and a flag if there are more entities
+func (q *query) apply(entities []*entity, pageSize int) ([]*entity, bool) {
+	var res []*entity
+	for _, e := range entities {
+		if q.filter == nil || q.filter.match(e.props) {
+			res = append(res, e)
+		}
+	}
+	if len(q.orderBy) > 0 {
+		sort.SliceStable(res, func(i, j int) bool {
+			for _, o := range q.orderBy {
+				cmp, _ := compareTyped(fieldValue(res[i].props, o.name), fieldValue(res[j].props, o.name))
+				if cmp == 0 {
+					continue
+				}
+				return (cmp < 0) != o.desc
+			}
+			return false
+		})
+	}
+	if q.skipToken > 0 {
+		// Items are ordered by ID by default, otherwise the page starts after the item with the token's ID
+		start := len(res)
+		for i, e := range res {
+			id, _ := toNumber(e.props["Id"])
+			if len(q.orderBy) == 0 && int(id) > q.skipToken {
+				start = i
+				break
+			}
+			if len(q.orderBy) > 0 && int(id) == q.skipToken {
+				start = i + 1
+				break
+			}
+		}
+		res = res[start:]
+	}
+	if q.skip > 0 {
+		if q.skip > len(res) {
+			q.skip = len(res)
+		}
+		res = res[q.skip:]
+	}
+	top := q.top
+	if top == -1 {
+		top = pageSize
+	}
+	if top >= 0 && len(res) > top {
+		return res[:top], true
+	}
+	return res, false
+}
+
+// project applies $select to entity props
+func (q *query) project(e *entity, strict bool) (map[string]interface{}, error) {
+	res := map[string]interface{}{}
+	if len(q.selects) == 0 {
+		for key, val := range e.props {
+			if _, deferred := val.(map[string]interface{}); !deferred {
+				res[key] = val
+			}
+		}
+		return res, nil
+	}
+	for _, sel := range q.selects {
+		parts := strings.SplitN(sel, "/", 2)
+		key, ok := propKey(e.props, parts[0])
+		if !ok {
+			if strict {
+				return nil, badRequest("The field or property '%s' does not exist.", parts[0])
+			}
+			continue
+		}
+		if len(parts) == 1 {
+			res[key] = e.props[key]
+			continue
+		}
+		nested, _ := e.props[key].(map[string]interface{})
+		if nested == nil {
+			continue
+		}
+		projected, _ := res[key].(map[string]interface{})
+		if projected == nil {
+			projected = map[string]interface{}{}
+			res[key] = projected
+		}
+		if subKey, ok := propKey(nested, parts[1]); ok {
+			projected[subKey] = nested[subKey]
+		}
+	}
+	return res, nil
+}
+
+// propKey finds a property key ignoring case
+func propKey(props map[string]interface{}, name string) (string, bool) {
+	if _, ok := props[name]; ok {
+		return name, true
+	}
+	for key := range props {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// render renders entity props with metadata relevant to OData mode
+func (mode odataMode) render(e *entity, props map[string]interface{}, siteURL string) map[string]interface{} {
+	switch mode {
+	case modeVerbose:
+		metadata := map[string]interface{}{"id": e.uri, "uri": e.uri, "type": e.typ}
+		if e.etag != "" {
+			metadata["etag"] = e.etag
+		}
+		props["__metadata"] = metadata
+	case modeMinimalMetadata:
+		props["odata.type"] = e.typ
+		props["odata.id"] = e.uri
+		props["odata.editLink"] = strings.TrimPrefix(e.uri, siteURL+"/_api/")
+		if e.etag != "" {
+			props["odata.etag"] = e.etag
+		}
+	}
+	return props
+}
+
+// writeEntity writes a single entity response
+func (s *Server) writeEntity(w http.ResponseWriter, r *http.Request, status int, e *entity, q *query, strict bool) {
+	mode := detectMode(r.Header.Get("Accept"))
+	props, err := q.project(e, strict)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	props = mode.render(e, props, s.SiteURL())
+	var payload interface{} = props
+	switch mode {
+	case modeVerbose:
+		payload = map[string]interface{}{"d": props}
+	case modeMinimalMetadata:
+		props["odata.metadata"] = fmt.Sprintf("%s/_api/$metadata#%s/@Element", s.SiteURL(), e.typ)
+	}
+	writeJSON(w, status, mode.contentType(), payload)
+}
+
+// writeCollection writes entities collection response, nextLink is added when there are more list items
+func (s *Server) writeCollection(w http.ResponseWriter, r *http.Request, typ string, entities []*entity, q *query, pageSize int, strict bool) {
+	mode := detectMode(r.Header.Get("Accept"))
+	page, hasMore := q.apply(entities, pageSize)
+	results := []interface{}{}
+	for _, e := range page {
+		props, err := q.project(e, strict)
+		if err != nil {
+			s.writeError(w, r, err)
+			return
+		}
+		results = append(results, mode.render(e, props, s.SiteURL()))
+	}
+
+	// Only list items collections are paged with next links, other collections are cut with $top
+	nextLink := ""
+	if hasMore && len(page) > 0 && pageSize != -1 {
+		values := url.Values{}
+		for key, val := range q.values {
+			values[key] = val
+		}
+		values.Set("$skiptoken", fmt.Sprintf("Paged=TRUE&p_ID=%v", page[len(page)-1].props["Id"]))
+		nextLink = s.URL + r.URL.Path + "?" + values.Encode()
+	}
+
+	var payload map[string]interface{}
+	switch mode {
+	case modeVerbose:
+		d := map[string]interface{}{"results": results}
+		if nextLink != "" {
+			d["__next"] = nextLink
+		}
+		payload = map[string]interface{}{"d": d}
+	default:
+		payload = map[string]interface{}{"value": results}
+		if mode == modeMinimalMetadata {
+			payload["odata.metadata"] = fmt.Sprintf("%s/_api/$metadata#%s", s.SiteURL(), typ)
+		}
+		if nextLink != "" {
+			payload["odata.nextLink"] = nextLink
+		}
+	}
+	writeJSON(w, http.StatusOK, mode.contentType(), payload)
+}
+
+// writeValue writes a scalar value response, e.g. a result of a method call
+func (s *Server) writeValue(w http.ResponseWriter, r *http.Request, name string, value interface{}) {
+	mode := detectMode(r.Header.Get("Accept"))
+	var payload interface{} = map[string]interface{}{"value": value}
+	if mode == modeVerbose {
+		payload = map[string]interface{}{"d": map[string]interface{}{name: value}}
+	}
+	writeJSON(w, http.StatusOK, mode.contentType(), payload)
+}
+
+// apiError SharePoint REST API error
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string { return e.message }
+
+func badRequest(format string, args ...interface{}) *apiError {
+	return &apiError{
+		status:  http.StatusBadRequest,
+		code:    "-1, Microsoft.SharePoint.Client.InvalidClientQueryException",
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+func notFound(format string, args ...interface{}) *apiError {
+	return &apiError{
+		status:  http.StatusNotFound,
+		code:    "-2147024894, System.IO.FileNotFoundException",
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+func resourceNotFound(resource string) *apiError {
+	return &apiError{
+		status:  http.StatusNotFound,
+		code:    "-1, Microsoft.SharePoint.Client.ResourceNotFoundException",
+		message: fmt.Sprintf("Cannot find resource for the request %s.", resource),
+	}
+}
+
+// writeError writes SharePoint REST API error response
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	e, ok := err.(*apiError)
+	if !ok {
+		e = &apiError{status: http.StatusInternalServerError, code: "-1, System.Exception", message: err.Error()}
+	}
+	mode := detectMode(r.Header.Get("Accept"))
+	body := map[string]interface{}{
+		"code":    e.code,
+		"message": map[string]string{"lang": "en-US", "value": e.message},
+	}
+	key := "odata.error"
+	if mode == modeVerbose {
+		key = "error"
+	}
+	writeJSON(w, e.status, mode.contentType(), map[string]interface{}{key: body})
+}
+
+func writeJSON(w http.ResponseWriter, status int, contentType string, payload interface{}) {
+	data, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,108 @@
+package spmock
+
+import (
+	"strings"
+)
+
+// segment is an API URL path segment, e.g. `GetByTitle('List')`, `Items(1)` or `Add(overwrite=true,url='a.txt')`
+type segment struct {
+	name  string            // lower cased segment name
+	args  []string          // unnamed arguments with quotes trimmed
+	named map[string]string // named arguments with lower cased keys
+}
+
+// arg gets the first unnamed argument or a named one
+func (s *segment) arg(name string) (string, bool) {
+	if v, ok := s.named[name]; ok {
+		return v, true
+	}
+	if len(s.args) > 0 {
+		return s.args[0], true
+	}
+	return "", false
+}
+
+// splitPath splits API path to segments ignoring slashes inside of quotes and parentheses
+func splitPath(path string) []segment {
+	var segments []segment
+	depth := 0
+	inQuotes := false
+	start := 0
+	for i := 0; i <= len(path); i++ {
+		if i < len(path) {
+			switch c := path[i]; {
+			case c == '\'':
+				inQuotes = !inQuotes
+				continue
+			case inQuotes:
+				continue
+			case c == '(':
+				depth++
+				continue
+			case c == ')':
+				depth--
+				continue
+			case c != '/' || depth > 0:
+				continue
+			}
+		}
+		if part := path[start:i]; part != "" {
+			segments = append(segments, parseSegment(part))
+		}
+		start = i + 1
+	}
+	return segments
+}
+
+// parseSegment parses path segment name and arguments
+func parseSegment(part string) segment {
+	seg := segment{named: map[string]string{}}
+	pos := strings.Index(part, "(")
+	if pos == -1 || !strings.HasSuffix(part, ")") {
+		seg.name = strings.ToLower(part)
+		return seg
+	}
+	seg.name = strings.ToLower(part[:pos])
+	for _, arg := range splitArgs(part[pos+1 : len(part)-1]) {
+		if eq := strings.Index(arg, "="); eq != -1 && !strings.HasPrefix(arg, "'") {
+			seg.named[strings.ToLower(strings.TrimSpace(arg[:eq]))] = unquote(arg[eq+1:])
+			continue
+		}
+		seg.args = append(seg.args, unquote(arg))
+	}
+	return seg
+}
+
+// splitArgs splits arguments list by commas outside of quotes
+func splitArgs(args string) []string {
+	var res []string
+	inQuotes := false
+	start := 0
+	for i := 0; i <= len(args); i++ {
+		if i < len(args) {
+			if args[i] == '\'' {
+				inQuotes = !inQuotes
+			}
+			if inQuotes || args[i] != ',' {
+				continue
+			}
+		}
+		if arg := strings.TrimSpace(args[start:i]); arg != "" {
+			res = append(res, arg)
+		}
+		start = i + 1
+	}
+	return res
+}
+
+// unquote trims OData literal quotes and type prefix, e.g. guid'...', doubled quotes are unescaped
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if pos := strings.Index(value, "'"); pos > 0 && strings.HasSuffix(value, "'") && !strings.Contains(value[:pos], " ") {
+		value = value[pos:] // guid'...', datetime'...'
+	}
+	if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+		return strings.Replace(value[1:len(value)-1], "''", "'", -1)
+	}
+	return value
+}
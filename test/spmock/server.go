@@ -0,0 +1,1492 @@
+// Package spmock provides an in-memory SharePoint REST API stand-in server for offline tests.
+//
+// The server implements a meaningful subset of `/_api`: contextinfo, web, lists, items
+// (with $select, $filter, $top, $orderby and $skiptoken paging), RenderListDataAsStream,
+// lookup and user fields projections, EnsureUser, fields, folders and files with chunked uploads,
+// ranged downloads, property bags, file and item versions and storage metrics (other collections are paged with $skip),
+// responds in verbose, minimalmetadata and nometadata modes, handles OData $batch requests, validates X-RequestDigest,
+// handles file property bag CSOM updates and returns other ProcessQuery errors in CSOM shape. Copy migration jobs and MoveCopyUtil methods
+// copy and move files and folders within the mock site. It is paired with `auth/anon` strategy:
+//
+//	srv := spmock.NewServer()
+//	defer srv.Close()
+//	sp := api.NewSP(srv.Client())
+package spmock
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/koltyakov/gosip"
+	"github.com/koltyakov/gosip/auth/anon"
+)
+
+// SitePath mock site collection server relative URL
+const SitePath = "/sites/mock"
+
+// Server in-memory SharePoint REST API stand-in server
+type Server struct {
+	*httptest.Server
+
+	// ProcessQuery handles CSOM requests, a returned error is sent in CSOM ErrorInfo shape.
+	// When the handler is not set CSOM requests fail with NotSupportedException
+	ProcessQuery func(body []byte) ([]byte, error)
+
+	// LibraryVersion is reported in context info, "16.0.0.0" by default.
+	// Chunked upload methods are not available with "15.x" (SharePoint 2013) versions
+	LibraryVersion string
+
+	mu       sync.Mutex
+	web      *web
+	folders  map[string]*folder  // by lower cased server relative URL
+	files    map[string]*file    // by lower cased server relative URL
+	uploads  map[string]*upload  // chunked upload sessions by lower cased upload ID
+	copyJobs map[string]*copyJob // copy migration jobs by ID
+	digest   string
+}
+
+// CSOMError CSOM ErrorInfo, can be returned by ProcessQuery handler to control the error details
+type CSOMError struct {
+	Message  string
+	Code     int
+	TypeName string
+}
+
+// Error stringifies CSOM error
+func (e *CSOMError) Error() string { return e.Message }
+
+// node resolved API resource
+type node struct {
+	kind    string // root, site, web, user, lists, list, items, item, itemversions, itemversion, attachments, attachment, fields, field, folders, folder, files, file, value
+	uri     string // absolute API URI
+	list    *list
+	item    *item
+	field   *field
+	url     string // folder or file server relative URL
+	action  *segment
+	version int // list item version ID
+}
+
+var schemaAttrRgx = regexp.MustCompile(`(\w+)=["']([^"']*)["']`)
+
+// NewServer creates and starts mock server with a site containing "Documents" library
+func NewServer() *Server {
+	now := time.Now().UTC()
+	s := &Server{
+		LibraryVersion: "16.0.0.0",
+		web: &web{
+			id:      newGUID(),
+			title:   "Mock",
+			created: now,
+			users:   []map[string]interface{}{mockUser()},
+		},
+		folders:  map[string]*folder{},
+		files:    map[string]*file{},
+		uploads:  map[string]*upload{},
+		copyJobs: map[string]*copyJob{},
+		digest:   fmt.Sprintf("0x%s,%s", strings.ToUpper(hex.EncodeToString(uuid.New().NodeID())), now.Format(time.RFC1123)),
+	}
+	s.folders[strings.ToLower(SitePath)] = &folder{uniqueID: newGUID(), url: SitePath, created: now, modified: now}
+	s.addList("Documents", 101, "")
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// SiteURL gets mock site absolute URL
+func (s *Server) SiteURL() string {
+	return s.URL + SitePath
+}
+
+// AuthCnfg gets anonymous auth config targeting the mock site
+func (s *Server) AuthCnfg() *anon.AuthCnfg {
+	return &anon.AuthCnfg{SiteURL: s.SiteURL()}
+}
+
+// Client gets SharePoint client bound to the mock site
+func (s *Server) Client() *gosip.SPClient {
+	return &gosip.SPClient{AuthCnfg: s.AuthCnfg()}
+}
+
+// AddList adds a list (100) or a document library (101), returns list ID
+func (s *Server) AddList(title string, baseTemplate int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, err := s.addList(title, baseTemplate, "")
+	if err != nil {
+		return "", err
+	}
+	return l.id, nil
+}
+
+// AddField adds a field to the list, typeAsString is a field type, e.g. Text, Number, DateTime
+func (s *Server) AddField(listTitle string, internalName string, typeAsString string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := s.web.listByTitle(listTitle)
+	if l == nil {
+		return fmt.Errorf("list %s does not exist", listTitle)
+	}
+	l.fields = append(l.fields, &field{id: newGUID(), internalName: internalName, title: internalName, typeAsString: typeAsString})
+	return nil
+}
+
+// AddLookupField adds a lookup field to the list, the lookup shows lookupListTitle list's Title field
+func (s *Server) AddLookupField(listTitle string, internalName string, lookupListTitle string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := s.web.listByTitle(listTitle)
+	if l == nil {
+		return fmt.Errorf("list %s does not exist", listTitle)
+	}
+	target := s.web.listByTitle(lookupListTitle)
+	if target == nil {
+		return fmt.Errorf("list %s does not exist", lookupListTitle)
+	}
+	l.fields = append(l.fields, &field{id: newGUID(), internalName: internalName, title: internalName, typeAsString: "Lookup", lookupList: target.id})
+	return nil
+}
+
+// AddItem adds an item to the list, returns new item ID
+func (s *Server) AddItem(listTitle string, props map[string]interface{}) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := s.web.listByTitle(listTitle)
+	if l == nil {
+		return 0, fmt.Errorf("list %s does not exist", listTitle)
+	}
+	return l.addItem(props, 0, "").id, nil
+}
+
+// AddFolder adds a folder by server relative URL, parent folders are created when missing
+func (s *Server) AddFolder(serverRelativeURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.ensureFolder(s.absPath(serverRelativeURL))
+	return err
+}
+
+// AddFile adds or overwrites a file by server relative URL, parent folders are created when missing
+func (s *Server) AddFile(serverRelativeURL string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fileURL := s.absPath(serverRelativeURL)
+	if _, err := s.ensureFolder(path.Dir(fileURL)); err != nil {
+		return err
+	}
+	_, err := s.putFile(fileURL, content, true)
+	return err
+}
+
+// UploadSessions gets the number of active chunked upload sessions
+func (s *Server) UploadSessions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.uploads)
+}
+
+// handle handles HTTP requests
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.serve(w, r)
+}
+
+// serve routes a request, the caller holds the lock
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(strings.ToLower(r.URL.Path), strings.ToLower(SitePath)+"/") {
+		s.writeError(w, r, resourceNotFound(r.URL.Path))
+		return
+	}
+	rel := r.URL.Path[len(SitePath):]
+	method := r.Method
+	if h := r.Header.Get("X-HTTP-Method"); h != "" && method == "POST" {
+		method = strings.ToUpper(h)
+	}
+
+	lower := strings.ToLower(rel)
+	if lower == "/_api/contextinfo" {
+		if method != "POST" {
+			s.writeError(w, r, badRequest("The HTTP method '%s' cannot be used to access the resource 'GetContextWebInformation'.", method))
+			return
+		}
+		s.handleContextInfo(w, r)
+		return
+	}
+
+	if method != "GET" && r.Header.Get("X-RequestDigest") != s.digest {
+		s.writeError(w, r, &apiError{
+			status:  http.StatusForbidden,
+			code:    "-2130575252, Microsoft.SharePoint.SPException",
+			message: "The security validation for this page is invalid and might be corrupted. Please use your web browser's Back button to try your operation again.",
+		})
+		return
+	}
+
+	if lower == "/_api/$batch" {
+		s.handleBatch(w, r)
+		return
+	}
+	if lower == "/_vti_bin/client.svc/processquery" {
+		s.handleProcessQuery(w, r)
+		return
+	}
+	if !strings.HasPrefix(lower, "/_api/") {
+		s.writeError(w, r, resourceNotFound(rel))
+		return
+	}
+
+	q, err := parseQuery(r.URL.Query())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	n, err := s.resolve(splitPath(rel[len("/_api/"):]))
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	if err := s.dispatch(w, r, method, n, q); err != nil {
+		s.writeError(w, r, err)
+	}
+}
+
+// resolve walks API path segments to a resource
+func (s *Server) resolve(segments []segment) (*node, error) {
+	n := &node{kind: "root", uri: s.SiteURL() + "/_api"}
+	for i, seg := range segments {
+		next, err := s.step(n, seg)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			// The segment is a method call on the resource
+			if i != len(segments)-1 {
+				return nil, resourceNotFound(seg.name)
+			}
+			action := seg
+			n.action = &action
+			return n, nil
+		}
+		n = next
+	}
+	return n, nil
+}
+
+// step resolves the next resource from the current one, returns nil node for a method call
+func (s *Server) step(n *node, seg segment) (*node, error) {
+	switch n.kind {
+	case "root":
+		switch seg.name {
+		case "web":
+			return s.webNode(), nil
+		case "site":
+			return &node{kind: "site", uri: n.uri + "/Site"}, nil
+		case "sp.movecopyutil.copyfilebypath", "sp.movecopyutil.movefilebypath",
+			"sp.movecopyutil.copyfolderbypath", "sp.movecopyutil.movefolderbypath":
+			return nil, nil
+		}
+	case "site":
+		switch seg.name {
+		case "rootweb":
+			return s.webNode(), nil
+		case "createcopyjobs", "getcopyjobprogress":
+			return nil, nil
+		}
+	case "web":
+		switch seg.name {
+		case "lists":
+			lists := &node{kind: "lists", uri: n.uri + "/Lists"}
+			if id, ok := seg.arg("id"); ok {
+				return s.listNode(s.web.listByID(id), id)
+			}
+			return lists, nil
+		case "getlist":
+			listURL, _ := seg.arg("")
+			return s.listNode(s.web.listByURL(s.absPath(listURL)), listURL)
+		case "fields":
+			return &node{kind: "fields", uri: n.uri + "/Fields"}, nil
+		case "rootfolder":
+			return s.folderNode(SitePath)
+		case "folders":
+			return &node{kind: "folders", uri: n.uri + "/Folders", url: SitePath}, nil
+		case "currentuser":
+			return &node{kind: "user", uri: n.uri + "/CurrentUser"}, nil
+		case "ensureuser":
+			return nil, nil
+		case "getfolderbyserverrelativeurl", "getfolderbyserverrelativepath":
+			folderURL, _ := seg.arg("decodedurl")
+			return s.folderNode(s.absPath(folderURL))
+		case "getfilebyserverrelativeurl", "getfilebyserverrelativepath":
+			fileURL, _ := seg.arg("decodedurl")
+			return s.fileNode(s.absPath(fileURL))
+		case "getfolderbyid":
+			id, _ := seg.arg("")
+			for _, f := range s.folders {
+				if strings.EqualFold(f.uniqueID, strings.Trim(id, "{}")) {
+					return s.folderNode(f.url)
+				}
+			}
+			return nil, notFound("File Not Found.")
+		case "getfilebyid":
+			id, _ := seg.arg("")
+			for _, f := range s.files {
+				if strings.EqualFold(f.uniqueID, strings.Trim(id, "{}")) {
+					return s.fileNode(f.url)
+				}
+			}
+			return nil, notFound("File Not Found.")
+		}
+	case "lists":
+		switch seg.name {
+		case "getbytitle":
+			title, _ := seg.arg("")
+			return s.listNode(s.web.listByTitle(title), title)
+		case "getbyid":
+			id, _ := seg.arg("")
+			return s.listNode(s.web.listByID(id), id)
+		}
+	case "list":
+		switch seg.name {
+		case "items":
+			if id, ok := seg.arg(""); ok {
+				return s.itemNode(n.list, id)
+			}
+			return &node{kind: "items", uri: n.uri + "/Items", list: n.list}, nil
+		case "getitembyid":
+			id, _ := seg.arg("")
+			return s.itemNode(n.list, id)
+		case "fields":
+			if id, ok := seg.arg(""); ok {
+				return s.fieldNode(n.list, n.uri+"/Fields", id)
+			}
+			return &node{kind: "fields", uri: n.uri + "/Fields", list: n.list}, nil
+		case "rootfolder":
+			return s.folderNode(n.list.url)
+		case "parentweb":
+			return s.webNode(), nil
+		case "recycle", "renderlistdataasstream":
+			return nil, nil
+		}
+	case "items":
+		switch seg.name {
+		case "getbyid":
+			id, _ := seg.arg("")
+			return s.itemNode(n.list, id)
+		}
+	case "item":
+		switch seg.name {
+		case "file":
+			return s.fileNode(fmt.Sprintf("%s", n.item.props["FileRef"]))
+		case "folder":
+			return s.folderNode(fmt.Sprintf("%s", n.item.props["FileRef"]))
+		case "parentlist":
+			return s.listNode(n.list, n.list.id)
+		case "attachmentfiles":
+			attachments := &node{kind: "attachments", uri: n.uri + "/AttachmentFiles", list: n.list, item: n.item}
+			if name, ok := seg.arg(""); ok {
+				return s.attachmentNode(attachments, name)
+			}
+			return attachments, nil
+		case "versions":
+			versions := &node{kind: "itemversions", uri: n.uri + "/Versions", list: n.list, item: n.item}
+			if id, ok := seg.arg(""); ok {
+				return s.itemVersionNode(versions, id)
+			}
+			return versions, nil
+		case "recycle", "validateupdatelistitem":
+			return nil, nil
+		}
+	case "itemversion":
+		switch seg.name {
+		case "deleteobject":
+			return nil, nil
+		}
+	case "attachments":
+		switch seg.name {
+		case "getbyfilename":
+			name, _ := seg.arg("")
+			return s.attachmentNode(n, name)
+		case "add":
+			return nil, nil
+		}
+	case "attachment":
+		switch seg.name {
+		case "$value":
+			return &node{kind: "attachmentvalue", uri: n.uri + "/$value", list: n.list, item: n.item, url: n.url}, nil
+		case "recycleobject":
+			return nil, nil
+		}
+	case "fields":
+		switch seg.name {
+		case "getbytitle", "getbyinternalnameortitle", "getbyid":
+			name, _ := seg.arg("")
+			return s.fieldNode(n.list, n.uri, name)
+		case "createfieldasxml", "add", "addfield":
+			return nil, nil
+		}
+	case "folders":
+		switch seg.name {
+		case "add":
+			return nil, nil
+		}
+	case "folder":
+		switch seg.name {
+		case "folders":
+			if name, ok := seg.arg(""); ok {
+				return s.folderNode(n.url + "/" + name)
+			}
+			return &node{kind: "folders", uri: n.uri + "/Folders", url: n.url}, nil
+		case "files":
+			if name, ok := seg.arg(""); ok {
+				return s.fileNode(n.url + "/" + name)
+			}
+			return &node{kind: "files", uri: n.uri + "/Files", url: n.url}, nil
+		case "parentfolder":
+			return s.folderNode(path.Dir(n.url))
+		case "listitemallfields":
+			return s.fsItemNode(s.folders[strings.ToLower(n.url)].list, s.folders[strings.ToLower(n.url)].itemID)
+		case "storagemetrics":
+			return &node{kind: "storagemetrics", uri: n.uri + "/StorageMetrics", url: n.url}, nil
+		case "recycle":
+			return nil, nil
+		}
+	case "files":
+		switch seg.name {
+		case "add":
+			return nil, nil
+		}
+	case "file":
+		switch seg.name {
+		case "$value":
+			return &node{kind: "value", uri: n.uri + "/$value", url: n.url}, nil
+		case "listitemallfields":
+			return s.fsItemNode(s.files[strings.ToLower(n.url)].list, s.files[strings.ToLower(n.url)].itemID)
+		case "properties":
+			return &node{kind: "fileproperties", uri: n.uri + "/Properties", url: n.url}, nil
+		case "versions":
+			return &node{kind: "fileversions", uri: n.uri + "/Versions", url: n.url}, nil
+		case "recycle", "copyto", "moveto", "startupload", "continueupload", "finishupload", "cancelupload":
+			return nil, nil
+		}
+	}
+	return nil, resourceNotFound(seg.name)
+}
+
+// dispatch executes the request against the resolved resource
+func (s *Server) dispatch(w http.ResponseWriter, r *http.Request, method string, n *node, q *query) error {
+	if n.action != nil {
+		if method != "POST" {
+			return badRequest("The HTTP method '%s' cannot be used to access the resource '%s'.", method, n.action.name)
+		}
+		return s.callAction(w, r, n, q)
+	}
+
+	switch method + " " + n.kind {
+	case "GET site":
+		s.writeEntity(w, r, http.StatusOK, s.siteEntity(), q, false)
+	case "GET web":
+		s.writeEntity(w, r, http.StatusOK, s.webEntity(), q, false)
+	case "MERGE web":
+		props, err := readBody(r)
+		if err != nil {
+			return err
+		}
+		if title, ok := props["Title"].(string); ok {
+			s.web.title = title
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "GET user":
+		s.writeEntity(w, r, http.StatusOK, s.userEntity(n.uri), q, false)
+
+	case "GET lists":
+		var entities []*entity
+		for _, l := range s.web.lists {
+			entities = append(entities, s.listEntity(l))
+		}
+		s.writeCollection(w, r, "SP.ApiData.Lists", entities, q, -1, false)
+	case "POST lists":
+		props, err := readBody(r)
+		if err != nil {
+			return err
+		}
+		title, _ := props["Title"].(string)
+		baseTemplate, _ := toNumber(props["BaseTemplate"])
+		description, _ := props["Description"].(string)
+		l, err := s.addList(title, int(baseTemplate), description)
+		if err != nil {
+			return err
+		}
+		s.writeEntity(w, r, http.StatusCreated, s.listEntity(l), q, false)
+	case "GET list":
+		s.writeEntity(w, r, http.StatusOK, s.listEntity(n.list), q, false)
+	case "MERGE list":
+		props, err := readBody(r)
+		if err != nil {
+			return err
+		}
+		if title, ok := props["Title"].(string); ok {
+			n.list.title = title
+		}
+		if description, ok := props["Description"].(string); ok {
+			n.list.description = description
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "DELETE list":
+		s.deleteList(n.list)
+		w.WriteHeader(http.StatusOK)
+
+	case "GET items":
+		var entities []*entity
+		for _, it := range n.list.items {
+			entities = append(entities, s.itemEntity(n.list, it))
+		}
+		s.writeCollection(w, r, n.list.itemEntityType(), entities, q, defaultPageSize, true)
+	case "POST items":
+		props, err := s.readItemBody(r, n.list)
+		if err != nil {
+			return err
+		}
+		it := n.list.addItem(props, 0, "")
+		s.writeEntity(w, r, http.StatusCreated, s.itemEntity(n.list, it), q, true)
+	case "GET item":
+		s.writeEntity(w, r, http.StatusOK, s.itemEntity(n.list, n.item), q, true)
+	case "MERGE item":
+		props, err := s.readItemBody(r, n.list)
+		if err != nil {
+			return err
+		}
+		n.item.saveVersion()
+		for key, val := range props {
+			n.item.props[key] = val
+		}
+		n.item.props["Modified"] = time.Now().UTC().Format(time.RFC3339)
+		w.WriteHeader(http.StatusNoContent)
+	case "DELETE item":
+		s.deleteItem(n.list, n.item)
+		w.WriteHeader(http.StatusOK)
+	case "GET itemversions":
+		s.writeCollection(w, r, "SP.ListItemVersion", s.itemVersionEntities(n.uri, n.item), q, -1, false)
+	case "GET itemversion":
+		for _, e := range s.itemVersionEntities(strings.TrimSuffix(n.uri, fmt.Sprintf("(%d)", n.version)), n.item) {
+			if e.props["VersionId"] == n.version {
+				s.writeEntity(w, r, http.StatusOK, e, q, false)
+			}
+		}
+
+	case "GET fields":
+		var entities []*entity
+		for _, f := range s.fieldsOf(n.list) {
+			entities = append(entities, s.fieldEntity(n.uri, f))
+		}
+		s.writeCollection(w, r, "SP.ApiData.Fields", entities, q, -1, false)
+	case "POST fields":
+		props, err := readBody(r)
+		if err != nil {
+			return err
+		}
+		title, _ := props["Title"].(string)
+		kind, _ := toNumber(props["FieldTypeKind"])
+		f, err := s.addField(n.list, encodeName(title), title, fieldTypeByKind(int(kind)))
+		if err != nil {
+			return err
+		}
+		s.writeEntity(w, r, http.StatusCreated, s.fieldEntity(n.uri, f), q, false)
+	case "GET field":
+		s.writeEntity(w, r, http.StatusOK, s.fieldEntity(strings.TrimSuffix(n.uri, "('"+n.field.id+"')"), n.field), q, false)
+	case "DELETE field":
+		s.deleteField(n.list, n.field)
+		w.WriteHeader(http.StatusOK)
+
+	case "GET folders":
+		var entities []*entity
+		for _, f := range s.childFolders(n.url) {
+			entities = append(entities, s.folderEntity(f))
+		}
+		s.writeCollection(w, r, "SP.ApiData.Folders", entities, q, -1, false)
+	case "GET folder":
+		s.writeEntity(w, r, http.StatusOK, s.folderEntity(s.folders[strings.ToLower(n.url)]), q, false)
+	case "DELETE folder":
+		s.deleteFolder(n.url)
+		w.WriteHeader(http.StatusOK)
+
+	case "GET files":
+		var entities []*entity
+		for _, f := range s.childFiles(n.url) {
+			entities = append(entities, s.fileEntity(f))
+		}
+		s.writeCollection(w, r, "SP.ApiData.Files", entities, q, -1, false)
+	case "GET file":
+		s.writeEntity(w, r, http.StatusOK, s.fileEntity(s.files[strings.ToLower(n.url)]), q, false)
+	case "DELETE file":
+		s.deleteFile(n.url)
+		w.WriteHeader(http.StatusOK)
+
+	case "GET attachments":
+		var entities []*entity
+		for _, a := range n.item.attachments {
+			entities = append(entities, s.attachmentEntity(n.uri, n.list, n.item, a))
+		}
+		s.writeCollection(w, r, "SP.Attachment", entities, q, -1, false)
+	case "GET attachment":
+		s.writeEntity(w, r, http.StatusOK, s.attachmentEntity(strings.TrimSuffix(n.uri, fmt.Sprintf("('%s')", n.url)), n.list, n.item, n.item.attachmentByName(n.url)), q, false)
+	case "DELETE attachment":
+		n.item.deleteAttachment(n.url)
+		w.WriteHeader(http.StatusOK)
+	case "GET attachmentvalue":
+		a := n.item.attachmentByName(n.url)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.Itoa(len(a.content)))
+		_, _ = w.Write(a.content)
+
+	case "GET fileproperties":
+		s.writeEntity(w, r, http.StatusOK, s.filePropertiesEntity(s.files[strings.ToLower(n.url)]), q, false)
+	case "GET fileversions":
+		s.writeCollection(w, r, "SP.FileVersion", s.fileVersionEntities(n.uri, s.files[strings.ToLower(n.url)]), q, -1, false)
+	case "GET storagemetrics":
+		s.writeEntity(w, r, http.StatusOK, s.storageMetricsEntity(n.uri, n.url), q, false)
+	case "GET value":
+		return s.fileContent(w, r, s.files[strings.ToLower(n.url)])
+	case "PUT value", "POST value":
+		content, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		if _, err := s.putFile(n.url, content, true); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		return badRequest("The HTTP method '%s' cannot be used to access the resource.", method)
+	}
+	return nil
+}
+
+// callAction executes a method call on a resource
+func (s *Server) callAction(w http.ResponseWriter, r *http.Request, n *node, q *query) error {
+	switch n.kind + "." + n.action.name {
+	case "list.recycle":
+		s.deleteList(n.list)
+		s.writeValue(w, r, "Recycle", newGUID())
+	case "item.recycle":
+		s.deleteItem(n.list, n.item)
+		s.writeValue(w, r, "Recycle", newGUID())
+	case "folder.recycle":
+		s.deleteFolder(n.url)
+		s.writeValue(w, r, "Recycle", newGUID())
+	case "file.recycle":
+		s.deleteFile(n.url)
+		s.writeValue(w, r, "Recycle", newGUID())
+
+	case "list.renderlistdataasstream":
+		return s.renderListDataAsStream(w, r, n.list)
+
+	case "itemversion.deleteobject":
+		if n.version == (n.item.version+1)*512 {
+			return badRequest("You cannot delete the current version.")
+		}
+		for i, v := range n.item.versions {
+			if v.id == n.version {
+				n.item.versions = append(n.item.versions[:i], n.item.versions[i+1:]...)
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+
+	case "item.validateupdatelistitem":
+		return s.validateUpdateListItem(w, r, n.list, n.item)
+	case "attachments.add":
+		name, _ := n.action.arg("filename")
+		content, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		if n.item.attachmentByName(name) != nil {
+			return badRequest("The specified name is already in use.")
+		}
+		a := &attachment{name: name, content: content}
+		n.item.attachments = append(n.item.attachments, a)
+		s.writeEntity(w, r, http.StatusOK, s.attachmentEntity(n.uri, n.list, n.item, a), q, false)
+	case "attachment.recycleobject":
+		n.item.deleteAttachment(n.url)
+		w.WriteHeader(http.StatusOK)
+
+	case "web.ensureuser":
+		body := &struct {
+			LogonName string `json:"logonName"`
+		}{}
+		if err := readBodyTo(r, body); err != nil {
+			return err
+		}
+		if body.LogonName == "" {
+			return badRequest("The user logon name is required.")
+		}
+		u := s.web.ensureUser(body.LogonName)
+		s.writeEntity(w, r, http.StatusOK, &entity{
+			typ:   "SP.User",
+			uri:   fmt.Sprintf("%s/_api/Web/GetUserById(%d)", s.SiteURL(), u["Id"]),
+			props: u,
+		}, q, false)
+
+	case "fields.createfieldasxml":
+		body := &struct {
+			Parameters struct {
+				SchemaXML string `json:"SchemaXml"`
+			} `json:"parameters"`
+		}{}
+		if err := readBodyTo(r, body); err != nil {
+			return err
+		}
+		attrs := map[string]string{}
+		for _, m := range schemaAttrRgx.FindAllStringSubmatch(body.Parameters.SchemaXML, -1) {
+			attrs[strings.ToLower(m[1])] = m[2]
+		}
+		title := attrs["displayname"]
+		name := attrs["name"]
+		if name == "" {
+			name = attrs["staticname"]
+		}
+		if name == "" {
+			name = encodeName(title)
+		}
+		if title == "" {
+			title = name
+		}
+		f, err := s.addField(n.list, name, title, attrs["type"])
+		if err != nil {
+			return err
+		}
+		s.writeEntity(w, r, http.StatusOK, s.fieldEntity(n.uri, f), q, false)
+
+	case "folders.add":
+		name, _ := n.action.arg("url")
+		folderURL := n.url + "/" + strings.Trim(name, "/")
+		if strings.HasPrefix(name, "/") {
+			folderURL = name
+		}
+		f, err := s.ensureFolder(folderURL)
+		if err != nil {
+			return err
+		}
+		s.writeEntity(w, r, http.StatusOK, s.folderEntity(f), q, false)
+
+	case "files.add":
+		name, _ := n.action.arg("url")
+		overwrite := strings.EqualFold(n.action.named["overwrite"], "true")
+		content, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		f, err := s.putFile(n.url+"/"+name, content, overwrite)
+		if err != nil {
+			return err
+		}
+		s.writeEntity(w, r, http.StatusOK, s.fileEntity(f), q, false)
+
+	case "file.copyto", "file.moveto":
+		newURL, _ := n.action.arg("strnewurl")
+		if v, ok := n.action.named["newurl"]; ok {
+			newURL = v
+		}
+		overwrite := strings.EqualFold(n.action.named["boverwrite"], "true") || n.action.named["flags"] == "1"
+		src := s.files[strings.ToLower(n.url)]
+		newURL = s.absPath(newURL)
+		if s.folders[strings.ToLower(path.Dir(newURL))] == nil {
+			return notFound("File Not Found.")
+		}
+		if _, err := s.putFile(newURL, src.content, overwrite); err != nil {
+			return err
+		}
+		if n.action.name == "moveto" {
+			s.deleteFile(n.url)
+		}
+		w.WriteHeader(http.StatusOK)
+
+	case "file.startupload", "file.continueupload", "file.finishupload", "file.cancelupload":
+		return s.chunkedUpload(w, r, n, q)
+
+	case "site.createcopyjobs":
+		return s.createCopyJobs(w, r)
+	case "site.getcopyjobprogress":
+		return s.getCopyJobProgress(w, r)
+	case "root.sp.movecopyutil.copyfilebypath", "root.sp.movecopyutil.movefilebypath",
+		"root.sp.movecopyutil.copyfolderbypath", "root.sp.movecopyutil.movefolderbypath":
+		return s.moveCopyUtil(w, r, strings.TrimPrefix(n.action.name, "sp.movecopyutil."))
+
+	default:
+		return resourceNotFound(n.action.name)
+	}
+	return nil
+}
+
+// handleContextInfo responds with context info and the request digest
+func (s *Server) handleContextInfo(w http.ResponseWriter, r *http.Request) {
+	mode := detectMode(r.Header.Get("Accept"))
+	versions := []string{"14.0.0.0", "15.0.0.0"}
+	info := map[string]interface{}{
+		"FormDigestTimeoutSeconds": 1800,
+		"FormDigestValue":          s.digest,
+		"LibraryVersion":           s.LibraryVersion,
+		"SiteFullUrl":              s.SiteURL(),
+		"WebFullUrl":               s.SiteURL(),
+		"SupportedSchemaVersions":  versions,
+	}
+	var payload interface{} = info
+	switch mode {
+	case modeVerbose:
+		info["__metadata"] = map[string]string{"type": "SP.ContextWebInformation"}
+		info["SupportedSchemaVersions"] = map[string]interface{}{
+			"__metadata": map[string]string{"type": "Collection(Edm.String)"},
+			"results":    versions,
+		}
+		payload = map[string]interface{}{"d": map[string]interface{}{"GetContextWebInformation": info}}
+	case modeMinimalMetadata:
+		info["odata.metadata"] = s.SiteURL() + "/_api/$metadata#SP.ContextWebInformation"
+	}
+	writeJSON(w, http.StatusOK, mode.contentType(), payload)
+}
+
+// handleProcessQuery handles CSOM requests
+func (s *Server) handleProcessQuery(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	res, ok := s.setFileProperties(body)
+	if ok {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write(res)
+		return
+	}
+	err = &CSOMError{
+		Message:  "CSOM requests are not supported by the mock server.",
+		Code:     -2146233079,
+		TypeName: "System.NotSupportedException",
+	}
+	if s.ProcessQuery != nil {
+		res, err = s.ProcessQuery(body)
+	}
+	if err != nil {
+		csomErr, ok := err.(*CSOMError)
+		if !ok {
+			csomErr = &CSOMError{Message: err.Error(), Code: -1, TypeName: "Microsoft.SharePoint.Client.ServerException"}
+		}
+		correlationID := newGUID()
+		res, _ = json.Marshal([]interface{}{map[string]interface{}{
+			"SchemaVersion":  "15.0.0.0",
+			"LibraryVersion": s.LibraryVersion,
+			"ErrorInfo": map[string]interface{}{
+				"ErrorMessage":       csomErr.Message,
+				"ErrorValue":         nil,
+				"TraceCorrelationId": correlationID,
+				"ErrorCode":          csomErr.Code,
+				"ErrorTypeName":      csomErr.TypeName,
+			},
+			"TraceCorrelationId": correlationID,
+		}})
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = w.Write(res)
+}
+
+/* Resources */
+
+func (s *Server) webNode() *node {
+	return &node{kind: "web", uri: s.SiteURL() + "/_api/Web"}
+}
+
+func (s *Server) listNode(l *list, ref string) (*node, error) {
+	if l == nil {
+		return nil, &apiError{
+			status:  http.StatusNotFound,
+			code:    "-1, System.ArgumentException",
+			message: fmt.Sprintf("List '%s' does not exist at site with URL '%s'.", ref, s.SiteURL()),
+		}
+	}
+	return &node{kind: "list", uri: fmt.Sprintf("%s/_api/Web/Lists(guid'%s')", s.SiteURL(), l.id), list: l}, nil
+}
+
+func (s *Server) itemNode(l *list, id string) (*node, error) {
+	itemID, _ := strconv.Atoi(id)
+	it := l.itemByID(itemID)
+	if it == nil {
+		return nil, &apiError{
+			status:  http.StatusNotFound,
+			code:    "-2147024809, System.ArgumentException",
+			message: "Item does not exist. It may have been deleted by another user.",
+		}
+	}
+	return &node{
+		kind: "item",
+		uri:  fmt.Sprintf("%s/_api/Web/Lists(guid'%s')/Items(%d)", s.SiteURL(), l.id, it.id),
+		list: l,
+		item: it,
+	}, nil
+}
+
+// itemVersionNode gets list item version node, the current version is included
+func (s *Server) itemVersionNode(versions *node, id string) (*node, error) {
+	versionID, _ := strconv.Atoi(id)
+	for _, e := range s.itemVersionEntities(versions.uri, versions.item) {
+		if e.props["VersionId"] == versionID {
+			return &node{kind: "itemversion", uri: e.uri, list: versions.list, item: versions.item, version: versionID}, nil
+		}
+	}
+	return nil, notFound("Version does not exist. It may have been deleted by another user.")
+}
+
+// fsItemNode gets list item node of a folder or a file
+func (s *Server) fsItemNode(l *list, itemID int) (*node, error) {
+	if l == nil || itemID == 0 {
+		return nil, notFound("Item does not exist.")
+	}
+	return s.itemNode(l, strconv.Itoa(itemID))
+}
+
+func (s *Server) fieldNode(l *list, collectionURI string, ref string) (*node, error) {
+	for _, f := range s.fieldsOf(l) {
+		if strings.EqualFold(f.id, strings.Trim(ref, "{}")) || f.internalName == ref || strings.EqualFold(f.title, ref) {
+			return &node{kind: "field", uri: fmt.Sprintf("%s('%s')", collectionURI, f.id), list: l, field: f}, nil
+		}
+	}
+	for _, f := range s.fieldsOf(l) {
+		if strings.EqualFold(f.internalName, ref) {
+			return &node{kind: "field", uri: fmt.Sprintf("%s('%s')", collectionURI, f.id), list: l, field: f}, nil
+		}
+	}
+	return nil, &apiError{
+		status:  http.StatusNotFound,
+		code:    "-2147024809, System.ArgumentException",
+		message: fmt.Sprintf("Column '%s' does not exist. It may have been deleted by another user.", ref),
+	}
+}
+
+func (s *Server) folderNode(folderURL string) (*node, error) {
+	f := s.folders[strings.ToLower(strings.TrimRight(folderURL, "/"))]
+	if f == nil {
+		return nil, notFound("File Not Found.")
+	}
+	return &node{
+		kind: "folder",
+		uri:  fmt.Sprintf("%s/_api/Web/GetFolderByServerRelativeUrl('%s')", s.SiteURL(), escapeLiteral(f.url)),
+		url:  f.url,
+	}, nil
+}
+
+func (s *Server) fileNode(fileURL string) (*node, error) {
+	f := s.files[strings.ToLower(fileURL)]
+	if f == nil {
+		return nil, notFound("File Not Found.")
+	}
+	return &node{
+		kind: "file",
+		uri:  fmt.Sprintf("%s/_api/Web/GetFileByServerRelativeUrl('%s')", s.SiteURL(), escapeLiteral(f.url)),
+		url:  f.url,
+	}, nil
+}
+
+/* Entities */
+
+func (s *Server) siteEntity() *entity {
+	return &entity{
+		typ: "SP.Site",
+		uri: s.SiteURL() + "/_api/Site",
+		props: map[string]interface{}{
+			"Id":                s.web.id,
+			"Url":               s.SiteURL(),
+			"ServerRelativeUrl": SitePath,
+			"RootWeb":           map[string]interface{}{"Id": s.web.id, "Title": s.web.title},
+		},
+	}
+}
+
+func (s *Server) webEntity() *entity {
+	return &entity{
+		typ: "SP.Web",
+		uri: s.SiteURL() + "/_api/Web",
+		props: map[string]interface{}{
+			"Id":                s.web.id,
+			"Title":             s.web.title,
+			"Description":       "",
+			"Url":               s.SiteURL(),
+			"ServerRelativeUrl": SitePath,
+			"WebTemplate":       "STS",
+			"Language":          1033,
+			"Created":           s.web.created.Format(time.RFC3339),
+			"CurrentUser":       mockUser(),
+		},
+	}
+}
+
+func (s *Server) userEntity(uri string) *entity {
+	return &entity{typ: "SP.User", uri: uri, props: mockUser()}
+}
+
+func (s *Server) listEntity(l *list) *entity {
+	baseType := 0
+	if l.isLibrary() {
+		baseType = 1
+	}
+	return &entity{
+		typ: "SP.List",
+		uri: fmt.Sprintf("%s/_api/Web/Lists(guid'%s')", s.SiteURL(), l.id),
+		props: map[string]interface{}{
+			"Id":                         l.id,
+			"Title":                      l.title,
+			"Description":                l.description,
+			"BaseTemplate":               l.baseTemplate,
+			"BaseType":                   baseType,
+			"ItemCount":                  len(l.items),
+			"EntityTypeName":             l.entityType,
+			"ListItemEntityTypeFullName": l.itemEntityType(),
+			"Hidden":                     false,
+			"ParentWebUrl":               SitePath,
+			"Created":                    l.created.Format(time.RFC3339),
+			"LastItemModifiedDate":       l.modified.Format(time.RFC3339),
+			"RootFolder": map[string]interface{}{
+				"Name":              path.Base(l.url),
+				"ServerRelativeUrl": l.url,
+				"UniqueId":          s.folders[strings.ToLower(l.url)].uniqueID,
+			},
+		},
+	}
+}
+
+func (s *Server) itemEntity(l *list, it *item) *entity {
+	props := map[string]interface{}{}
+	for key, val := range it.props {
+		props[key] = val
+	}
+	props["Author"] = s.web.userByID(authorID(it.props["AuthorId"]))
+	props["Editor"] = s.web.userByID(authorID(it.props["EditorId"]))
+	props["Attachments"] = len(it.attachments) > 0
+	// Lookup and user fields are deferred projections of the target items
+	for _, f := range l.fields {
+		id, _ := toNumber(it.props[f.internalName+"Id"])
+		switch {
+		case f.typeAsString == "User" && id > 0:
+			if u := s.web.userByID(int(id)); u != nil {
+				props[f.internalName] = u
+			}
+		case f.typeAsString == "Lookup" && f.lookupList != "" && id > 0:
+			if target := s.web.listByID(f.lookupList); target != nil {
+				if lookupItem := target.itemByID(int(id)); lookupItem != nil {
+					props[f.internalName] = map[string]interface{}{"Id": lookupItem.id, "Title": lookupItem.props["Title"]}
+				}
+			}
+		}
+	}
+	return &entity{
+		typ:   l.itemEntityType(),
+		uri:   fmt.Sprintf("%s/_api/Web/Lists(guid'%s')/Items(%d)", s.SiteURL(), l.id, it.id),
+		etag:  `"1"`,
+		props: props,
+	}
+}
+
+// itemVersionEntities gets list item versions with the field values as of each version, the current version is the last
+func (s *Server) itemVersionEntities(uri string, it *item) []*entity {
+	versions := append(append([]*itemVersion{}, it.versions...), &itemVersion{id: (it.version + 1) * 512, props: it.props})
+	var entities []*entity
+	for _, v := range versions {
+		props := map[string]interface{}{}
+		for key, val := range v.props {
+			props[key] = val
+		}
+		props["VersionId"] = v.id
+		props["VersionLabel"] = fmt.Sprintf("%d.0", v.id/512)
+		props["IsCurrentVersion"] = v.id == (it.version+1)*512
+		entities = append(entities, &entity{typ: "SP.ListItemVersion", uri: fmt.Sprintf("%s(%d)", uri, v.id), props: props})
+	}
+	return entities
+}
+
+func (s *Server) fieldEntity(collectionURI string, f *field) *entity {
+	e := &entity{
+		typ: "SP.Field",
+		uri: fmt.Sprintf("%s('%s')", collectionURI, f.id),
+		props: map[string]interface{}{
+			"Id":                 f.id,
+			"InternalName":       f.internalName,
+			"StaticName":         f.internalName,
+			"EntityPropertyName": f.internalName,
+			"Title":              f.title,
+			"TypeAsString":       f.typeAsString,
+			"FieldTypeKind":      fieldTypeKinds[f.typeAsString],
+			"Hidden":             f.hidden,
+			"ReadOnlyField":      f.readOnly,
+			"Required":           f.required,
+			"Group":              "Custom Columns",
+		},
+	}
+	if f.lookupList != "" {
+		e.typ = "SP.FieldLookup"
+		e.props["LookupList"] = "{" + f.lookupList + "}"
+		e.props["LookupField"] = "Title"
+		e.props["AllowMultipleValues"] = false
+	}
+	return e
+}
+
+func (s *Server) folderEntity(f *folder) *entity {
+	e := &entity{
+		typ: "SP.Folder",
+		uri: fmt.Sprintf("%s/_api/Web/GetFolderByServerRelativeUrl('%s')", s.SiteURL(), escapeLiteral(f.url)),
+		props: map[string]interface{}{
+			"Name":              path.Base(f.url),
+			"ServerRelativeUrl": f.url,
+			"UniqueId":          f.uniqueID,
+			"ItemCount":         len(s.childFolders(f.url)) + len(s.childFiles(f.url)),
+			"Exists":            true,
+			"TimeCreated":       f.created.Format(time.RFC3339),
+			"TimeLastModified":  f.modified.Format(time.RFC3339),
+		},
+	}
+	s.expandFSItem(e, f.list, f.itemID)
+	return e
+}
+
+func (s *Server) fileEntity(f *file) *entity {
+	e := &entity{
+		typ:  "SP.File",
+		uri:  fmt.Sprintf("%s/_api/Web/GetFileByServerRelativeUrl('%s')", s.SiteURL(), escapeLiteral(f.url)),
+		etag: f.etag(),
+		props: map[string]interface{}{
+			"ETag":              f.etag(),
+			"Name":              path.Base(f.url),
+			"ServerRelativeUrl": f.url,
+			"UniqueId":          f.uniqueID,
+			"Length":            strconv.Itoa(len(f.content)),
+			"Exists":            true,
+			"MajorVersion":      f.version,
+			"MinorVersion":      0,
+			"UIVersionLabel":    fmt.Sprintf("%d.0", f.version),
+			"CheckOutType":      2,
+			"TimeCreated":       f.created.Format(time.RFC3339),
+			"TimeLastModified":  f.modified.Format(time.RFC3339),
+		},
+	}
+	s.expandFSItem(e, f.list, f.itemID)
+	return e
+}
+
+// expandFSItem adds expandable ListItemAllFields to a file or folder entity of a list
+func (s *Server) expandFSItem(e *entity, l *list, itemID int) {
+	if l == nil || itemID == 0 {
+		return
+	}
+	if it := l.itemByID(itemID); it != nil {
+		e.props["ListItemAllFields"] = s.itemEntity(l, it).props
+	}
+}
+
+func mockUser() map[string]interface{} {
+	return map[string]interface{}{
+		"Id":          1,
+		"Title":       "Mock Admin",
+		"Email":       "admin@mock.local",
+		"LoginName":   "i:0#.f|membership|admin@mock.local",
+		"IsSiteAdmin": true,
+	}
+}
+
+/* State operations */
+
+// absPath converts web relative URL to server relative one
+func (s *Server) absPath(relativeURL string) string {
+	relativeURL = strings.TrimRight(relativeURL, "/")
+	if !strings.HasPrefix(relativeURL, "/") {
+		relativeURL = SitePath + "/" + relativeURL
+	}
+	return relativeURL
+}
+
+func (s *Server) addList(title string, baseTemplate int, description string) (*list, error) {
+	if title == "" {
+		return nil, badRequest("The list title is required.")
+	}
+	if s.web.listByTitle(title) != nil {
+		return nil, &apiError{
+			status:  http.StatusBadRequest,
+			code:    "-2130575342, Microsoft.SharePoint.SPException",
+			message: fmt.Sprintf("A list, survey, discussion board, or document library with the specified title '%s' already exists in this Web site.", title),
+		}
+	}
+	if baseTemplate == 0 {
+		baseTemplate = 100
+	}
+	urlName := strings.Replace(title, " ", "", -1)
+	l := &list{
+		id:           newGUID(),
+		title:        title,
+		description:  description,
+		baseTemplate: baseTemplate,
+		created:      time.Now().UTC(),
+		modified:     time.Now().UTC(),
+	}
+	if l.isLibrary() {
+		if title == "Documents" {
+			urlName = "Shared Documents"
+		}
+		l.url = SitePath + "/" + urlName
+		l.entityType = encodeName(urlName)
+	} else {
+		l.url = SitePath + "/Lists/" + urlName
+		l.entityType = urlName + "List"
+	}
+	l.fields = defaultListFields(l.isLibrary())
+	if s.web.listByURL(l.url) != nil {
+		return nil, badRequest("A list with URL '%s' already exists.", l.url)
+	}
+	s.web.lists = append(s.web.lists, l)
+	if _, err := s.ensureFolder(path.Dir(l.url)); err != nil {
+		return nil, err
+	}
+	s.folders[strings.ToLower(l.url)] = &folder{uniqueID: newGUID(), url: l.url, created: l.created, modified: l.created, list: l}
+	return l, nil
+}
+
+func (s *Server) deleteList(l *list) {
+	for i, cur := range s.web.lists {
+		if cur == l {
+			s.web.lists = append(s.web.lists[:i], s.web.lists[i+1:]...)
+			break
+		}
+	}
+	s.deleteFolder(l.url)
+}
+
+func (s *Server) fieldsOf(l *list) []*field {
+	if l == nil {
+		return s.web.fields
+	}
+	return l.fields
+}
+
+func (s *Server) addField(l *list, internalName string, title string, typeAsString string) (*field, error) {
+	if internalName == "" {
+		return nil, badRequest("The field title is required.")
+	}
+	for _, f := range s.fieldsOf(l) {
+		if strings.EqualFold(f.internalName, internalName) {
+			return nil, badRequest("A duplicate field name \"%s\" was found.", internalName)
+		}
+	}
+	if typeAsString == "" {
+		typeAsString = "Text"
+	}
+	f := &field{id: newGUID(), internalName: internalName, title: title, typeAsString: typeAsString}
+	if l == nil {
+		s.web.fields = append(s.web.fields, f)
+	} else {
+		l.fields = append(l.fields, f)
+	}
+	return f, nil
+}
+
+func (s *Server) deleteField(l *list, f *field) {
+	fields := s.fieldsOf(l)
+	for i, cur := range fields {
+		if cur == f {
+			fields = append(fields[:i], fields[i+1:]...)
+			break
+		}
+	}
+	if l == nil {
+		s.web.fields = fields
+	} else {
+		l.fields = fields
+	}
+}
+
+func (s *Server) deleteItem(l *list, it *item) {
+	fileRef := strings.ToLower(fmt.Sprintf("%s", it.props["FileRef"]))
+	if f, ok := s.files[fileRef]; ok && f.list == l {
+		delete(s.files, fileRef)
+	}
+	if f, ok := s.folders[fileRef]; ok && f.list == l {
+		s.deleteFolder(f.url)
+	}
+	l.deleteItem(it.id)
+}
+
+// ensureFolder creates a folder with its parents when missing
+func (s *Server) ensureFolder(folderURL string) (*folder, error) {
+	key := strings.ToLower(folderURL)
+	if f, ok := s.folders[key]; ok {
+		return f, nil
+	}
+	if !strings.HasPrefix(key, strings.ToLower(SitePath)+"/") {
+		return nil, notFound("File Not Found.")
+	}
+	if _, err := s.ensureFolder(path.Dir(folderURL)); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	f := &folder{uniqueID: newGUID(), url: folderURL, created: now, modified: now}
+	if l := s.web.listByURL(folderURL); l != nil {
+		f.list = l
+		f.itemID = l.addItem(map[string]interface{}{"Title": path.Base(folderURL)}, 1, folderURL).id
+	}
+	s.folders[key] = f
+	return f, nil
+}
+
+func (s *Server) deleteFolder(folderURL string) {
+	prefix := strings.ToLower(folderURL)
+	for key, f := range s.files {
+		if strings.HasPrefix(key, prefix+"/") {
+			s.removeFSItem(f.list, f.itemID)
+			delete(s.files, key)
+		}
+	}
+	for key, f := range s.folders {
+		if key == prefix || strings.HasPrefix(key, prefix+"/") {
+			s.removeFSItem(f.list, f.itemID)
+			delete(s.folders, key)
+		}
+	}
+}
+
+// putFile creates or overwrites a file in an existing folder
+func (s *Server) putFile(fileURL string, content []byte, overwrite bool) (*file, error) {
+	key := strings.ToLower(fileURL)
+	parent, ok := s.folders[strings.ToLower(path.Dir(fileURL))]
+	if !ok {
+		return nil, notFound("File Not Found.")
+	}
+	now := time.Now().UTC()
+	if f, ok := s.files[key]; ok {
+		if !overwrite {
+			return nil, &apiError{
+				status:  http.StatusBadRequest,
+				code:    "-2130575257, Microsoft.SharePoint.SPException",
+				message: fmt.Sprintf("A file with the name %s already exists.", fileURL),
+			}
+		}
+		f.versions = append(f.versions, fileVersion{size: len(f.content), created: f.modified})
+		f.content = content
+		f.version++
+		f.modified = now
+		return f, nil
+	}
+	f := &file{uniqueID: newGUID(), url: fileURL, content: content, version: 1, created: now, modified: now}
+	if parent.list != nil {
+		f.list = parent.list
+		f.itemID = parent.list.addItem(map[string]interface{}{}, 0, fileURL).id
+	}
+	s.files[key] = f
+	parent.modified = now
+	return f, nil
+}
+
+func (s *Server) deleteFile(fileURL string) {
+	key := strings.ToLower(fileURL)
+	if f, ok := s.files[key]; ok {
+		s.removeFSItem(f.list, f.itemID)
+		delete(s.files, key)
+	}
+	for id, u := range s.uploads {
+		if strings.EqualFold(u.fileURL, fileURL) {
+			delete(s.uploads, id)
+		}
+	}
+}
+
+func (s *Server) removeFSItem(l *list, itemID int) {
+	if l != nil && itemID != 0 {
+		l.deleteItem(itemID)
+	}
+}
+
+func (s *Server) childFolders(folderURL string) []*folder {
+	var res []*folder
+	for _, f := range s.folders {
+		if strings.EqualFold(path.Dir(f.url), folderURL) && !strings.EqualFold(f.url, folderURL) {
+			res = append(res, f)
+		}
+	}
+	sortByURL(len(res), func(i int) string { return res[i].url }, func(i, j int) { res[i], res[j] = res[j], res[i] })
+	return res
+}
+
+func (s *Server) childFiles(folderURL string) []*file {
+	var res []*file
+	for _, f := range s.files {
+		if strings.EqualFold(path.Dir(f.url), folderURL) {
+			res = append(res, f)
+		}
+	}
+	sortByURL(len(res), func(i int) string { return res[i].url }, func(i, j int) { res[i], res[j] = res[j], res[i] })
+	return res
+}
+
+// readItemBody reads item payload validating entity type and field names
+func (s *Server) readItemBody(r *http.Request, l *list) (map[string]interface{}, error) {
+	props, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+	if metadata, ok := props["__metadata"].(map[string]interface{}); ok {
+		if typ, _ := metadata["type"].(string); typ != "" && typ != l.itemEntityType() {
+			return nil, badRequest("A type named '%s' could not be resolved by the model. When a model is available, each type name must resolve to a valid type.", typ)
+		}
+		delete(props, "__metadata")
+	}
+	for key, val := range props {
+		if !l.writableField(key) {
+			return nil, badRequest("The property '%s' does not exist on type '%s'. Make sure to only use property names that are defined by the type.", key, l.itemEntityType())
+		}
+		// Verbose multi values, e.g. {"results": [1, 2]}
+		if m, ok := val.(map[string]interface{}); ok {
+			if results, ok := m["results"]; ok {
+				props[key] = results
+			}
+		}
+	}
+	return props, nil
+}
+
+func readBody(r *http.Request) (map[string]interface{}, error) {
+	props := map[string]interface{}{}
+	return props, readBodyTo(r, &props)
+}
+
+func readBodyTo(r *http.Request, v interface{}) error {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return badRequest("Invalid JSON. %s", err)
+	}
+	return nil
+}
+
+// escapeLiteral escapes OData string literal
+func escapeLiteral(value string) string {
+	return strings.Replace(value, "'", "''", -1)
+}
+
+// sortByURL simple insertion sort for small collections to keep listings stable
+func sortByURL(n int, url func(i int) string, swap func(i, j int)) {
+	for i := 1; i < n; i++ {
+		for j := i; j > 0 && strings.ToLower(url(j-1)) > strings.ToLower(url(j)); j-- {
+			swap(j-1, j)
+		}
+	}
+}
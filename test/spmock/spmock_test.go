@@ -0,0 +1,283 @@
+package spmock_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/koltyakov/gosip/api"
+	"github.com/koltyakov/gosip/test/spmock"
+)
+
+var modes = map[string]*api.RequestConfig{
+	"Verbose":         api.HeadersPresets.Verbose,
+	"Minimalmetadata": api.HeadersPresets.Minimalmetadata,
+	"Nometadata":      api.HeadersPresets.Nometadata,
+}
+
+func newSP(t *testing.T, srv *spmock.Server, mode string) *api.SP {
+	t.Helper()
+	return api.NewSP(srv.Client()).Conf(modes[mode])
+}
+
+func TestWeb(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	for mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			sp := newSP(t, srv, mode)
+			data, err := sp.Web().Select("Title,ServerRelativeUrl").Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data.Data().Title != "Mock" {
+				t.Errorf("unexpected title: %s", data.Data().Title)
+			}
+			if data.Data().ServerRelativeURL != spmock.SitePath {
+				t.Errorf("unexpected server relative URL: %s", data.Data().ServerRelativeURL)
+			}
+		})
+	}
+}
+
+func TestListsAndItems(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	for mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			sp := newSP(t, srv, mode)
+			listTitle := "Tasks " + mode
+			if _, err := sp.Web().Lists().Add(listTitle, nil); err != nil {
+				t.Fatal(err)
+			}
+			list := sp.Web().Lists().GetByTitle(listTitle)
+			if err := srv.AddField(listTitle, "Priority", "Number"); err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 1; i <= 5; i++ {
+				body := []byte(fmt.Sprintf(`{"Title":"Task %d","Priority":%d}`, i, i))
+				if _, err := list.Items().Add(body); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			items, err := list.Items().
+				Select("Id,Title,Priority").
+				Filter("Priority ge 2 and startswith(Title,'Task')").
+				OrderBy("Priority", false).
+				Top(2).
+				Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := items.Data()
+			if len(data) != 2 || data[0].Data().Title != "Task 5" {
+				t.Errorf("unexpected items: %s", items.Normalized())
+			}
+			if !items.HasNextPage() {
+				t.Error("next page is expected")
+			}
+
+			all, err := list.Items().Select("Id").Top(2).GetAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != 5 {
+				t.Errorf("expected 5 items, got %d", len(all))
+			}
+
+			item := list.Items().GetByID(1)
+			if _, err := item.Update([]byte(`{"Title":"Updated"}`)); err != nil {
+				t.Fatal(err)
+			}
+			itemData, err := item.Select("Title,Author/Title").Expand("Author").Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if itemData.Data().Title != "Updated" {
+				t.Errorf("unexpected title: %s", itemData.Data().Title)
+			}
+			if !strings.Contains(string(itemData.Normalized()), "Mock Admin") {
+				t.Errorf("lookup projection is expected: %s", itemData.Normalized())
+			}
+
+			if _, err := list.Items().Add([]byte(`{"Unknown":"value"}`)); err == nil {
+				t.Error("unknown field should fail")
+			}
+			if _, err := list.Items().Select("Unknown").Get(); err == nil {
+				t.Error("unknown field in select should fail")
+			}
+
+			if err := item.Delete(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := item.Get(); err == nil || !strings.Contains(err.Error(), "404 Not Found") {
+				t.Errorf("404 is expected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	sp := newSP(t, srv, "Minimalmetadata")
+	list := sp.Web().Lists().GetByTitle("Documents")
+	schema := `<Field Type="Text" DisplayName="Category" Name="Category" />`
+	if _, err := list.Fields().CreateFieldAsXML(schema, 0); err != nil {
+		t.Fatal(err)
+	}
+	field, err := list.Fields().GetByInternalNameOrTitle("Category").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.Data().TypeAsString != "Text" {
+		t.Errorf("unexpected field type: %s", field.Data().TypeAsString)
+	}
+	if _, err := list.Fields().GetByTitle("Missing").Get(); err == nil {
+		t.Error("missing field should fail")
+	}
+}
+
+func TestFoldersAndFiles(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	for mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			sp := newSP(t, srv, mode)
+			root := sp.Web().GetFolder("Shared Documents")
+			if _, err := root.Folders().Add("Folder " + mode); err != nil {
+				t.Fatal(err)
+			}
+			folder := root.Folders().GetByName("Folder " + mode)
+			content := []byte("Hello, " + mode)
+			if _, err := folder.Files().Add("file.txt", content, true); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := folder.Files().Add("file.txt", content, false); err == nil {
+				t.Error("adding existing file without overwrite should fail")
+			}
+
+			fileURL := spmock.SitePath + "/Shared Documents/Folder " + mode + "/file.txt"
+			file := sp.Web().GetFile(fileURL)
+			data, err := file.Download()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, content) {
+				t.Errorf("unexpected content: %s", data)
+			}
+
+			files, err := folder.Files().Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files.Data()) != 1 {
+				t.Errorf("expected one file, got %d", len(files.Data()))
+			}
+
+			item, err := file.GetItem()
+			if err != nil {
+				t.Fatal(err)
+			}
+			itemData, err := item.Select("Id,FileLeafRef").Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if itemData.Data().ID == 0 || itemData.ToMap()["FileLeafRef"] != "file.txt" {
+				t.Errorf("unexpected file's item: %s", itemData.Normalized())
+			}
+
+			if err := folder.Delete(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := file.Get(); err == nil {
+				t.Error("file should be deleted together with its folder")
+			}
+		})
+	}
+}
+
+func TestRequestDigest(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	client := srv.Client()
+	req, _ := api.NewHTTPClient(client).Post(
+		srv.SiteURL()+"/_api/Web/Lists",
+		bytes.NewBuffer([]byte(`{"Title":"List"}`)),
+		nil,
+	)
+	if req == nil {
+		t.Fatal("list should be created with a valid digest")
+	}
+
+	_, err := api.NewHTTPClient(client).Post(
+		srv.SiteURL()+"/_api/Web/Lists",
+		bytes.NewBuffer([]byte(`{"Title":"Other"}`)),
+		&api.RequestConfig{Headers: map[string]string{"X-RequestDigest": "wrong", "X-Gosip-NoRetry": "true"}},
+	)
+	if err == nil || !strings.Contains(err.Error(), "403 Forbidden") {
+		t.Errorf("403 is expected, got %v", err)
+	}
+}
+
+func TestProcessQuery(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	client := api.NewHTTPClient(srv.Client())
+	if _, err := client.ProcessQuery(srv.SiteURL(), bytes.NewBuffer([]byte("<Request />")), nil); err == nil {
+		t.Error("not supported error is expected")
+	}
+
+	srv.ProcessQuery = func(body []byte) ([]byte, error) {
+		return nil, &spmock.CSOMError{Message: "Access denied.", Code: -2147024891, TypeName: "System.UnauthorizedAccessException"}
+	}
+	_, err := client.ProcessQuery(srv.SiteURL(), bytes.NewBuffer([]byte("<Request />")), nil)
+	if err == nil || !strings.Contains(err.Error(), "Access denied.") {
+		t.Errorf("access denied is expected, got %v", err)
+	}
+
+	srv.ProcessQuery = func(body []byte) ([]byte, error) {
+		res := []interface{}{
+			map[string]interface{}{"SchemaVersion": "15.0.0.0", "LibraryVersion": "16.0.0.0", "ErrorInfo": nil},
+			1, map[string]interface{}{"IsNull": false},
+		}
+		return json.Marshal(res)
+	}
+	if _, err := client.ProcessQuery(srv.SiteURL(), bytes.NewBuffer([]byte("<Request />")), nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestVerboseCollections(t *testing.T) {
+	srv := spmock.NewServer()
+	defer srv.Close()
+
+	client := api.NewHTTPClient(srv.Client())
+	endpoint := srv.SiteURL() + "/_api/Site/CreateCopyJobs"
+	source := srv.SiteURL() + "/Shared Documents"
+	body := func(uris string) *bytes.Buffer {
+		return bytes.NewBuffer([]byte(`{"exportObjectUris":` + uris + `,"destinationUri":"` + source + `","options":{}}`))
+	}
+	conf := &api.RequestConfig{Headers: map[string]string{"X-Gosip-NoRetry": "true"}}
+
+	if _, err := client.Post(endpoint, body(`["`+source+`"]`), conf); err == nil || !strings.Contains(err.Error(), "StartArray") {
+		t.Errorf("plain array should be rejected in verbose mode, got %v", err)
+	}
+	if _, err := client.Post(endpoint, body(`{"results":["`+source+`"]}`), conf); err != nil {
+		t.Error(err)
+	}
+	conf.Headers["Content-Type"] = "application/json;odata=nometadata"
+	if _, err := client.Post(endpoint, body(`["`+source+`"]`), conf); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,341 @@
+package spmock
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// web mock web state
+type web struct {
+	id      string
+	title   string
+	created time.Time
+	fields  []*field
+	lists   []*list
+	users   []map[string]interface{}
+}
+
+// list mock list or document library state
+type list struct {
+	id           string
+	title        string
+	description  string
+	baseTemplate int
+	url          string // root folder server relative URL
+	entityType   string
+	created      time.Time
+	modified     time.Time
+	fields       []*field
+	items        []*item // ordered by ID
+	nextID       int
+}
+
+// field mock field state
+type field struct {
+	id           string
+	internalName string
+	title        string
+	typeAsString string
+	readOnly     bool
+	hidden       bool
+	required     bool
+	lookupList   string // lookup fields target list ID
+}
+
+// item mock list item state
+type item struct {
+	id          int
+	props       map[string]interface{}
+	attachments []*attachment
+	versions    []*itemVersion // previous versions, oldest first
+	version     int            // number of updates, the current version label is version+1
+}
+
+// itemVersion mock previous list item version
+type itemVersion struct {
+	id    int // 512 per major version
+	props map[string]interface{}
+}
+
+// saveVersion keeps item's field values as a previous version before an update
+func (it *item) saveVersion() {
+	it.version++
+	props := map[string]interface{}{}
+	for key, val := range it.props {
+		props[key] = val
+	}
+	it.versions = append(it.versions, &itemVersion{id: it.version * 512, props: props})
+}
+
+// attachment mock list item attachment
+type attachment struct {
+	name    string
+	content []byte
+}
+
+// upload mock chunked upload session
+type upload struct {
+	fileURL string
+	content []byte
+}
+
+// fileVersion mock previous file version
+type fileVersion struct {
+	size    int
+	created time.Time
+}
+
+// folder mock folder state
+type folder struct {
+	uniqueID string
+	url      string // server relative URL
+	created  time.Time
+	modified time.Time
+	list     *list
+	itemID   int
+}
+
+// file mock file state
+type file struct {
+	uniqueID string
+	url      string // server relative URL
+	content  []byte
+	version  int               // content version, is a part of ETag
+	versions []fileVersion     // previous versions
+	props    map[string]string // property bag
+	created  time.Time
+	modified time.Time
+	list     *list
+	itemID   int
+}
+
+// fieldTypeKinds SP.FieldType values
+var fieldTypeKinds = map[string]int{
+	"Integer":     1,
+	"Text":        2,
+	"Note":        3,
+	"DateTime":    4,
+	"Counter":     5,
+	"Choice":      6,
+	"Lookup":      7,
+	"Boolean":     8,
+	"Number":      9,
+	"Currency":    10,
+	"URL":         11,
+	"Computed":    12,
+	"Guid":        14,
+	"MultiChoice": 15,
+	"File":        18,
+	"User":        20,
+}
+
+// fieldTypeByKind resolves TypeAsString by FieldTypeKind value
+func fieldTypeByKind(kind int) string {
+	for t, k := range fieldTypeKinds {
+		if k == kind {
+			return t
+		}
+	}
+	return "Text"
+}
+
+// encodeName encodes spaces the same way SharePoint does in internal names
+func encodeName(name string) string {
+	return strings.Replace(name, " ", "_x0020_", -1)
+}
+
+func newGUID() string {
+	return uuid.New().String()
+}
+
+func (w *web) listByTitle(title string) *list {
+	for _, l := range w.lists {
+		if strings.EqualFold(l.title, title) {
+			return l
+		}
+	}
+	return nil
+}
+
+func (w *web) listByID(id string) *list {
+	id = strings.Trim(strings.ToLower(id), "{}")
+	for _, l := range w.lists {
+		if l.id == id {
+			return l
+		}
+	}
+	return nil
+}
+
+// listByURL gets a list by its root folder or any nested server relative URL
+func (w *web) listByURL(url string) *list {
+	url = strings.ToLower(strings.TrimRight(url, "/"))
+	for _, l := range w.lists {
+		root := strings.ToLower(l.url)
+		if url == root || strings.HasPrefix(url, root+"/") {
+			return l
+		}
+	}
+	return nil
+}
+
+func (l *list) isLibrary() bool {
+	return l.baseTemplate == 101
+}
+
+// itemEntityType gets list items OData type name
+func (l *list) itemEntityType() string {
+	return "SP.Data." + l.entityType + "Item"
+}
+
+func (l *list) fieldByName(name string) *field {
+	for _, f := range l.fields {
+		if f.internalName == name {
+			return f
+		}
+	}
+	for _, f := range l.fields {
+		if strings.EqualFold(f.internalName, name) || strings.EqualFold(f.title, name) {
+			return f
+		}
+	}
+	return nil
+}
+
+func (l *list) itemByID(id int) *item {
+	pos := sort.Search(len(l.items), func(i int) bool { return l.items[i].id >= id })
+	if pos < len(l.items) && l.items[pos].id == id {
+		return l.items[pos]
+	}
+	return nil
+}
+
+func (l *list) deleteItem(id int) bool {
+	for i, it := range l.items {
+		if it.id == id {
+			l.items = append(l.items[:i], l.items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// addItem adds an item with system properties
+func (l *list) addItem(props map[string]interface{}, fsObjType int, fileRef string) *item {
+	l.nextID++
+	now := time.Now().UTC()
+	it := &item{id: l.nextID, props: map[string]interface{}{}}
+	contentTypeID := "0x0100"
+	if l.isLibrary() {
+		contentTypeID = "0x0101"
+	}
+	if fsObjType == 1 {
+		contentTypeID = "0x0120"
+	}
+	if fileRef == "" {
+		fileRef = fmt.Sprintf("%s/%d_.000", l.url, it.id)
+	}
+	for key, val := range map[string]interface{}{
+		"Id":                   it.id,
+		"ID":                   it.id,
+		"Title":                nil,
+		"GUID":                 newGUID(),
+		"FileSystemObjectType": fsObjType,
+		"FSObjType":            fsObjType,
+		"ContentTypeId":        contentTypeID,
+		"Created":              now.Format(time.RFC3339),
+		"Modified":             now.Format(time.RFC3339),
+		"AuthorId":             1,
+		"EditorId":             1,
+		"FileRef":              fileRef,
+		"FileLeafRef":          path.Base(fileRef),
+		"FileDirRef":           path.Dir(fileRef),
+	} {
+		it.props[key] = val
+	}
+	for key, val := range props {
+		it.props[key] = val
+	}
+	l.items = append(l.items, it)
+	l.modified = now
+	return it
+}
+
+// writableField checks if the property can be written to the list item
+func (l *list) writableField(name string) bool {
+	if f := l.fieldByName(name); f != nil {
+		return !f.readOnly
+	}
+	for _, suffix := range []string{"StringId", "Id"} {
+		if strings.HasSuffix(name, suffix) {
+			f := l.fieldByName(strings.TrimSuffix(name, suffix))
+			if f != nil && (f.typeAsString == "Lookup" || f.typeAsString == "User" || f.typeAsString == "UserMulti") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// defaultListFields gets list's default fields
+func defaultListFields(library bool) []*field {
+	fields := []*field{
+		{internalName: "ID", title: "ID", typeAsString: "Counter", readOnly: true},
+		{internalName: "Title", title: "Title", typeAsString: "Text"},
+		{internalName: "ContentTypeId", title: "Content Type ID", typeAsString: "ContentTypeId", readOnly: true, hidden: true},
+		{internalName: "Created", title: "Created", typeAsString: "DateTime", readOnly: true},
+		{internalName: "Modified", title: "Modified", typeAsString: "DateTime", readOnly: true},
+		{internalName: "Author", title: "Created By", typeAsString: "User", readOnly: true},
+		{internalName: "Editor", title: "Modified By", typeAsString: "User", readOnly: true},
+		{internalName: "GUID", title: "GUID", typeAsString: "Guid", readOnly: true, hidden: true},
+		{internalName: "FileRef", title: "URL Path", typeAsString: "Lookup", readOnly: true, hidden: true},
+		{internalName: "FileDirRef", title: "Path", typeAsString: "Lookup", readOnly: true, hidden: true},
+		{internalName: "FSObjType", title: "Item Type", typeAsString: "Lookup", readOnly: true, hidden: true},
+	}
+	if library {
+		fields = append(fields, &field{internalName: "FileLeafRef", title: "Name", typeAsString: "File"})
+	} else {
+		fields = append(fields, &field{internalName: "FileLeafRef", title: "Name", typeAsString: "File", readOnly: true, hidden: true})
+	}
+	for _, f := range fields {
+		f.id = newGUID()
+	}
+	return fields
+}
+
+// userByID finds a site user by ID
+func (w *web) userByID(id int) map[string]interface{} {
+	for _, u := range w.users {
+		if u["Id"] == id {
+			return u
+		}
+	}
+	return nil
+}
+
+// ensureUser finds a site user by login name or email, adds the user when not found
+func (w *web) ensureUser(logonName string) map[string]interface{} {
+	for _, u := range w.users {
+		if strings.EqualFold(u["LoginName"].(string), logonName) || strings.EqualFold(u["Email"].(string), logonName) {
+			return u
+		}
+	}
+	email := logonName
+	if i := strings.LastIndex(email, "|"); i != -1 {
+		email = email[i+1:]
+	}
+	u := map[string]interface{}{
+		"Id":          len(w.users) + 1,
+		"Title":       strings.Split(email, "@")[0],
+		"Email":       email,
+		"LoginName":   "i:0#.f|membership|" + email,
+		"IsSiteAdmin": false,
+	}
+	w.users = append(w.users, u)
+	return u
+}
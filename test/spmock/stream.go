@@ -0,0 +1,147 @@
+package spmock
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/koltyakov/gosip/caml"
+)
+
+// defaultRowLimit stream page size when the view doesn't limit rows
+const defaultRowLimit = 30
+
+// renderListDataAsStream renders list items in RenderListDataAsStream format,
+// ViewFields and RowLimit of the ViewXml parameter are respected, Query is ignored
+func (s *Server) renderListDataAsStream(w http.ResponseWriter, r *http.Request, l *list) error {
+	body := &struct {
+		Parameters struct {
+			ViewXML string `json:"ViewXml"`
+		} `json:"parameters"`
+	}{}
+	if err := readBodyTo(r, body); err != nil {
+		return err
+	}
+
+	rowLimit := defaultRowLimit
+	var viewFields []string
+	if body.Parameters.ViewXML != "" {
+		view, err := caml.Parse(body.Parameters.ViewXML)
+		if err != nil {
+			return badRequest("Invalid view XML: %s", err)
+		}
+		if view.Limit > 0 {
+			rowLimit = view.Limit
+		}
+		for _, f := range view.ViewFields {
+			viewFields = append(viewFields, f.Name)
+		}
+	}
+	if viewFields == nil {
+		for _, f := range l.fields {
+			if !f.hidden {
+				viewFields = append(viewFields, f.internalName)
+			}
+		}
+	}
+
+	paging := r.URL.Query()
+	afterID, _ := strconv.Atoi(paging.Get("p_ID"))
+	firstRow, _ := strconv.Atoi(paging.Get("PageFirstRow"))
+	if firstRow == 0 {
+		firstRow = 1
+	}
+
+	rows := []interface{}{}
+	lastID := 0
+	hasMore := false
+	for _, it := range l.items {
+		if it.id <= afterID {
+			continue
+		}
+		if len(rows) == rowLimit {
+			hasMore = true
+			break
+		}
+		row := map[string]interface{}{"ID": strconv.Itoa(it.id)}
+		for _, name := range viewFields {
+			s.renderStreamValue(row, l, it, name)
+		}
+		rows = append(rows, row)
+		lastID = it.id
+	}
+
+	res := map[string]interface{}{
+		"Row":               rows,
+		"FirstRow":          firstRow,
+		"LastRow":           firstRow + len(rows) - 1,
+		"RowLimit":          rowLimit,
+		"FolderPermissions": "0x7fffffffffffffff",
+	}
+	if hasMore {
+		next := url.Values{}
+		next.Set("Paged", "TRUE")
+		next.Set("p_ID", strconv.Itoa(lastID))
+		next.Set("PageFirstRow", strconv.Itoa(firstRow+len(rows)))
+		res["NextHref"] = "?" + next.Encode()
+	}
+	writeJSON(w, http.StatusOK, "application/json;charset=utf-8", res)
+	return nil
+}
+
+// renderStreamValue renders a field value the way list data stream does
+func (s *Server) renderStreamValue(row map[string]interface{}, l *list, it *item, name string) {
+	typ := "Text"
+	if f := l.fieldByName(name); f != nil {
+		typ = f.typeAsString
+	}
+	val, ok := it.props[name]
+	switch typ {
+	case "User":
+		if name == "Author" || name == "Editor" {
+			u := mockUser()
+			row[name] = []interface{}{map[string]interface{}{
+				"id": fmt.Sprintf("%v", u["Id"]), "title": u["Title"], "email": u["Email"], "sip": u["Email"],
+			}}
+			return
+		}
+		fallthrough
+	case "Lookup":
+		id, hasID := it.props[name+"Id"]
+		if !hasID || id == nil {
+			row[name] = ""
+			return
+		}
+		row[name] = []interface{}{map[string]interface{}{"lookupId": id, "lookupValue": fmt.Sprintf("%v", id)}}
+	case "DateTime":
+		d, err := time.Parse(time.RFC3339, fmt.Sprintf("%v", val))
+		if !ok || val == nil || err != nil {
+			row[name] = ""
+			return
+		}
+		row[name] = d.UTC().Format("1/2/2006 3:04 PM")
+		row[name+"."] = d.UTC().Format(time.RFC3339)
+	case "Number", "Currency", "Integer", "Counter":
+		n, _ := toNumber(val)
+		if !ok || val == nil {
+			row[name] = ""
+			return
+		}
+		row[name] = strconv.FormatFloat(n, 'f', 2, 64)
+		row[name+"."] = strconv.FormatFloat(n, 'f', -1, 64)
+	case "Boolean":
+		b, _ := val.(bool)
+		row[name], row[name+".value"] = "No", "0"
+		if b {
+			row[name], row[name+".value"] = "Yes", "1"
+		}
+	default:
+		if !ok || val == nil {
+			row[name] = ""
+			return
+		}
+		row[name] = fmt.Sprintf("%v", val)
+	}
+}